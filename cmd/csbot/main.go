@@ -40,7 +40,7 @@ func main() {
 	defer db.Close()
 
 	csService := service.NewCSService(db)
-	bot, err := csbot.New(cfg, csService)
+	bot, err := csbot.New(cfg, csService, service.NewAccessService(db, cfg))
 	if err != nil {
 		logger.Fatal("❌ Failed to initialize CS Bot", zap.Error(err))
 	}
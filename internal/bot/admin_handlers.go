@@ -3,23 +3,37 @@ package bot
 import (
 	"context"
 	"fmt"
+	"html"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/pnj-anonymous-bot/internal/logger"
-	"github.com/pnj-anonymous-bot/internal/metrics"
+	"github.com/pnj-anonymous-bot/internal/models"
+	"github.com/pnj-anonymous-bot/internal/service"
 	"go.uber.org/zap"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func (b *Bot) isAdmin(telegramID int64) bool {
-	return telegramID == b.cfg.MaintenanceAccountID
+func (b *Bot) can(ctx context.Context, telegramID int64, p models.Permission) bool {
+	return b.access.Can(ctx, telegramID, p)
+}
+
+// notifyStaff sends text to every staff member holding p.
+func (b *Bot) notifyStaff(ctx context.Context, p models.Permission, text string, keyboard *tgbotapi.InlineKeyboardMarkup) {
+	staff, err := b.access.StaffWith(ctx, p)
+	if err != nil {
+		logger.Warn("Failed to get staff to notify", zap.String("permission", string(p)), zap.Error(err))
+		return
+	}
+	for _, id := range staff {
+		b.sendMessageHTML(id, text, keyboard)
+	}
 }
 
 func (b *Bot) handleAdminPoll(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
-	if !b.isAdmin(telegramID) {
+	if !b.can(ctx, telegramID, models.PermGlobalPoll) {
 		return
 	}
 
@@ -27,137 +41,460 @@ func (b *Bot) handleAdminPoll(ctx context.Context, msg *tgbotapi.Message) {
 	if args == "" {
 		b.sendMessageHTML(telegramID, `<b>📢 Global Admin Poll</b>
 
-Ketik: <code>/admin_poll Pertanyaan | Opsi 1 | Opsi 2 | ...</code>
+Ketik: <code>/admin_poll [native] [durasi] [tipe] Pertanyaan | Opsi 1 | Opsi 2 | ...</code>
 
-Polling ini akan disiarkan ke <b>SELURUH</b> pengguna bot yang terverifikasi.`, nil)
+Polling ini akan disiarkan ke <b>SELURUH</b> pengguna bot yang terverifikasi.
+<i>Durasi opsional (contoh: 24h, 3d). Tipe opsional: multi:K atau ranked. Saat ditutup, hasil dikirim ke semua pemilih.
+Tambahkan <code>native</code> untuk mengirim polling bawaan Telegram (hanya satu pilihan atau <code>multi</code> tanpa batas).</i>`, nil)
 		return
 	}
 
-	parts := strings.Split(args, "|")
-	if len(parts) < 3 {
-		b.sendMessageHTML(telegramID, "⚠️ <b>Format salah.</b> pertanyaan dan minimal 2 opsi.", nil)
+	native := false
+	if rest, found := strings.CutPrefix(args, "native "); found {
+		native = true
+		args = rest
+	}
+
+	poll, options, _, errMsg := parsePollArgs(args)
+	if errMsg != "" {
+		b.sendMessageHTML(telegramID, errMsg, nil)
+		return
+	}
+	if native && !service.SupportsNativePoll(poll, len(options)) {
+		b.sendMessageHTML(telegramID, "⚠️ Polling native Telegram hanya mendukung satu pilihan atau <code>multi</code> tanpa batas pilihan.", nil)
 		return
 	}
 
-	question := strings.TrimSpace(parts[0])
-	var options []string
-	for i := 1; i < len(parts); i++ {
-		opt := strings.TrimSpace(parts[i])
-		if opt != "" {
-			options = append(options, opt)
-		}
+	poll.Scope = models.PollGlobal
+	poll.IsNative = native
+	pollID, err := b.poll.CreatePoll(ctx, poll, options)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "❌ Gagal membuat polling global: "+html.EscapeString(err.Error()), nil)
+		return
+	}
+
+	kind := models.BroadcastPoll
+	if native {
+		kind = models.BroadcastNativePoll
+	}
+	broadcastID, count, err := b.broadcast.StartBroadcast(ctx, &models.Broadcast{Kind: kind, PollID: &pollID, CreatedBy: telegramID})
+	if err != nil {
+		b.sendMessageHTML(telegramID, "❌ Gagal memulai broadcast polling: "+html.EscapeString(err.Error()), nil)
+		return
+	}
+	b.recordAudit(ctx, telegramID, models.AuditGlobalPoll, 0, map[string]any{
+		"poll_id":      pollID,
+		"question":     poll.Question,
+		"options":      options,
+		"native":       native,
+		"broadcast_id": broadcastID,
+		"recipients":   count,
+	})
+
+	kb := PollManageKeyboard(pollID)
+	b.sendMessageHTML(telegramID, fmt.Sprintf("🚀 <b>Memulai broadcast #%d polling global #%d</b> ke %d pengguna...\n\nPantau dengan /broadcast_status %d", broadcastID, pollID, count, broadcastID), &kb)
+	b.wakeBroadcastWorker()
+}
+
+func (b *Bot) handleBroadcast(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	if !b.can(ctx, telegramID, models.PermBroadcast) {
+		return
 	}
 
-	pollID, err := b.db.CreatePoll(ctx, 0, "[GLOBAL] "+question, options)
+	args := strings.TrimSpace(msg.CommandArguments())
+	if args == "" {
+		b.sendMessageHTML(telegramID, `💡 <b>Cara broadcast:</b>
+<code>/broadcast [filter...] | [pesan]</code>
+
+Filter (opsional):
+• <code>dept:informatika</code> — jurusan
+• <code>year:2023</code> — angkatan
+• <code>gender:l</code> / <code>gender:p</code>
+• <code>level:5</code> — level minimal
+• <code>active:30d</code> — aktif dalam 30 hari terakhir
+• <code>at:2025-01-31T19:00</code> — jadwal kirim (WIB)
+
+Tanpa filter: <code>/broadcast [pesan]</code>`, nil)
+		return
+	}
+
+	bc, errMsg := parseBroadcastArgs(args)
+	if errMsg != "" {
+		b.sendMessageHTML(telegramID, errMsg, nil)
+		return
+	}
+	bc.CreatedBy = telegramID
+
+	broadcastID, count, err := b.broadcast.DraftBroadcast(ctx, bc)
 	if err != nil {
-		b.sendMessageHTML(telegramID, "❌ Gagal membuat polling global.", nil)
+		b.sendMessageHTML(telegramID, "❌ Gagal menyiapkan broadcast: "+html.EscapeString(err.Error()), nil)
 		return
 	}
 
-	b.sendMessageHTML(telegramID, fmt.Sprintf("🚀 <b>Memulai broadcast polling global #%d...</b>", pollID), nil)
+	kb := ConfirmKeyboard(fmt.Sprintf("broadcast:confirm:%d", broadcastID), fmt.Sprintf("broadcast:discard:%d", broadcastID))
+	b.sendMessageHTML(telegramID, fmt.Sprintf(`📢 <b>Pratinjau Broadcast #%d</b>
+
+🎯 Target: %s
+👥 Penerima: %d pengguna
+🗓️ Jadwal: %s
 
-	go b.broadcastGlobalPoll(pollID)
+%s
+
+Kirim broadcast ini?`, broadcastID, html.EscapeString(describeBroadcastSegment(bc.BroadcastSegment)), count, formatBroadcastSchedule(bc.ScheduledAt), bc.Content), &kb)
 }
 
-func (b *Bot) handleBroadcast(ctx context.Context, msg *tgbotapi.Message) {
+func (b *Bot) handleBroadcastCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	if !b.can(ctx, telegramID, models.PermBroadcast) {
+		return
+	}
+
+	action, rawID, _ := strings.Cut(data, ":")
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return
+	}
+
+	switch action {
+	case "confirm":
+		bc, count, err := b.broadcast.ConfirmBroadcast(ctx, id)
+		if err != nil {
+			b.answerCallback(callback.ID, "❌ "+err.Error())
+			return
+		}
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_broadcast_preview")
+		b.recordAudit(ctx, telegramID, models.AuditBroadcastConfirm, 0, broadcastAuditDetails(bc, count))
+
+		if bc.Status == models.BroadcastScheduled {
+			b.sendMessageHTML(telegramID, fmt.Sprintf("🗓️ <b>Broadcast #%d dijadwalkan</b> pada %s untuk ±%d pengguna.\n\nBatalkan dengan /broadcast_cancel %d",
+				id, formatBroadcastSchedule(bc.ScheduledAt), count, id), nil)
+			return
+		}
+		b.sendMessageHTML(telegramID, fmt.Sprintf("🚀 <b>Memulai broadcast #%d</b> ke %d pengguna...\n\nPantau dengan /broadcast_status %d", id, count, id), nil)
+		b.wakeBroadcastWorker()
+
+	case "discard":
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_broadcast_preview")
+		if err := b.broadcast.Cancel(ctx, id); err != nil {
+			b.answerCallback(callback.ID, "❌ "+err.Error())
+			return
+		}
+		b.answerCallback(callback.ID, "👌 Broadcast tidak jadi dikirim.")
+	}
+}
+
+func (b *Bot) handleBroadcastStatus(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
-	if !b.isAdmin(telegramID) {
+	if !b.can(ctx, telegramID, models.PermBroadcast) {
 		return
 	}
 
-	content := msg.CommandArguments()
-	if content == "" {
-		b.sendMessageHTML(telegramID, "💡 Cara broadcast: <code>/broadcast [pesan]</code>", nil)
+	args := strings.TrimSpace(msg.CommandArguments())
+	if args == "" {
+		broadcasts, err := b.broadcast.Recent(ctx, 5)
+		if err != nil {
+			b.sendMessageHTML(telegramID, "❌ Gagal mengambil daftar broadcast.", nil)
+			return
+		}
+		if len(broadcasts) == 0 {
+			b.sendMessageHTML(telegramID, "📭 Belum ada broadcast.", nil)
+			return
+		}
+
+		var sb strings.Builder
+		sb.WriteString("📢 <b>Broadcast Terakhir</b>\n\n")
+		for _, bc := range broadcasts {
+			sb.WriteString(fmt.Sprintf("#%d • %s • %s • %s\n", bc.ID, bc.Kind, broadcastStatusLabel(bc.Status), bc.CreatedAt.Format("02 Jan 15:04")))
+		}
+		sb.WriteString("\nDetail: <code>/broadcast_status [id]</code>")
+		b.sendMessageHTML(telegramID, sb.String(), nil)
 		return
 	}
 
-	b.sendMessageHTML(telegramID, "🚀 <b>Memulai broadcast pesan ke seluruh pengguna...</b>", nil)
+	id, err := strconv.ParseInt(args, 10, 64)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "💡 Cara pakai: <code>/broadcast_status [id]</code>", nil)
+		return
+	}
 
-	go b.broadcastMessage(content)
+	bc, progress, err := b.broadcast.Status(ctx, id)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "❌ Gagal mengambil status broadcast.", nil)
+		return
+	}
+	if bc == nil {
+		b.sendMessageHTML(telegramID, fmt.Sprintf("❌ Broadcast #%d tidak ditemukan.", id), nil)
+		return
+	}
+
+	b.sendMessageHTML(telegramID, fmt.Sprintf("📢 <b>Broadcast #%d</b> (%s)\nStatus: %s\nTarget: %s\nJadwal: %s\nDibuat: %s\n\n%s",
+		bc.ID, bc.Kind, broadcastStatusLabel(bc.Status), html.EscapeString(describeBroadcastTarget(bc)),
+		formatBroadcastSchedule(bc.ScheduledAt), bc.CreatedAt.Format("02 Jan 15:04"), formatBroadcastProgress(progress)), nil)
+}
+
+func (b *Bot) handleBroadcastCancel(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	if !b.can(ctx, telegramID, models.PermBroadcast) {
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(msg.CommandArguments()), 10, 64)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "💡 Cara pakai: <code>/broadcast_cancel [id]</code>", nil)
+		return
+	}
+
+	if err := b.broadcast.Cancel(ctx, id); err != nil {
+		b.sendMessageHTML(telegramID, "❌ "+html.EscapeString(err.Error()), nil)
+		return
+	}
+	b.recordAudit(ctx, telegramID, models.AuditBroadcastCancel, 0, map[string]any{"broadcast_id": id})
+	b.sendMessageHTML(telegramID, fmt.Sprintf("🛑 Broadcast #%d dihentikan. Pengguna yang belum menerima tidak akan dikirimi.", id), nil)
 }
 
-func (b *Bot) broadcastGlobalPoll(pollID int64) {
-	b.background.Add(1)
-	defer b.background.Done()
+func (b *Bot) handleAdminReports(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	if !b.can(ctx, telegramID, models.PermReviewContent) {
+		return
+	}
 
-	ctx := context.Background()
-	start := time.Now()
-	defer func() {
-		metrics.BroadcastDuration.Observe(time.Since(start).Seconds())
-	}()
+	flags, err := b.contentReport.GetReviewQueue(ctx, 10)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "❌ Gagal mengambil antrean laporan.", nil)
+		return
+	}
 
-	p, err := b.db.GetPoll(ctx, pollID)
+	pending, err := b.confession.GetPendingConfessions(ctx, 10)
 	if err != nil {
-		logger.Error("Failed to get poll for broadcast", zap.Int64("poll_id", pollID), zap.Error(err))
+		b.sendMessageHTML(telegramID, "❌ Gagal mengambil confession yang menunggu persetujuan.", nil)
+		return
+	}
+
+	if len(flags) == 0 && len(pending) == 0 {
+		b.sendMessageHTML(telegramID, "✅ <b>Antrean review kosong.</b> Tidak ada konten yang menunggu keputusan.", nil)
+		return
+	}
+
+	for _, c := range pending {
+		b.sendPendingConfession(telegramID, c)
+	}
+	if len(flags) == 0 {
 		return
 	}
 
-	users, err := b.db.GetAllVerifiedUsers(ctx)
+	b.sendMessageHTML(telegramID, fmt.Sprintf("🚩 <b>Antrean Review Konten</b>\n\n%d konten disembunyikan otomatis dan menunggu keputusan:", len(flags)), nil)
+
+	for _, f := range flags {
+		content, _ := b.contentReport.GetContentText(ctx, f.ContentType, f.ContentID)
+		text := fmt.Sprintf("🚩 <b>%s #%d</b> | Laporan: %d | %s\n\n%s",
+			models.ContentTypeLabel(f.ContentType), f.ContentID, f.ReportCount,
+			f.CreatedAt.Format("02 Jan 15:04"), html.EscapeString(truncateText(content, 500)))
+
+		kb := ContentReviewKeyboard(f.ID)
+		b.sendMessageHTML(telegramID, text, &kb)
+	}
+}
+
+func (b *Bot) notifyAdminContentHidden(ctx context.Context, contentType models.ContentType, contentID int64) {
+	b.notifyStaff(ctx, models.PermReviewContent, fmt.Sprintf(`🚩 <b>Konten Disembunyikan Otomatis</b>
+
+%s #%d mencapai batas %d laporan dan masuk antrean review.
+Ketik /admin_reports untuk meninjau.`, models.ContentTypeLabel(contentType), contentID, b.cfg.ContentReportHideThreshold), nil)
+}
+
+func (b *Bot) notifyAdminPendingConfession(ctx context.Context, c *models.Confession) {
+	staff, err := b.access.StaffWith(ctx, models.PermReviewContent)
 	if err != nil {
-		logger.Error("Failed to get users for broadcast", zap.Error(err))
+		logger.Warn("Failed to get staff to notify", zap.Error(err))
 		return
 	}
+	for _, id := range staff {
+		b.sendPendingConfession(id, c)
+	}
+}
 
-	kb := PollVoteKeyboard(p.ID, p.Options)
-	text := fmt.Sprintf("📢 <b>PENGUMUMAN & POLLING GLOBAL</b> 🗳️\n\n%s\n\n<i>Klik di bawah untuk memberikan suara kamu:</i>", p.Question)
+func (b *Bot) sendPendingConfession(chatID int64, c *models.Confession) {
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(c.PhotoFileID))
+	photo.Caption = fmt.Sprintf("⏳ Confession Foto #%d menunggu persetujuan\n\n%s", c.ID, truncateText(c.Content, 900))
+	photo.ReplyMarkup = PendingConfessionKeyboard(c.ID)
+	b.sendAPI("send_pending_confession", photo)
+}
 
-	success := 0
-	failed := 0
+func (b *Bot) handleCircleRestore(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	if !b.can(ctx, telegramID, models.PermManageCircles) {
+		return
+	}
 
-	for _, userID := range users {
-		msg := tgbotapi.NewMessage(userID, text)
-		msg.ParseMode = "HTML"
-		msg.ReplyMarkup = kb
-		_, err := b.api.Send(msg)
+	slug := strings.TrimSpace(msg.CommandArguments())
+	if slug == "" {
+		rooms, err := b.room.GetArchivedRooms(ctx)
 		if err != nil {
-			failed++
-		} else {
-			success++
+			b.sendMessageHTML(telegramID, "❌ Gagal mengambil daftar circle yang diarsipkan.", nil)
+			return
+		}
+		if len(rooms) == 0 {
+			b.sendMessageHTML(telegramID, "📦 Tidak ada circle yang diarsipkan.", nil)
+			return
 		}
-		time.Sleep(50 * time.Millisecond)
+
+		var sb strings.Builder
+		sb.WriteString("📦 <b>Circle yang Diarsipkan</b>\n\n")
+		for _, r := range rooms {
+			sb.WriteString(fmt.Sprintf("• %s — <code>%s</code>\n", html.EscapeString(r.Name), r.Slug))
+		}
+		sb.WriteString("\nPulihkan: <code>/circle_restore [slug]</code>\nHapus permanen: <code>/circle_delete [slug]</code>")
+		b.sendMessageHTML(telegramID, sb.String(), nil)
+		return
 	}
 
-	logger.Info("Global poll broadcast finished",
-		zap.Int64("poll_id", pollID),
-		zap.Int("success", success),
-		zap.Int("failed", failed),
-	)
+	room, err := b.room.RestoreRoom(ctx, slug)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "❌ "+html.EscapeString(err.Error()), nil)
+		return
+	}
 
-	b.sendMessageHTML(b.cfg.MaintenanceAccountID, fmt.Sprintf("✅ <b>Broadcast Selesai!</b>\n\nBerhasil: %d\nGagal: %d", success, failed), nil)
+	b.recordAudit(ctx, telegramID, models.AuditCircleRestore, 0, map[string]any{"room_id": room.ID, "slug": room.Slug})
+	b.sendMessageHTML(telegramID, fmt.Sprintf("✅ Circle <b>%s</b> dipulihkan.", html.EscapeString(room.Name)), nil)
+	if members, err := b.db.GetRoomMembers(ctx, room.ID); err == nil && len(members) > 0 {
+		b.circleNotice(room.ID, members, fmt.Sprintf("♻️ <b>Circle %s aktif kembali.</b> Gunakan /my_circles untuk bicara di sana.", html.EscapeString(room.Name)))
+	}
 }
 
-func (b *Bot) broadcastMessage(content string) {
-	b.background.Add(1)
-	defer b.background.Done()
+func (b *Bot) handleCircleDelete(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	if !b.can(ctx, telegramID, models.PermManageCircles) {
+		return
+	}
 
-	ctx := context.Background()
-	start := time.Now()
-	defer func() {
-		metrics.BroadcastDuration.Observe(time.Since(start).Seconds())
-	}()
+	slug := strings.TrimSpace(msg.CommandArguments())
+	if slug == "" {
+		b.sendMessageHTML(telegramID, "💡 Cara menghapus circle: <code>/circle_delete [slug]</code>\nLihat slug circle yang diarsipkan dengan /circle_restore.", nil)
+		return
+	}
+
+	room, err := b.db.GetRoomBySlug(ctx, slug)
+	if err != nil || room == nil {
+		b.sendMessageHTML(telegramID, "❌ Circle tidak ditemukan.", nil)
+		return
+	}
+
+	kb := ConfirmKeyboard("circle:admin_delete:"+room.Slug, "circle:admin_keep")
+	b.sendMessageHTML(telegramID, fmt.Sprintf(`⚠️ <b>Hapus circle %s secara permanen?</b>
 
-	users, err := b.db.GetAllVerifiedUsers(ctx)
+Semua anggota, peran, pin, dan riwayat pesan circle ini akan dihapus dan tidak bisa dikembalikan.`, html.EscapeString(room.Name)), &kb)
+}
+
+func (b *Bot) deleteCircle(ctx context.Context, telegramID int64, slug string) {
+	room, members, err := b.room.DeleteRoom(ctx, slug)
 	if err != nil {
-		logger.Error("Failed to get users for broadcast", zap.Error(err))
+		b.sendMessageHTML(telegramID, "❌ "+html.EscapeString(err.Error()), nil)
 		return
 	}
 
-	text := fmt.Sprintf("📢 <b>PENGUMUMAN GLOBAL</b>\n\n%s", content)
+	logger.Info("Circle deleted by admin", zap.Int64("room_id", room.ID), zap.String("slug", room.Slug))
+	var creatorID int64
+	if room.CreatedBy != nil {
+		creatorID = *room.CreatedBy
+	}
+	b.recordAudit(ctx, telegramID, models.AuditCircleDelete, creatorID, map[string]any{
+		"room_id": room.ID,
+		"slug":    room.Slug,
+		"name":    room.Name,
+		"members": len(members),
+	})
+	b.sendMessageHTML(telegramID, fmt.Sprintf("🗑️ Circle <b>%s</b> telah dihapus.", html.EscapeString(room.Name)), nil)
+	if len(members) > 0 {
+		b.circleNotice(room.ID, members, fmt.Sprintf("🗑️ <b>Circle %s telah dihapus oleh admin.</b>", html.EscapeString(room.Name)))
+	}
+}
 
-	success := 0
-	failed := 0
+// parseRoleArgs parses "<telegram_id> <role>".
+func parseRoleArgs(args string) (int64, models.StaffRole, bool) {
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return 0, "", false
+	}
+	targetID, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || !models.IsValidStaffRole(fields[1]) {
+		return 0, "", false
+	}
+	return targetID, models.StaffRole(fields[1]), true
+}
 
-	for _, userID := range users {
-		msg := tgbotapi.NewMessage(userID, text)
-		msg.ParseMode = "HTML"
-		_, err := b.api.Send(msg)
-		if err != nil {
-			failed++
-		} else {
-			success++
-		}
-		time.Sleep(50 * time.Millisecond)
+func staffRoleUsage(command string) string {
+	roles := make([]string, 0, len(models.AllStaffRoles()))
+	for _, r := range models.AllStaffRoles() {
+		roles = append(roles, "<code>"+string(r)+"</code>")
 	}
+	return fmt.Sprintf("💡 Cara pakai: <code>/%s [telegram_id] [role]</code>\nRole: %s", command, strings.Join(roles, ", "))
+}
 
-	b.sendMessageHTML(b.cfg.MaintenanceAccountID, fmt.Sprintf("✅ <b>Broadcast Pesan Selesai!</b>\n\nBerhasil: %d\nGagal: %d", success, failed), nil)
+func (b *Bot) handleGrantRole(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	if !b.can(ctx, telegramID, models.PermManageRoles) {
+		return
+	}
+
+	targetID, role, ok := parseRoleArgs(msg.CommandArguments())
+	if !ok {
+		b.sendMessageHTML(telegramID, staffRoleUsage("grant_role"), nil)
+		return
+	}
+
+	if err := b.access.Grant(ctx, telegramID, targetID, role); err != nil {
+		b.sendMessageHTML(telegramID, "❌ "+html.EscapeString(err.Error()), nil)
+		return
+	}
+
+	logger.Info("Staff role granted", zap.Int64("by", telegramID), zap.Int64("user_id", targetID), zap.String("role", string(role)))
+	b.recordAudit(ctx, telegramID, models.AuditRoleGrant, targetID, map[string]any{"role": role})
+	b.sendMessageHTML(telegramID, fmt.Sprintf("✅ <code>%d</code> sekarang %s.", targetID, models.StaffRoleLabel(role)), nil)
+	b.sendMessageHTML(targetID, fmt.Sprintf("🎖️ Kamu mendapat role <b>%s</b> di PNJ Anonymous Bot.", models.StaffRoleLabel(role)), nil)
+}
+
+func (b *Bot) handleRevokeRole(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	if !b.can(ctx, telegramID, models.PermManageRoles) {
+		return
+	}
+
+	targetID, role, ok := parseRoleArgs(msg.CommandArguments())
+	if !ok {
+		b.sendMessageHTML(telegramID, staffRoleUsage("revoke_role"), nil)
+		return
+	}
+
+	if err := b.access.Revoke(ctx, telegramID, targetID, role); err != nil {
+		b.sendMessageHTML(telegramID, "❌ "+html.EscapeString(err.Error()), nil)
+		return
+	}
+
+	logger.Info("Staff role revoked", zap.Int64("by", telegramID), zap.Int64("user_id", targetID), zap.String("role", string(role)))
+	b.recordAudit(ctx, telegramID, models.AuditRoleRevoke, targetID, map[string]any{"role": role})
+	b.sendMessageHTML(telegramID, fmt.Sprintf("🗑️ Role %s dicabut dari <code>%d</code>.", models.StaffRoleLabel(role), targetID), nil)
+}
+
+func (b *Bot) handleStaff(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	if !b.can(ctx, telegramID, models.PermManageRoles) {
+		return
+	}
+
+	members, err := b.access.Staff(ctx)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "❌ Gagal mengambil daftar staf.", nil)
+		return
+	}
+	if len(members) == 0 {
+		b.sendMessageHTML(telegramID, "📭 Belum ada staf terdaftar.", nil)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("👥 <b>Daftar Staf</b>\n\n")
+	for _, m := range members {
+		sb.WriteString(fmt.Sprintf("%s • <code>%d</code>\n", models.StaffRoleLabel(m.Role), m.TelegramID))
+	}
+	b.sendMessageHTML(telegramID, sb.String(), nil)
 }
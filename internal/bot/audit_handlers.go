@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const auditPageSize = 20
+
+// recordAudit appends a privileged action to the admin audit log. A failed
+// write is logged but never blocks the action itself.
+func (b *Bot) recordAudit(ctx context.Context, actorID int64, action models.AuditAction, targetID int64, details map[string]any) {
+	logIfErr("record_audit", b.audit.Record(ctx, actorID, action, targetID, details))
+}
+
+func broadcastAuditDetails(bc *models.Broadcast, recipients int) map[string]any {
+	details := map[string]any{
+		"broadcast_id": bc.ID,
+		"kind":         bc.Kind,
+		"segment":      describeBroadcastSegment(bc.BroadcastSegment),
+		"recipients":   recipients,
+	}
+	if bc.Content != "" {
+		details["content"] = bc.Content
+	}
+	if bc.PollID != nil {
+		details["poll_id"] = *bc.PollID
+	}
+	if bc.ScheduledAt != nil {
+		details["scheduled_at"] = bc.ScheduledAt
+	}
+	return details
+}
+
+func (b *Bot) handleAudit(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	if !b.can(ctx, telegramID, models.PermViewAudit) {
+		return
+	}
+
+	var userID int64
+	if args := strings.TrimSpace(msg.CommandArguments()); args != "" {
+		id, err := strconv.ParseInt(args, 10, 64)
+		if err != nil {
+			b.sendMessageHTML(telegramID, "💡 Cara pakai: <code>/audit [telegram_id]</code>", nil)
+			return
+		}
+		userID = id
+	}
+
+	entries, err := b.audit.Recent(ctx, userID, auditPageSize)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "❌ Gagal mengambil log audit.", nil)
+		return
+	}
+	if len(entries) == 0 {
+		b.sendMessageHTML(telegramID, "📭 Belum ada catatan audit.", nil)
+		return
+	}
+
+	var sb strings.Builder
+	if userID != 0 {
+		sb.WriteString(fmt.Sprintf("🧾 <b>Log Audit</b> • <code>%d</code>\n\n", userID))
+	} else {
+		sb.WriteString("🧾 <b>Log Audit Terakhir</b>\n\n")
+	}
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("<b>#%d</b> %s\n👤 <code>%d</code> → <b>%s</b>",
+			e.ID, e.CreatedAt.In(wib).Format("02 Jan 2006 15:04"), e.ActorID, html.EscapeString(string(e.Action))))
+		if e.TargetID != 0 {
+			sb.WriteString(fmt.Sprintf(" • target <code>%d</code>", e.TargetID))
+		}
+		sb.WriteString(fmt.Sprintf("\n<code>%s</code>\n\n", html.EscapeString(truncateText(e.Details, 200))))
+	}
+
+	kb := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📄 Export CSV", fmt.Sprintf("audit:csv:%d", userID)),
+		),
+	)
+	b.sendMessageHTML(telegramID, sb.String(), &kb)
+}
+
+func (b *Bot) handleAuditCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	if !b.can(ctx, telegramID, models.PermViewAudit) {
+		return
+	}
+
+	rawID, ok := strings.CutPrefix(data, "csv:")
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	count, err := b.audit.ExportCSV(ctx, &buf, userID)
+	if err != nil {
+		b.answerCallback(callback.ID, "❌ Gagal membuat export.")
+		return
+	}
+	b.answerCallback(callback.ID, "📄 Menyiapkan file...")
+	b.recordAudit(ctx, telegramID, models.AuditExport, userID, map[string]any{"entries": count})
+
+	name := "admin_audit.csv"
+	if userID != 0 {
+		name = fmt.Sprintf("admin_audit_%d.csv", userID)
+	}
+	doc := tgbotapi.NewDocument(telegramID, tgbotapi.FileBytes{Name: name, Bytes: buf.Bytes()})
+	doc.Caption = fmt.Sprintf("🧾 Log audit admin (%d entri)", count)
+	b.sendAPI("send_audit_csv", doc)
+}
@@ -53,26 +53,34 @@ var (
 const numShards = 128
 
 type Bot struct {
-	api          *tgbotapi.BotAPI
-	cfg          *config.Config
-	db           *database.DB
-	redisSvc     *service.RedisService
-	auth         *service.AuthService
-	chat         *service.ChatService
-	confession   *service.ConfessionService
-	profile      *service.ProfileService
-	room         *service.RoomService
-	moderation   *service.ModerationService
-	profanity    *service.ProfanityService
-	evidence     *service.EvidenceService
-	gamification *service.GamificationService
-	startedAt    time.Time
-	updateQ      chan tgbotapi.Update
-	updateWG     sync.WaitGroup
-	background   sync.WaitGroup
-	userShards   [numShards]sync.Mutex
-	handlers     map[string]func(context.Context, *tgbotapi.Message)
-	callbacks    map[string]func(context.Context, int64, string, *tgbotapi.CallbackQuery)
+	api           *tgbotapi.BotAPI
+	cfg           *config.Config
+	db            *database.DB
+	redisSvc      *service.RedisService
+	auth          *service.AuthService
+	chat          *service.ChatService
+	confession    *service.ConfessionService
+	profile       *service.ProfileService
+	room          *service.RoomService
+	moderation    *service.ModerationService
+	profanity     *service.ProfanityService
+	evidence      *service.EvidenceService
+	gamification  *service.GamificationService
+	contentReport *service.ContentReportService
+	poll          *service.PollService
+	pollChart     *service.PollChartService
+	broadcast     *service.BroadcastService
+	access        *service.AccessService
+	audit         *service.AuditService
+	startedAt     time.Time
+	updateQ       chan tgbotapi.Update
+	fanoutQ       chan circleDelivery
+	broadcastWake chan struct{}
+	updateWG      sync.WaitGroup
+	background    sync.WaitGroup
+	userShards    [numShards]sync.Mutex
+	handlers      map[string]func(context.Context, *tgbotapi.Message)
+	callbacks     map[string]func(context.Context, int64, string, *tgbotapi.CallbackQuery)
 }
 
 func (b *Bot) getUserLock(userID int64) *sync.Mutex {
@@ -91,21 +99,29 @@ func New(cfg *config.Config, db *database.DB) (*Bot, error) {
 	redisSvc := service.NewRedisService(cfg.RedisURL)
 
 	bot := &Bot{
-		api:          api,
-		cfg:          cfg,
-		db:           db,
-		redisSvc:     redisSvc,
-		auth:         service.NewAuthService(db, emailSender, cfg, redisSvc.GetClient()),
-		chat:         service.NewChatService(db, redisSvc, cfg.MaxSearchPerMinute),
-		confession:   service.NewConfessionService(db, cfg),
-		profile:      service.NewProfileService(db, cfg),
-		room:         service.NewRoomService(db),
-		moderation:   service.NewModerationService(cfg),
-		profanity:    service.NewProfanityService(),
-		evidence:     service.NewEvidenceService(db, redisSvc.GetClient()),
-		gamification: service.NewGamificationService(db),
-		startedAt:    time.Now(),
-		updateQ:      make(chan tgbotapi.Update, cfg.MaxUpdateQueue),
+		api:           api,
+		cfg:           cfg,
+		db:            db,
+		redisSvc:      redisSvc,
+		auth:          service.NewAuthService(db, emailSender, cfg, redisSvc.GetClient()),
+		chat:          service.NewChatService(db, redisSvc, cfg.MaxSearchPerMinute),
+		confession:    service.NewConfessionService(db, cfg),
+		profile:       service.NewProfileService(db, cfg),
+		room:          service.NewRoomService(db, redisSvc, cfg),
+		moderation:    service.NewModerationService(cfg),
+		profanity:     service.NewProfanityService(),
+		evidence:      service.NewEvidenceService(db, redisSvc.GetClient()),
+		gamification:  service.NewGamificationService(db),
+		contentReport: service.NewContentReportService(db, cfg),
+		poll:          service.NewPollService(db, redisSvc, cfg),
+		pollChart:     service.NewPollChartService(redisSvc),
+		broadcast:     service.NewBroadcastService(db),
+		access:        service.NewAccessService(db, cfg),
+		audit:         service.NewAuditService(db),
+		startedAt:     time.Now(),
+		updateQ:       make(chan tgbotapi.Update, cfg.MaxUpdateQueue),
+		fanoutQ:       make(chan circleDelivery, cfg.CircleFanoutQueue),
+		broadcastWake: make(chan struct{}, 1),
 	}
 
 	bot.registerHandlers()
@@ -115,48 +131,84 @@ func New(cfg *config.Config, db *database.DB) (*Bot, error) {
 
 func (b *Bot) registerHandlers() {
 	b.handlers = map[string]func(context.Context, *tgbotapi.Message){
-		"start":        b.handleStart,
-		"regist":       b.handleRegist,
-		"help":         b.handleHelp,
-		"about":        b.handleAbout,
-		"cancel":       b.handleCancel,
-		"search":       b.handleSearch,
-		"next":         b.handleNext,
-		"stop":         b.handleStop,
-		"confess":      b.handleConfess,
-		"confessions":  b.handleConfessions,
-		"react":        b.handleReact,
-		"reply":        b.handleReply,
-		"view_replies": b.handleViewReplies,
-		"poll":         b.handlePoll,
-		"polls":        b.handleViewPolls,
-		"vote_poll":    b.handleVotePoll,
-		"whisper":      b.handleWhisper,
-		"profile":      b.handleProfile,
-		"stats":        b.handleStats,
-		"leaderboard":  b.handleLeaderboard,
-		"admin_poll":   b.handleAdminPoll,
-		"broadcast":    b.handleBroadcast,
-		"edit":         b.handleEdit,
-		"report":       b.handleReport,
-		"block":        b.handleBlock,
-		"circles":      b.handleCircles,
-		"leave_circle": b.handleLeaveCircle,
+		"start":             b.handleStart,
+		"regist":            b.handleRegist,
+		"help":              b.handleHelp,
+		"about":             b.handleAbout,
+		"cancel":            b.handleCancel,
+		"search":            b.handleSearch,
+		"next":              b.handleNext,
+		"stop":              b.handleStop,
+		"chatrequests":      b.handleChatRequests,
+		"confess":           b.handleConfess,
+		"confessions":       b.handleConfessions,
+		"myconfessions":     b.handleMyConfessions,
+		"search_confess":    b.handleSearchConfess,
+		"tag":               b.handleTag,
+		"react":             b.handleReact,
+		"reply":             b.handleReply,
+		"view_replies":      b.handleViewReplies,
+		"poll":              b.handlePoll,
+		"dept_poll":         b.handleDeptPoll,
+		"polls":             b.handleViewPolls,
+		"vote_poll":         b.handleVotePoll,
+		"whisper":           b.handleWhisper,
+		"profile":           b.handleProfile,
+		"stats":             b.handleStats,
+		"leaderboard":       b.handleLeaderboard,
+		"admin_poll":        b.handleAdminPoll,
+		"broadcast":         b.handleBroadcast,
+		"admin_reports":     b.handleAdminReports,
+		"broadcast_status":  b.handleBroadcastStatus,
+		"broadcast_cancel":  b.handleBroadcastCancel,
+		"circle_restore":    b.handleCircleRestore,
+		"circle_delete":     b.handleCircleDelete,
+		"grant_role":        b.handleGrantRole,
+		"revoke_role":       b.handleRevokeRole,
+		"staff":             b.handleStaff,
+		"audit":             b.handleAudit,
+		"edit":              b.handleEdit,
+		"report":            b.handleReport,
+		"block":             b.handleBlock,
+		"circles":           b.handleCircles,
+		"leave_circle":      b.handleLeaveCircle,
+		"circle_kick":       b.handleCircleKick,
+		"circle_mute":       b.handleCircleMute,
+		"circle_pin":        b.handleCirclePin,
+		"circle_mod":        b.handleCircleMod,
+		"circle_invite":     b.handleCircleInvite,
+		"circle_visibility": b.handleCircleVisibility,
+		"circle_history":    b.handleCircleHistory,
+		"circle_slowmode":   b.handleCircleSlowMode,
+		"my_circles":        b.handleMyCircles,
+		"circle_remove":     b.handleCircleRemove,
 	}
 
 	b.callbacks = map[string]func(context.Context, int64, string, *tgbotapi.CallbackQuery){
-		"gender":  b.handleGenderCallback,
-		"dept":    b.handleDeptCallback,
-		"search":  b.handleSearchCallback,
-		"chat":    b.handleChatActionCallback,
-		"menu":    b.handleMenuCallback,
-		"edit":    b.handleEditCallback,
-		"vote":    b.handleVoteCallback,
-		"year":    b.handleYearCallback,
-		"react":   b.handleReactionCallback,
-		"whisper": b.handleWhisperCallback,
-		"legal":   b.handleLegalCallback,
-		"circle":  b.handleCircleCallback,
+		"gender":     b.handleGenderCallback,
+		"dept":       b.handleDeptCallback,
+		"search":     b.handleSearchCallback,
+		"chat":       b.handleChatActionCallback,
+		"menu":       b.handleMenuCallback,
+		"edit":       b.handleEditCallback,
+		"vote":       b.handleVoteCallback,
+		"year":       b.handleYearCallback,
+		"react":      b.handleReactionCallback,
+		"whisper":    b.handleWhisperCallback,
+		"legal":      b.handleLegalCallback,
+		"circle":     b.handleCircleCallback,
+		"myconf":     b.handleMyConfessionCallback,
+		"creport":    b.handleContentReportCallback,
+		"modq":       b.handleModerationQueueCallback,
+		"pconf":      b.handlePendingConfessionCallback,
+		"authorchat": b.handleAuthorChatCallback,
+		"pollclose":  b.handlePollCloseCallback,
+		"pollres":    b.handlePollResultsCallback,
+		"pollbd":     b.handlePollBreakdownCallback,
+		"circlehist": b.handleCircleHistoryCallback,
+		"broadcast":  b.handleBroadcastCallback,
+		"audit":      b.handleAuditCallback,
+		"cphoto":     b.handleConfessionPhotoCallback,
 	}
 }
 
@@ -343,6 +395,8 @@ func (b *Bot) Start(ctx context.Context) error {
 
 	logger.Info("🚀 Starting PNJ Anonymous Bot...")
 
+	logIfErr("bootstrap_owner", b.access.Bootstrap(runCtx))
+
 	b.background.Add(1)
 	go func() {
 		defer b.background.Done()
@@ -355,6 +409,30 @@ func (b *Bot) Start(ctx context.Context) error {
 		b.startQueueWorker(runCtx)
 	}()
 
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+		b.startPollWorker(runCtx)
+	}()
+
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+		b.startCircleFanout(runCtx)
+	}()
+
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+		b.startCircleArchiver(runCtx)
+	}()
+
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+		b.startBroadcastWorker(runCtx)
+	}()
+
 	b.startUpdateWorkers()
 
 	commands := []tgbotapi.BotCommand{
@@ -363,16 +441,31 @@ func (b *Bot) Start(ctx context.Context) error {
 		{Command: "search", Description: "🔍 Cari partner chat anonim"},
 		{Command: "next", Description: "⏭️ Skip ke partner berikutnya"},
 		{Command: "stop", Description: "🛑 Hentikan chat saat ini"},
+		{Command: "chatrequests", Description: "💬 Atur ajakan chat dari pembaca confession"},
 		{Command: "confess", Description: "💬 Kirim confession anonim"},
 		{Command: "confessions", Description: "📋 Lihat confession terbaru"},
+		{Command: "myconfessions", Description: "🗂️ Kelola confession kamu"},
+		{Command: "search_confess", Description: "🔎 Cari confession (contoh: /search_confess ujian)"},
+		{Command: "tag", Description: "🏷️ Jelajahi confession per hashtag"},
 		{Command: "react", Description: "❤️ Reaksi ke confession"},
 		{Command: "reply", Description: "Balas confession (contoh: /reply 1 Hallo!)"},
 		{Command: "view_replies", Description: "Lihat balasan confession (contoh: /view_replies 1)"},
 		{Command: "poll", Description: "🗳️ Buat polling anonim"},
+		{Command: "dept_poll", Description: "🏛️ Buat polling khusus jurusanmu"},
 		{Command: "polls", Description: "📊 Lihat daftar polling"},
 		{Command: "vote_poll", Description: "🗳️ Ikut memilih polling (contoh: /vote_poll 1)"},
 		{Command: "whisper", Description: "📢 Kirim whisper ke jurusan"},
 		{Command: "circles", Description: "👥 Gabung Group Circle Anonim"},
+		{Command: "circle_kick", Description: "🚫 (Moderator) Keluarkan anggota circle (reply pesan)"},
+		{Command: "circle_mute", Description: "🔇 (Moderator) Mute anggota circle (contoh: /circle_mute 1h)"},
+		{Command: "circle_pin", Description: "📌 (Moderator) Sematkan pesan circle (reply pesan)"},
+		{Command: "circle_mod", Description: "🛡️ (Pemilik) Angkat/cabut moderator circle (reply pesan)"},
+		{Command: "circle_invite", Description: "🔗 (Moderator) Link undangan circle"},
+		{Command: "circle_visibility", Description: "👁️ (Pemilik) Atur visibilitas circle"},
+		{Command: "my_circles", Description: "🗣️ Pilih circle tempat kamu bicara"},
+		{Command: "circle_history", Description: "🕘 Lihat riwayat pesan circle"},
+		{Command: "circle_slowmode", Description: "🐢 (Moderator) Atur slow mode circle"},
+		{Command: "circle_remove", Description: "🗑️ (Moderator) Hapus pesan dari riwayat circle (reply pesan)"},
 		{Command: "profile", Description: "👤 Lihat profil kamu"},
 		{Command: "stats", Description: "📊 Statistik kamu"},
 		{Command: "leaderboard", Description: "🏆 Peringkat pengguna teraktif"},
@@ -381,7 +474,16 @@ func (b *Bot) Start(ctx context.Context) error {
 		{Command: "help", Description: "❓ Bantuan & panduan"},
 		{Command: "cancel", Description: "❌ Batalkan aksi saat ini"},
 		{Command: "admin_poll", Description: "📢 (Admin) Buat polling global"},
-		{Command: "broadcast", Description: "📢 (Admin) Broadcast pesan global"},
+		{Command: "broadcast", Description: "📢 (Admin) Broadcast pesan (per segmen/terjadwal)"},
+		{Command: "admin_reports", Description: "🚩 (Admin) Antrean review konten"},
+		{Command: "broadcast_status", Description: "📊 (Admin) Progres broadcast"},
+		{Command: "broadcast_cancel", Description: "🛑 (Admin) Hentikan broadcast"},
+		{Command: "circle_restore", Description: "♻️ (Admin) Pulihkan circle yang diarsipkan"},
+		{Command: "circle_delete", Description: "🗑️ (Admin) Hapus circle secara permanen"},
+		{Command: "grant_role", Description: "🎖️ (Owner) Berikan role staf"},
+		{Command: "revoke_role", Description: "🚫 (Owner) Cabut role staf"},
+		{Command: "staff", Description: "👥 (Owner) Daftar staf"},
+		{Command: "audit", Description: "🧾 (Admin) Log audit aksi staf"},
 	}
 	cmdCfg := tgbotapi.NewSetMyCommands(commands...)
 	if _, err := b.api.Request(cmdCfg); err != nil {
@@ -390,6 +492,7 @@ func (b *Bot) Start(ctx context.Context) error {
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
+	u.AllowedUpdates = []string{"message", "callback_query", "poll", "my_chat_member"}
 
 	updates := b.api.GetUpdatesChan(u)
 
@@ -487,6 +590,11 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 		}
 		defer lock.Unlock()
 
+		if update.MyChatMember != nil {
+			b.handleMyChatMember(ctx, update.MyChatMember)
+			return
+		}
+
 		// Only check streak once per user per day using Redis flag
 		streakKey := fmt.Sprintf("streak_checked:%d", userID)
 		alreadyChecked, _ := b.redisSvc.GetClient().Exists(ctx, streakKey).Result()
@@ -510,6 +618,11 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 		return
 	}
 
+	if update.Poll != nil {
+		b.handleNativePollUpdate(ctx, update.Poll)
+		return
+	}
+
 	if update.Message == nil {
 		return
 	}
@@ -526,6 +639,12 @@ func classifyUpdate(update tgbotapi.Update) string {
 	if update.CallbackQuery != nil {
 		return "callback"
 	}
+	if update.Poll != nil {
+		return "poll"
+	}
+	if update.MyChatMember != nil {
+		return "my_chat_member"
+	}
 	if update.Message == nil {
 		return "other"
 	}
@@ -542,6 +661,9 @@ func (b *Bot) extractUpdateUserID(update tgbotapi.Update) (int64, bool) {
 	if update.Message != nil && update.Message.From != nil {
 		return update.Message.From.ID, true
 	}
+	if update.MyChatMember != nil {
+		return update.MyChatMember.From.ID, true
+	}
 	return 0, false
 }
 
@@ -592,6 +714,8 @@ func (b *Bot) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 		b.handleChatMessage(ctx, msg)
 	case models.StateAwaitingConfess:
 		b.handleConfessionInput(ctx, msg)
+	case models.StateAwaitingConfessEdit:
+		b.handleConfessionEditInput(ctx, msg, stateData)
 	case models.StateAwaitingReport:
 		b.handleReportInput(ctx, msg)
 	case models.StateAwaitingWhisper:
@@ -655,6 +779,7 @@ func (b *Bot) sendMessage(chatID int64, text string, keyboard *tgbotapi.InlineKe
 
 	if _, err := b.api.Send(msg); err != nil {
 		logger.Error("Error sending message", zap.Int64("chat_id", chatID), zap.Error(err))
+		b.handleSendError(chatID, err)
 	}
 }
 
@@ -667,6 +792,7 @@ func (b *Bot) sendMessageHTML(chatID int64, text string, keyboard *tgbotapi.Inli
 
 	if _, err := b.api.Send(msg); err != nil {
 		logger.Error("Error sending HTML message", zap.Int64("chat_id", chatID), zap.Error(err))
+		b.handleSendError(chatID, err)
 	}
 }
 
@@ -0,0 +1,343 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/logger"
+	"github.com/pnj-anonymous-bot/internal/metrics"
+	"github.com/pnj-anonymous-bot/internal/models"
+	"go.uber.org/zap"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// wib is the timezone admins use when scheduling broadcasts.
+var wib = time.FixedZone("WIB", 7*60*60)
+
+const broadcastTimeLayout = "2006-01-02T15:04"
+
+const (
+	broadcastBatchSize   = 50
+	broadcastMaxAttempts = 3
+	broadcastIdlePoll    = 30 * time.Second
+)
+
+// wakeBroadcastWorker tells the broadcast worker a new job is waiting.
+func (b *Bot) wakeBroadcastWorker() {
+	select {
+	case b.broadcastWake <- struct{}{}:
+	default:
+	}
+}
+
+// startBroadcastWorker delivers persisted broadcast jobs one at a time. Jobs
+// left running by a previous process are resumed from their pending
+// recipients.
+func (b *Bot) startBroadcastWorker(ctx context.Context) {
+	limiter := newFanoutLimiter(b.cfg.BroadcastPerSecond, 60)
+	ticker := time.NewTicker(broadcastIdlePoll)
+	defer ticker.Stop()
+
+	for {
+		if started, err := b.broadcast.ActivateDueBroadcasts(ctx); err != nil {
+			logger.Error("⚠️ Failed to start scheduled broadcasts", zap.Error(err))
+		} else if started > 0 {
+			logger.Info("Scheduled broadcasts started", zap.Int("count", started))
+		}
+
+		for b.runNextBroadcast(ctx, limiter) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-b.broadcastWake:
+		}
+	}
+}
+
+// runNextBroadcast works on the oldest running broadcast until it finishes or
+// is cancelled. It returns false when there is nothing to do or the worker is
+// shutting down.
+func (b *Bot) runNextBroadcast(ctx context.Context, limiter *fanoutLimiter) bool {
+	job, err := b.db.GetNextRunningBroadcast(ctx)
+	if err != nil {
+		logger.Error("⚠️ Broadcast worker error", zap.Error(err))
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	var poll *models.Poll
+	if job.PollID != nil {
+		poll, err = b.db.GetPoll(ctx, *job.PollID)
+		if err != nil || poll == nil {
+			logger.Error("Broadcast poll is missing, cancelling job", zap.Int64("broadcast_id", job.ID), zap.Error(err))
+			_, err := b.db.FinishBroadcast(ctx, job.ID, models.BroadcastCancelled)
+			logIfErr("cancel_broadcast_missing_poll", err)
+			return true
+		}
+	}
+
+	for {
+		current, err := b.db.GetBroadcast(ctx, job.ID)
+		if err != nil || current == nil {
+			logIfErr("get_broadcast", err)
+			return false
+		}
+		if current.Status != models.BroadcastRunning {
+			return true
+		}
+
+		recipients, err := b.db.GetPendingRecipients(ctx, job.ID, broadcastBatchSize)
+		if err != nil {
+			logger.Error("⚠️ Broadcast worker error", zap.Int64("broadcast_id", job.ID), zap.Error(err))
+			return false
+		}
+		if len(recipients) == 0 {
+			b.finishBroadcast(ctx, job)
+			return true
+		}
+
+		for _, chatID := range recipients {
+			if !b.deliverBroadcast(ctx, limiter, job, poll, chatID) {
+				return false
+			}
+		}
+	}
+}
+
+func (b *Bot) finishBroadcast(ctx context.Context, job *models.Broadcast) {
+	finished, err := b.db.FinishBroadcast(ctx, job.ID, models.BroadcastDone)
+	if err != nil || !finished {
+		logIfErr("finish_broadcast", err)
+		return
+	}
+	metrics.BroadcastDuration.Observe(time.Since(job.CreatedAt).Seconds())
+
+	progress, err := b.db.GetBroadcastProgress(ctx, job.ID)
+	logIfErr("get_broadcast_progress", err)
+
+	logger.Info("Broadcast finished",
+		zap.Int64("broadcast_id", job.ID),
+		zap.Int("sent", progress.Sent),
+		zap.Int("failed", progress.Failed),
+		zap.Int("blocked", progress.Blocked),
+	)
+	if job.CreatedBy == 0 {
+		return
+	}
+	b.sendMessageHTML(job.CreatedBy, fmt.Sprintf("✅ <b>Broadcast #%d Selesai!</b>\n\n%s", job.ID, formatBroadcastProgress(progress)), nil)
+}
+
+// deliverBroadcast sends the job to one recipient and records the outcome.
+// It honours Telegram's retry_after and returns false only when ctx is done.
+func (b *Bot) deliverBroadcast(ctx context.Context, limiter *fanoutLimiter, job *models.Broadcast, poll *models.Poll, chatID int64) bool {
+	status, errText := models.RecipientFailed, "rate limited"
+
+	for attempt := 0; attempt < broadcastMaxAttempts; attempt++ {
+		if wait := limiter.delay(chatID, time.Now()); wait > 0 && !sleepContext(ctx, wait) {
+			return false
+		}
+		limiter.record(chatID, time.Now())
+
+		err := b.sendBroadcast(ctx, job, poll, chatID)
+		if err == nil {
+			status, errText = models.RecipientSent, ""
+			break
+		}
+		if isBotBlockedError(err) {
+			status, errText = models.RecipientBlocked, err.Error()
+			b.markUnreachable(ctx, chatID)
+			break
+		}
+		if d := retryAfter(err); d > 0 {
+			limiter.pause(time.Now(), d)
+			if !sleepContext(ctx, d) {
+				return false
+			}
+			continue
+		}
+
+		status, errText = models.RecipientFailed, err.Error()
+		break
+	}
+
+	logIfErr("mark_broadcast_recipient", b.db.MarkBroadcastRecipient(ctx, job.ID, chatID, status, errText))
+	return true
+}
+
+func (b *Bot) sendBroadcast(ctx context.Context, job *models.Broadcast, poll *models.Poll, chatID int64) error {
+	switch job.Kind {
+	case models.BroadcastNativePoll:
+		return b.sendNativePoll(ctx, chatID, poll)
+	case models.BroadcastPoll:
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📢 <b>PENGUMUMAN & POLLING GLOBAL</b> 🗳️\n\n%s\n\n<i>Klik di bawah untuk memberikan suara kamu:</i>", html.EscapeString(poll.Question)))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = PollVoteKeyboard(poll, nil)
+		_, err := b.api.Send(msg)
+		return err
+	case models.BroadcastPollResults:
+		b.stopChatNativePoll(ctx, poll.ID, chatID)
+		msg := tgbotapi.NewMessage(chatID, job.Content)
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	default:
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📢 <b>PENGUMUMAN GLOBAL</b>\n\n%s", job.Content))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+}
+
+func formatBroadcastProgress(p models.BroadcastProgress) string {
+	return fmt.Sprintf("Terkirim: %d\nGagal: %d\nMemblokir bot: %d\nMenunggu: %d\nTotal: %d",
+		p.Sent, p.Failed, p.Blocked, p.Pending, p.Total())
+}
+
+func broadcastStatusLabel(status models.BroadcastStatus) string {
+	switch status {
+	case models.BroadcastDraft:
+		return "📝 Menunggu konfirmasi"
+	case models.BroadcastScheduled:
+		return "🗓️ Terjadwal"
+	case models.BroadcastRunning:
+		return "⏳ Berjalan"
+	case models.BroadcastDone:
+		return "✅ Selesai"
+	case models.BroadcastCancelled:
+		return "🛑 Dibatalkan"
+	default:
+		return string(status)
+	}
+}
+
+// parseBroadcastArgs parses "[filter...] | pesan" where filters are
+// dept:<nama>, year:<angkatan>, gender:<l|p>, level:<min>, active:<hari>d and
+// at:<YYYY-MM-DDTHH:MM> in WIB. Without "|" the whole text is the message.
+func parseBroadcastArgs(args string) (*models.Broadcast, string) {
+	bc := &models.Broadcast{Kind: models.BroadcastMessage}
+
+	filters, content, found := strings.Cut(args, "|")
+	if !found {
+		filters, content = "", args
+	}
+	bc.Content = strings.TrimSpace(content)
+	if bc.Content == "" {
+		return nil, "⚠️ Pesan broadcast tidak boleh kosong."
+	}
+
+	for _, token := range strings.Fields(filters) {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || value == "" {
+			return nil, fmt.Sprintf("⚠️ Filter tidak dikenal: <code>%s</code>", html.EscapeString(token))
+		}
+
+		switch strings.ToLower(key) {
+		case "dept":
+			dept, ok := matchDepartment(value)
+			if !ok {
+				return nil, fmt.Sprintf("⚠️ Jurusan <code>%s</code> tidak ditemukan atau ambigu.", html.EscapeString(value))
+			}
+			bc.Department = dept
+		case "year":
+			year, err := strconv.Atoi(value)
+			if err != nil || !models.IsValidEntryYear(year) {
+				return nil, fmt.Sprintf("⚠️ Angkatan harus antara %d dan %d.", models.MinEntryYear, models.CurrentEntryYear())
+			}
+			bc.Year = year
+		case "gender":
+			switch strings.ToLower(value) {
+			case "l", "male", "laki-laki":
+				bc.Gender = models.GenderMale
+			case "p", "female", "perempuan":
+				bc.Gender = models.GenderFemale
+			default:
+				return nil, "⚠️ Gender harus <code>l</code> atau <code>p</code>."
+			}
+		case "level":
+			level, err := strconv.Atoi(value)
+			if err != nil || level < 1 {
+				return nil, "⚠️ Level minimal harus angka positif."
+			}
+			bc.MinLevel = level
+		case "active":
+			days, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(value), "d"))
+			if err != nil || days < 1 || days > 365 {
+				return nil, "⚠️ Filter aktif harus antara 1d dan 365d."
+			}
+			bc.ActiveDays = days
+		case "at":
+			at, err := time.ParseInLocation(broadcastTimeLayout, value, wib)
+			if err != nil {
+				return nil, "⚠️ Format jadwal: <code>at:2025-01-31T19:00</code> (WIB)."
+			}
+			bc.ScheduledAt = &at
+		default:
+			return nil, fmt.Sprintf("⚠️ Filter tidak dikenal: <code>%s</code>", html.EscapeString(token))
+		}
+	}
+
+	return bc, ""
+}
+
+// matchDepartment finds the single department whose name contains query.
+func matchDepartment(query string) (models.Department, bool) {
+	query = strings.ToLower(query)
+	var match models.Department
+	for _, d := range models.AllDepartments() {
+		if strings.Contains(strings.ToLower(string(d)), query) {
+			if match != "" {
+				return "", false
+			}
+			match = d
+		}
+	}
+	return match, match != ""
+}
+
+func describeBroadcastTarget(bc *models.Broadcast) string {
+	if bc.Kind == models.BroadcastPollResults && bc.PollID != nil {
+		return fmt.Sprintf("Pemilih polling #%d", *bc.PollID)
+	}
+	return describeBroadcastSegment(bc.BroadcastSegment)
+}
+
+func describeBroadcastSegment(seg models.BroadcastSegment) string {
+	if seg.IsEmpty() {
+		return "Semua pengguna"
+	}
+
+	var parts []string
+	if seg.Department != "" {
+		parts = append(parts, "Jurusan "+string(seg.Department))
+	}
+	if seg.Year != 0 {
+		parts = append(parts, fmt.Sprintf("Angkatan %d", seg.Year))
+	}
+	if seg.Gender != "" {
+		parts = append(parts, string(seg.Gender))
+	}
+	if seg.MinLevel > 0 {
+		parts = append(parts, fmt.Sprintf("Level ≥ %d", seg.MinLevel))
+	}
+	if seg.ActiveDays > 0 {
+		parts = append(parts, fmt.Sprintf("Aktif %d hari terakhir", seg.ActiveDays))
+	}
+	return strings.Join(parts, " • ")
+}
+
+func formatBroadcastSchedule(at *time.Time) string {
+	if at == nil {
+		return "Sekarang"
+	}
+	return at.In(wib).Format("02 Jan 2006 15:04") + " WIB"
+}
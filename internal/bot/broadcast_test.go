@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+func TestParseBroadcastArgs(t *testing.T) {
+	bc, errMsg := parseBroadcastArgs("Halo semua")
+	if errMsg != "" || bc.Content != "Halo semua" || !bc.IsEmpty() || bc.ScheduledAt != nil {
+		t.Fatalf("plain message should target everyone now, got %+v %q", bc, errMsg)
+	}
+
+	bc, errMsg = parseBroadcastArgs("dept:akuntansi gender:p level:3 active:30d at:2030-01-31T19:00 | <b>Info</b> jurusan")
+	if errMsg != "" {
+		t.Fatalf("unexpected error: %s", errMsg)
+	}
+	if bc.Department != models.DeptAkuntansi || bc.Gender != models.GenderFemale || bc.MinLevel != 3 || bc.ActiveDays != 30 {
+		t.Errorf("unexpected segment: %+v", bc.BroadcastSegment)
+	}
+	if bc.Content != "<b>Info</b> jurusan" {
+		t.Errorf("unexpected content: %q", bc.Content)
+	}
+	want := time.Date(2030, 1, 31, 12, 0, 0, 0, time.UTC)
+	if bc.ScheduledAt == nil || !bc.ScheduledAt.Equal(want) {
+		t.Errorf("schedule should be read as WIB, got %v", bc.ScheduledAt)
+	}
+
+	for _, args := range []string{
+		"dept:teknik | ambigu",
+		"year:1990 | terlalu lama",
+		"gender:x | salah",
+		"at:besok | salah",
+		"warna:merah | salah",
+		"dept:akuntansi |  ",
+	} {
+		if _, errMsg := parseBroadcastArgs(args); errMsg == "" {
+			t.Errorf("expected %q to be rejected", args)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package bot
 import (
 	"context"
 	"fmt"
+	"html"
 	"strconv"
 	"strings"
 
@@ -344,22 +345,53 @@ func (b *Bot) handleVoteCallback(ctx context.Context, telegramID int64, value st
 	}
 
 	pollID, _ := strconv.ParseInt(parts[0], 10, 64)
-	optionID, _ := strconv.ParseInt(parts[1], 10, 64)
 
-	err := b.db.VotePoll(ctx, pollID, telegramID, optionID)
+	poll, err := b.db.GetPoll(ctx, pollID)
+	if err != nil || poll == nil {
+		b.answerCallback(callback.ID, "❌ Polling tidak ditemukan.")
+		return
+	}
+	if !b.canAccessPoll(ctx, telegramID, poll) {
+		b.answerCallback(callback.ID, "⚠️ Polling ini khusus untuk jurusan lain.")
+		return
+	}
+
+	switch {
+	case parts[1] == "submit":
+		err = b.poll.SubmitBallot(ctx, poll, telegramID)
+	case poll.Type == models.PollSingle:
+		optionID, _ := strconv.ParseInt(parts[1], 10, 64)
+		err = b.poll.Vote(ctx, pollID, telegramID, optionID)
+	default:
+		optionID, _ := strconv.ParseInt(parts[1], 10, 64)
+		selected, toggleErr := b.poll.ToggleOption(ctx, poll, telegramID, optionID)
+		if toggleErr != nil {
+			b.answerCallback(callback.ID, "⚠️ "+toggleErr.Error())
+			return
+		}
+		kb := PollVoteKeyboard(poll, selected)
+		editMsg := tgbotapi.NewEditMessageReplyMarkup(telegramID, callback.Message.MessageID, kb)
+		b.sendAPI("edit_poll_vote", editMsg)
+		b.answerCallback(callback.ID, "")
+		return
+	}
 	if err != nil {
 		b.answerCallback(callback.ID, "⚠️ "+err.Error())
 		return
 	}
 
-	poll, err := b.db.GetPoll(ctx, pollID)
+	poll, err = b.db.GetPoll(ctx, pollID)
 	if err == nil && poll != nil {
-		kb := PollVoteKeyboard(poll.ID, poll.Options)
+		kb := PollVoteKeyboard(poll, nil)
 		editMsg := tgbotapi.NewEditMessageReplyMarkup(telegramID, callback.Message.MessageID, kb)
 		b.sendAPI("edit_poll_vote", editMsg)
 	}
 
 	b.answerCallback(callback.ID, "✅ Suara kamu berhasil direkam!")
+	if poll != nil {
+		b.sendPollChart(ctx, telegramID, poll)
+	}
+	b.rewardPollAuthor(ctx, pollID)
 }
 
 func (b *Bot) handleReactionCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
@@ -390,6 +422,9 @@ func (b *Bot) handleReactionCallback(ctx context.Context, telegramID int64, data
 
 	counts, _ := b.confession.GetReactionCounts(ctx, confessionID)
 	newKb := ConfessionReactionKeyboard(confessionID, counts)
+	if hasPhotoNav(callback.Message) {
+		newKb.InlineKeyboard = append(newKb.InlineKeyboard, ConfessionPhotoNavRow(confessionID))
+	}
 
 	editMsg := tgbotapi.NewEditMessageReplyMarkup(
 		telegramID,
@@ -449,20 +484,15 @@ Bergabung ke circle akan mengakhiri chat kamu saat ini secara otomatis. Apakah k
 		}
 
 		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_join")
-
-		kb := LeaveCircleKeyboard()
-		text := fmt.Sprintf(`🎉 <b>Berhasil Terhubung ke Circle %s</b>
-
-━━━━━━━━━━━━━━━━━━━
-Sekarang semua pesan yang kamu ketik akan dikirim ke semua anggota circle ini secara anonim.
-
-💡 Gunakan /leave_circle atau klik tombol di bawah untuk keluar.
-
-<i>Mulai ngobrol sekarang...</i>`, room.Name)
-
-		b.sendMessageHTML(telegramID, text, &kb)
+		b.sendCircleWelcome(ctx, telegramID, room)
 
 	case "create":
+		if !b.can(ctx, telegramID, models.PermManageCircles) {
+			if err := b.room.CanCreateRoom(ctx, telegramID); err != nil {
+				b.answerCallback(callback.ID, "⚠️ "+err.Error())
+				return
+			}
+		}
 		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_create")
 
 		logIfErr("set_state_room_name", b.db.SetUserState(ctx, telegramID, models.StateAwaitingRoomName, ""))
@@ -492,9 +522,31 @@ Sekarang semua pesan yang kamu ketik akan dikirim ke semua anggota circle ini se
 
 ━━━━━━━━━━━━━━━━━━━
 Pesan kamu sekarang dikirim ke circle ini. Private chat sebelumnya telah dihentikan.
+🎭 Alias kamu di circle ini: <b>%s</b>
 
-<i>Mulai ngobrol sekarang...</i>`, room.Name)
+<i>Mulai ngobrol sekarang...</i>`, room.Name, html.EscapeString(b.circleAlias(ctx, room.ID, telegramID)))
 		b.sendMessageHTML(telegramID, text, &kb)
+		b.showCirclePin(ctx, telegramID, room)
+		b.sendCircleHistory(ctx, telegramID, room, 0, b.cfg.CircleHistoryOnJoin)
+
+	case "confirm_invite":
+		if len(parts) < 2 {
+			return
+		}
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_confirm_invite")
+
+		partnerID, _ := b.chat.StopChat(ctx, telegramID)
+		if partnerID > 0 {
+			b.sendMessage(partnerID, "👋 *Partner kamu telah memutus chat.*", nil)
+		}
+		b.joinCircleByInvite(ctx, telegramID, parts[1])
+
+	case "vis":
+		if len(parts) < 2 {
+			return
+		}
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_visibility")
+		b.setCircleVisibility(ctx, telegramID, models.RoomVisibility(parts[1]))
 
 	case "stay_chat":
 		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_stay_chat")
@@ -507,13 +559,259 @@ Pesan kamu sekarang dikirim ke circle ini. Private chat sebelumnya telah dihenti
 	case "leave_next":
 		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_leave_next")
 
-		logIfErr("leave_room_for_next", b.room.LeaveRoom(ctx, telegramID))
-		b.sendMessageHTML(telegramID, "👋 <b>Kamu telah keluar dari circle.</b>\n⏭️ <i>Mencari partner baru...</i>", nil)
+		b.sendMessageHTML(telegramID, "💬 <b>Kamu berhenti bicara di circle.</b> Pesan circle tetap kamu terima.\n⏭️ <i>Mencari partner baru...</i>", nil)
 
 		b.startSearch(ctx, telegramID, "", "", 0)
 
+	case "admin_delete":
+		if len(parts) < 2 || !b.can(ctx, telegramID, models.PermManageCircles) {
+			return
+		}
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_admin_delete")
+		b.deleteCircle(ctx, telegramID, parts[1])
+
+	case "admin_keep":
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_admin_keep")
+		b.answerCallback(callback.ID, "👌 Circle tidak jadi dihapus.")
+
+	case "mine":
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_list")
+		b.showMyCircles(ctx, telegramID)
+
+	case "speak", "silence", "leave_id":
+		if len(parts) < 2 {
+			return
+		}
+		roomID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return
+		}
+		switch action {
+		case "speak":
+			b.switchCircle(ctx, telegramID, roomID, callback)
+		case "silence":
+			b.toggleCircleMute(ctx, telegramID, roomID, callback)
+		default:
+			b.leaveCircleByID(ctx, telegramID, roomID, callback)
+		}
+
 	case "stay":
 		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_stay")
 		b.answerCallback(callback.ID, "👌 Oke, kamu tetap di circle.")
 	}
 }
+
+func (b *Bot) handleMyConfessionCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	parts := strings.SplitN(data, ":", 2)
+	action := parts[0]
+
+	if action == "keep" {
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_myconf_keep")
+		b.answerCallback(callback.ID, "👌 Confession tidak jadi dihapus.")
+		return
+	}
+
+	if len(parts) < 2 {
+		return
+	}
+
+	confessionID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return
+	}
+
+	switch action {
+	case "edit":
+		confession, _ := b.db.GetConfession(ctx, confessionID)
+		if confession == nil || confession.AuthorID != telegramID {
+			b.answerCallback(callback.ID, "❌ Confession tidak ditemukan.")
+			return
+		}
+		if !b.confession.CanEdit(confession) {
+			b.answerCallback(callback.ID, "⏰ Batas waktu edit sudah lewat.")
+			return
+		}
+		if reports, _ := b.db.CountContentReports(ctx, models.ContentConfession, confessionID); reports > 0 {
+			b.answerCallback(callback.ID, "🚩 Confession yang sudah dilaporkan tidak bisa diedit.")
+			return
+		}
+
+		logIfErr("set_state_awaiting_confess_edit", b.db.SetUserState(ctx, telegramID, models.StateAwaitingConfessEdit, fmt.Sprintf("%d", confessionID)))
+		b.answerCallback(callback.ID, "")
+		b.sendMessage(telegramID, fmt.Sprintf("✏️ *Edit Confession #%d*\n\nKetik isi confession yang baru:\n_Ketik /cancel untuk membatalkan_", confessionID), nil)
+
+	case "delete":
+		kb := ConfirmKeyboard(fmt.Sprintf("myconf:confirm_delete:%d", confessionID), "myconf:keep")
+		b.answerCallback(callback.ID, "")
+		b.sendMessageHTML(telegramID, fmt.Sprintf(`🗑️ <b>Hapus Confession #%d?</b>
+
+Confession akan hilang dari feed dan tidak bisa dibalas atau direaksi lagi.`, confessionID), &kb)
+
+	case "confirm_delete":
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_myconf_confirm")
+
+		if err := b.confession.DeleteConfession(ctx, confessionID, telegramID); err != nil {
+			b.sendMessage(telegramID, fmt.Sprintf("⚠️ %s", err.Error()), nil)
+			return
+		}
+		b.sendMessage(telegramID, fmt.Sprintf("🗑️ *Confession #%d telah dihapus.*", confessionID), nil)
+	}
+}
+
+func (b *Bot) handleContentReportCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	parts := strings.Split(data, ":")
+
+	switch parts[0] {
+	case "cancel":
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_creport_cancel")
+		b.answerCallback(callback.ID, "👌 Laporan dibatalkan.")
+
+	case "send":
+		if len(parts) < 4 || !models.IsValidContentType(parts[1]) {
+			return
+		}
+		contentType := models.ContentType(parts[1])
+		contentID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return
+		}
+		reason := contentReportReasonLabel(parts[3])
+		if reason == "" {
+			return
+		}
+
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_creport_reason")
+
+		hidden, err := b.contentReport.ReportContent(ctx, telegramID, contentType, contentID, reason)
+		if err != nil {
+			b.answerCallback(callback.ID, "")
+			b.sendMessage(telegramID, fmt.Sprintf("⚠️ %s", err.Error()), nil)
+			return
+		}
+
+		metrics.ReportsTotal.Inc()
+		b.answerCallback(callback.ID, "✅ Laporan terkirim.")
+		b.sendMessageHTML(telegramID, "✅ <b>Laporan Terkirim!</b>\n\nTerima kasih, laporanmu membantu menjaga komunitas tetap aman.", nil)
+
+		if hidden {
+			b.notifyAdminContentHidden(ctx, contentType, contentID)
+		}
+
+	default:
+		if len(parts) < 2 || !models.IsValidContentType(parts[0]) {
+			return
+		}
+		contentType := models.ContentType(parts[0])
+		contentID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return
+		}
+
+		kb := ReportReasonKeyboard(contentType, contentID)
+		b.answerCallback(callback.ID, "")
+		b.sendMessageHTML(telegramID, fmt.Sprintf("🚩 <b>Laporkan %s #%d</b>\n\nPilih alasan laporan:",
+			models.ContentTypeLabel(contentType), contentID), &kb)
+	}
+}
+
+func (b *Bot) handleModerationQueueCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	if !b.can(ctx, telegramID, models.PermReviewContent) {
+		b.answerCallback(callback.ID, "")
+		return
+	}
+
+	parts := strings.SplitN(data, ":", 2)
+	if len(parts) < 2 {
+		return
+	}
+
+	flagID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return
+	}
+
+	var restore bool
+	switch parts[0] {
+	case "restore":
+		restore = true
+	case "remove":
+		restore = false
+	default:
+		return
+	}
+
+	flag, err := b.contentReport.ResolveFlag(ctx, flagID, telegramID, restore)
+	if err != nil {
+		b.answerCallback(callback.ID, fmt.Sprintf("⚠️ %s", err.Error()))
+		return
+	}
+
+	result := "🗑️ Dihapus permanen"
+	if restore {
+		result = "✅ Dipulihkan"
+	}
+	b.recordAudit(ctx, telegramID, models.AuditContentReview, flag.AuthorID, map[string]any{
+		"flag_id":      flagID,
+		"content_type": flag.ContentType,
+		"content_id":   flag.ContentID,
+		"restored":     restore,
+	})
+
+	b.answerCallback(callback.ID, result)
+	edit := tgbotapi.NewEditMessageText(telegramID, callback.Message.MessageID,
+		fmt.Sprintf("%s\n\nKeputusan: %s", callback.Message.Text, result))
+	b.sendAPI("edit_modq_resolved", edit)
+
+	if !restore && flag.AuthorID != 0 {
+		b.sendMessageHTML(flag.AuthorID, fmt.Sprintf("🚫 <b>%s #%d kamu telah dihapus</b> oleh admin karena melanggar aturan komunitas.",
+			models.ContentTypeLabel(flag.ContentType), flag.ContentID), nil)
+	}
+}
+
+func (b *Bot) handlePendingConfessionCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	if !b.can(ctx, telegramID, models.PermReviewContent) {
+		b.answerCallback(callback.ID, "")
+		return
+	}
+
+	parts := strings.SplitN(data, ":", 2)
+	if len(parts) < 2 || (parts[0] != "approve" && parts[0] != "reject") {
+		return
+	}
+
+	confessionID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return
+	}
+
+	approve := parts[0] == "approve"
+	confession, err := b.confession.ReviewConfession(ctx, confessionID, approve)
+	if err != nil {
+		b.answerCallback(callback.ID, fmt.Sprintf("⚠️ %s", err.Error()))
+		return
+	}
+
+	result := "❌ Ditolak"
+	if approve {
+		result = "✅ Disetujui"
+	}
+	b.recordAudit(ctx, telegramID, models.AuditConfessionReview, confession.AuthorID, map[string]any{
+		"confession_id": confession.ID,
+		"approved":      approve,
+	})
+	b.answerCallback(callback.ID, result)
+
+	edit := tgbotapi.NewEditMessageCaption(telegramID, callback.Message.MessageID,
+		fmt.Sprintf("%s\n\nKeputusan: %s", callback.Message.Caption, result))
+	b.sendAPI("edit_pending_confession", edit)
+
+	if approve {
+		metrics.ConfessionsTotal.Inc()
+		b.checkAchievements(ctx, confession.AuthorID)
+		b.processReward(ctx, confession.AuthorID, "confession_created")
+		b.sendMessage(confession.AuthorID, fmt.Sprintf("✅ *Confession foto #%d disetujui!*\n\nSekarang bisa dilihat semua pengguna melalui /confessions.", confession.ID), nil)
+		return
+	}
+
+	b.sendMessage(confession.AuthorID, fmt.Sprintf("❌ *Confession foto #%d ditolak admin* karena tidak sesuai aturan komunitas.", confession.ID), nil)
+}
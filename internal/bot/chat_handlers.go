@@ -10,6 +10,7 @@ import (
 
 	"github.com/pnj-anonymous-bot/internal/metrics"
 	"github.com/pnj-anonymous-bot/internal/models"
+	"github.com/pnj-anonymous-bot/internal/service"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -57,8 +58,6 @@ func (b *Bot) startSearch(ctx context.Context, telegramID int64, preferredDept,
 		preferredDept = ""
 	}
 
-	logIfErr("leave_room_before_search", b.room.LeaveRoom(ctx, telegramID))
-
 	matchID, err := b.chat.SearchPartner(ctx, telegramID, preferredDept, preferredGender, preferredYear)
 	if err != nil {
 		b.sendMessage(telegramID, fmt.Sprintf("⚠️ %s", err.Error()), nil)
@@ -124,6 +123,25 @@ Semua pesan akan diteruskan secara <b>anonim</b>.
 	b.sendMessageHTML(user2ID, msg2, &kb)
 }
 
+// notifyAuthorChatStarted opens an author chat without the profile card
+// notifyMatchFound shows, so neither side learns who the other is.
+func (b *Bot) notifyAuthorChatStarted(req *models.ChatRequest) {
+	kb := ChatActionKeyboard()
+	text := `<b>💬 Chat Anonim Dimulai</b>
+
+━━━━━━━━━━━━━━━━━━━
+%s
+━━━━━━━━━━━━━━━━━━━
+
+Semua pesan akan diteruskan secara <b>anonim</b>.
+Identitas kalian berdua tetap dirahasiakan.
+
+<i>Ketik pesan untuk memulai...</i>`
+
+	b.sendMessageHTML(req.RequesterID, fmt.Sprintf(text, fmt.Sprintf("Penulis confession #%d menerima ajakanmu.", req.ConfessionID)), &kb)
+	b.sendMessageHTML(req.AuthorID, fmt.Sprintf(text, fmt.Sprintf("Kamu terhubung dengan pembaca confession #%d.", req.ConfessionID)), &kb)
+}
+
 func (b *Bot) handleNext(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
 
@@ -140,12 +158,10 @@ func (b *Bot) handleNext(ctx context.Context, msg *tgbotapi.Message) {
 		b.sendMessageHTML(telegramID, fmt.Sprintf(`⚠️ <b>Kamu sedang berada di %s</b>
 
 Perintah /next hanya digunakan untuk mencari partner Private Chat. 
-Apakah kamu ingin keluar dari Circle dan mencari partner baru?`, roomName), &kb)
+Apakah kamu ingin berhenti bicara di Circle dan mencari partner baru? Kamu tetap menjadi anggota circle.`, roomName), &kb)
 		return
 	}
 
-	logIfErr("leave_room_before_next", b.room.LeaveRoom(ctx, telegramID))
-
 	partnerID, err := b.chat.NextPartner(ctx, telegramID)
 	if err != nil {
 		b.sendMessage(telegramID, fmt.Sprintf("⚠️ %s", err.Error()), nil)
@@ -225,7 +241,7 @@ func (b *Bot) handleChatMessage(ctx context.Context, msg *tgbotapi.Message) {
 		}
 
 	case msg.Sticker != nil, msg.Photo != nil, msg.Animation != nil:
-		if safe, reason := b.isSafeMedia(ctx, msg); !safe {
+		if safe, reason, _ := b.isSafeMedia(ctx, msg); !safe {
 			b.sendMessage(telegramID, "🚫 *Konten diblokir:* "+reason, nil)
 			return
 		}
@@ -317,3 +333,86 @@ func (b *Bot) deleteMessageAfterDelay(chatID int64, messageID int, delay time.Du
 	time.Sleep(delay)
 	b.deleteMessage(chatID, messageID, "delete_after_delay")
 }
+
+func (b *Bot) handleChatRequests(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	var allow bool
+	switch strings.ToLower(strings.TrimSpace(msg.CommandArguments())) {
+	case "on":
+		allow = true
+	case "off":
+		allow = false
+	default:
+		b.sendMessage(telegramID, "💬 *Ajakan Chat dari Pembaca*\n\nGunakan `/chatrequests on` untuk menerima atau `/chatrequests off` untuk menolak semua ajakan chat dari pembaca confession kamu.", nil)
+		return
+	}
+
+	if err := b.chat.SetAllowChatRequests(ctx, telegramID, allow); err != nil {
+		b.sendMessage(telegramID, "❌ Gagal menyimpan pengaturan.", nil)
+		return
+	}
+
+	if allow {
+		b.sendMessage(telegramID, "✅ Kamu sekarang *menerima* ajakan chat dari pembaca confession kamu.", nil)
+		return
+	}
+	b.sendMessage(telegramID, "🔕 Kamu *tidak lagi menerima* ajakan chat dari pembaca confession kamu.", nil)
+}
+
+func (b *Bot) handleAuthorChatCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	parts := strings.SplitN(data, ":", 2)
+	if len(parts) < 2 {
+		return
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return
+	}
+
+	switch parts[0] {
+	case "request":
+		req, err := b.chat.RequestAuthorChat(ctx, telegramID, id)
+		if err != nil {
+			b.answerCallback(callback.ID, "")
+			b.sendMessage(telegramID, fmt.Sprintf("⚠️ %s", err.Error()), nil)
+			return
+		}
+
+		kb := AuthorChatRequestKeyboard(req.ID)
+		b.sendMessageHTML(req.AuthorID, fmt.Sprintf(`💬 <b>Ajakan Chat Anonim</b>
+
+Seorang pembaca ingin ngobrol denganmu tentang <b>confession #%d</b>.
+Identitas kalian berdua tetap dirahasiakan.
+
+<i>Ajakan berlaku %d menit. Matikan ajakan chat dengan /chatrequests off</i>`,
+			req.ConfessionID, int(service.ChatRequestTTL.Minutes())), &kb)
+
+		b.answerCallback(callback.ID, "✅ Ajakan terkirim.")
+		b.sendMessage(telegramID, "✅ *Ajakan chat terkirim!*\n\nKamu akan diberi notifikasi jika penulis menerima ajakanmu.", nil)
+
+	case "accept":
+		req, err := b.chat.AcceptChatRequest(ctx, telegramID, id)
+		if err != nil {
+			b.answerCallback(callback.ID, fmt.Sprintf("⚠️ %s", err.Error()))
+			return
+		}
+
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_authorchat_request")
+		b.answerCallback(callback.ID, "✅ Ajakan diterima.")
+
+		metrics.ChatMatchesTotal.Inc()
+		b.notifyAuthorChatStarted(req)
+
+	case "decline":
+		req, err := b.chat.DeclineChatRequest(ctx, telegramID, id)
+		if err != nil {
+			b.answerCallback(callback.ID, fmt.Sprintf("⚠️ %s", err.Error()))
+			return
+		}
+
+		b.deleteMessage(telegramID, callback.Message.MessageID, "delete_authorchat_request")
+		b.answerCallback(callback.ID, "👌 Ajakan ditolak.")
+		b.sendMessage(req.RequesterID, fmt.Sprintf("😔 Penulis confession #%d belum bisa ngobrol saat ini.", req.ConfessionID), nil)
+	}
+}
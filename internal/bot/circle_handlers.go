@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"html"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/pnj-anonymous-bot/internal/logger"
 	"github.com/pnj-anonymous-bot/internal/metrics"
 	"github.com/pnj-anonymous-bot/internal/models"
 	"github.com/pnj-anonymous-bot/internal/validation"
+	"go.uber.org/zap"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -15,7 +20,7 @@ import (
 func (b *Bot) handleCircles(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
 
-	rooms, err := b.room.GetActiveRooms(ctx)
+	rooms, err := b.room.GetVisibleRooms(ctx, telegramID)
 	if err != nil {
 		b.sendMessage(telegramID, "❌ Gagal mengambil daftar circle.", nil)
 		return
@@ -32,7 +37,49 @@ Gabung ke circle topik tertentu untuk ngobrol bareng mahasiswa lainnya secara an
 	b.sendMessageHTML(telegramID, text, &kb)
 }
 
-func (b *Bot) handleLeaveCircle(ctx context.Context, msg *tgbotapi.Message) {
+func (b *Bot) sendCircleWelcome(ctx context.Context, telegramID int64, room *models.Room) {
+	kb := LeaveCircleKeyboard()
+	text := fmt.Sprintf(`🎉 <b>Berhasil Terhubung ke Circle %s</b>
+
+━━━━━━━━━━━━━━━━━━━
+Sekarang semua pesan yang kamu ketik akan dikirim ke semua anggota circle ini secara anonim.
+🎭 Alias kamu di circle ini: <b>%s</b>
+
+💡 Gunakan /my_circles untuk pindah ke circle lain, atau /leave_circle dan tombol di bawah untuk keluar.
+
+<i>Mulai ngobrol sekarang...</i>`, room.Name, html.EscapeString(b.circleAlias(ctx, room.ID, telegramID)))
+
+	b.sendMessageHTML(telegramID, text, &kb)
+	b.showCirclePin(ctx, telegramID, room)
+	b.sendCircleHistory(ctx, telegramID, room, 0, b.cfg.CircleHistoryOnJoin)
+}
+
+// joinCircleByInvite handles /start circle_<code> deep links.
+func (b *Bot) joinCircleByInvite(ctx context.Context, telegramID int64, code string) {
+	if banned, _ := b.auth.IsBanned(ctx, telegramID); banned {
+		b.sendMessage(telegramID, "🚫 *Akun kamu telah di-banned.*", nil)
+		return
+	}
+
+	state, _, _ := b.db.GetUserState(ctx, telegramID)
+	if state == models.StateInChat {
+		kb := ConfirmKeyboard("circle:confirm_invite:"+code, "circle:stay_chat")
+		b.sendMessageHTML(telegramID, `⚠️ <b>Kamu sedang dalam Private Chat aktif</b>
+
+Bergabung ke circle akan mengakhiri chat kamu saat ini secara otomatis. Apakah kamu yakin?`, &kb)
+		return
+	}
+
+	room, err := b.room.JoinRoomByInvite(ctx, telegramID, code)
+	if err != nil {
+		b.sendMessage(telegramID, "❌ "+err.Error(), nil)
+		return
+	}
+	metrics.CircleJoinsTotal.Inc()
+	b.sendCircleWelcome(ctx, telegramID, room)
+}
+
+func (b *Bot) handleCircleInvite(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
 
 	room, err := b.room.GetUserRoom(ctx, telegramID)
@@ -41,52 +88,588 @@ func (b *Bot) handleLeaveCircle(ctx context.Context, msg *tgbotapi.Message) {
 		return
 	}
 
-	err = b.room.LeaveRoom(ctx, telegramID)
+	role := b.circleRole(ctx, room.ID, telegramID)
+	if role == models.RoomRoleMember {
+		b.sendMessage(telegramID, "🚫 Hanya pemilik dan moderator circle yang bisa membagikan undangan.", nil)
+		return
+	}
+
+	action := strings.ToLower(strings.TrimSpace(msg.CommandArguments()))
+	if (action == "rotate" || action == "revoke") && role != models.RoomRoleOwner {
+		b.sendMessage(telegramID, "🚫 Hanya pemilik circle yang bisa mengganti atau mencabut undangan.", nil)
+		return
+	}
+
+	var code string
+	switch action {
+	case "":
+		code, err = b.room.InviteCode(ctx, room.ID)
+	case "rotate":
+		code, err = b.room.RotateInvite(ctx, room.ID)
+	case "revoke":
+		if err := b.room.RevokeInvite(ctx, room.ID); err != nil {
+			b.sendMessage(telegramID, "❌ Gagal mencabut undangan.", nil)
+			return
+		}
+		b.sendMessage(telegramID, "✅ Semua kode undangan circle ini sudah dicabut. Gunakan `/circle_invite` untuk membuat kode baru.", nil)
+		return
+	default:
+		b.sendMessage(telegramID, "⚠️ Format: `/circle_invite`, `/circle_invite rotate` atau `/circle_invite revoke`", nil)
+		return
+	}
 	if err != nil {
+		b.sendMessage(telegramID, "❌ Gagal membuat kode undangan.", nil)
+		return
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=circle_%s", b.api.Self.UserName, code)
+	b.sendMessageHTML(telegramID, fmt.Sprintf(`🔗 <b>Undangan Circle %s</b>
+
+Bagikan link ini untuk mengajak orang bergabung:
+%s
+
+Kode: <code>%s</code>
+
+<i>Pemilik bisa mengganti kode dengan /circle_invite rotate atau mencabutnya dengan /circle_invite revoke.</i>`,
+		html.EscapeString(room.Name), html.EscapeString(link), code), nil)
+}
+
+func (b *Bot) handleCircleVisibility(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	if mode := strings.ToLower(strings.TrimSpace(msg.CommandArguments())); mode != "" {
+		b.setCircleVisibility(ctx, telegramID, models.RoomVisibility(mode))
+		return
+	}
+
+	room, err := b.room.GetUserRoom(ctx, telegramID)
+	if err != nil || room == nil {
+		b.sendMessage(telegramID, "⚠️ Kamu tidak sedang berada di circle mana pun.", nil)
+		return
+	}
+
+	kb := CircleVisibilityKeyboard()
+	b.sendMessageHTML(telegramID, fmt.Sprintf(`👁️ <b>Visibilitas Circle %s</b>
+
+Saat ini: <b>%s</b>
+
+🌐 <b>Publik</b> — tampil di /circles untuk semua orang
+🏛️ <b>Jurusan</b> — hanya untuk mahasiswa jurusan kamu
+🔒 <b>Undangan</b> — hanya bisa dimasuki lewat /circle_invite`,
+		html.EscapeString(room.Name), visibilityLabel(room)), &kb)
+}
+
+func (b *Bot) setCircleVisibility(ctx context.Context, telegramID int64, visibility models.RoomVisibility) {
+	room, err := b.room.GetUserRoom(ctx, telegramID)
+	if err != nil || room == nil {
+		b.sendMessage(telegramID, "⚠️ Kamu tidak sedang berada di circle mana pun.", nil)
+		return
+	}
+	if b.circleRole(ctx, room.ID, telegramID) != models.RoomRoleOwner {
+		b.sendMessage(telegramID, "🚫 Hanya pemilik circle yang bisa mengubah visibilitas.", nil)
+		return
+	}
+
+	department := ""
+	if user, _ := b.db.GetUser(ctx, telegramID); user != nil {
+		department = string(user.Department)
+	}
+	if err := b.room.SetVisibility(ctx, room.ID, visibility, department); err != nil {
+		b.sendMessage(telegramID, "❌ "+err.Error(), nil)
+		return
+	}
+
+	room.Visibility = visibility
+	room.Department = department
+	b.sendMessageHTML(telegramID, fmt.Sprintf("✅ Visibilitas circle <b>%s</b> sekarang: <b>%s</b>", html.EscapeString(room.Name), visibilityLabel(room)), nil)
+}
+
+func visibilityLabel(room *models.Room) string {
+	switch room.Visibility {
+	case models.RoomDepartmentOnly:
+		return "🏛️ Khusus " + html.EscapeString(room.Department)
+	case models.RoomInviteOnly:
+		return "🔒 Undangan"
+	default:
+		return "🌐 Publik"
+	}
+}
+
+func (b *Bot) handleLeaveCircle(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	room, err := b.room.GetUserRoom(ctx, telegramID)
+	if err != nil || room == nil {
+		b.sendMessage(telegramID, "⚠️ Kamu tidak sedang berada di circle mana pun.", nil)
+		return
+	}
+	b.leaveCircle(ctx, telegramID, room)
+}
+
+func (b *Bot) leaveCircle(ctx context.Context, telegramID int64, room *models.Room) {
+	if err := b.room.LeaveRoom(ctx, telegramID, room.ID); err != nil {
 		b.sendMessage(telegramID, "❌ Gagal keluar dari circle.", nil)
 		return
 	}
 
-	b.sendMessageHTML(telegramID, fmt.Sprintf("👋 <b>Kamu telah keluar dari circle %s</b>", room.Name), nil)
+	text := fmt.Sprintf("👋 <b>Kamu telah keluar dari circle %s</b>", html.EscapeString(room.Name))
+	if rooms, _ := b.room.GetUserRooms(ctx, telegramID); len(rooms) > 0 {
+		text += fmt.Sprintf("\n\nKamu masih anggota %d circle lain. Gunakan /my_circles untuk memilih circle tempat kamu bicara.", len(rooms))
+	}
+	b.sendMessageHTML(telegramID, text, nil)
 	b.showMainMenu(ctx, telegramID, nil)
 }
 
+func (b *Bot) handleMyCircles(ctx context.Context, msg *tgbotapi.Message) {
+	b.showMyCircles(ctx, msg.From.ID)
+}
+
+func (b *Bot) showMyCircles(ctx context.Context, telegramID int64) {
+	rooms, err := b.room.GetUserRooms(ctx, telegramID)
+	if err != nil {
+		b.sendMessage(telegramID, "❌ Gagal mengambil daftar circle kamu.", nil)
+		return
+	}
+	if len(rooms) == 0 {
+		b.sendMessage(telegramID, "👥 Kamu belum bergabung di circle mana pun. Gunakan /circles untuk bergabung.", nil)
+		return
+	}
+
+	kb := MyCirclesKeyboard(rooms)
+	b.sendMessageHTML(telegramID, `👥 <b>Circle Kamu</b>
+
+━━━━━━━━━━━━━━━━━━━
+Kamu menerima pesan dari semua circle yang kamu ikuti, ditandai dengan nama circle-nya. Pesan yang kamu ketik dikirim ke circle bertanda 🗣️.
+
+• Ketuk nama circle untuk bicara di sana
+• 🔔/🔕 untuk menyalakan atau membisukan pesan circle
+• 🚪 untuk keluar dari circle`, &kb)
+}
+
+func (b *Bot) switchCircle(ctx context.Context, telegramID, roomID int64, callback *tgbotapi.CallbackQuery) {
+	if state, _, _ := b.db.GetUserState(ctx, telegramID); state == models.StateInChat {
+		b.answerCallback(callback.ID, "⚠️ Akhiri private chat dulu dengan /stop.")
+		return
+	}
+
+	room, err := b.room.SwitchRoom(ctx, telegramID, roomID)
+	if err != nil {
+		b.answerCallback(callback.ID, "❌ "+err.Error())
+		return
+	}
+
+	b.answerCallback(callback.ID, "🗣️ Bicara di "+room.Name)
+	b.deleteMessage(telegramID, callback.Message.MessageID, "delete_my_circles")
+	b.sendMessageHTML(telegramID, fmt.Sprintf("🗣️ Pesan kamu sekarang dikirim ke circle <b>%s</b> sebagai <b>%s</b>.",
+		html.EscapeString(room.Name), html.EscapeString(b.circleAlias(ctx, room.ID, telegramID))), nil)
+}
+
+func (b *Bot) toggleCircleMute(ctx context.Context, telegramID, roomID int64, callback *tgbotapi.CallbackQuery) {
+	rooms, err := b.room.GetUserRooms(ctx, telegramID)
+	if err != nil {
+		b.answerCallback(callback.ID, "❌ Gagal mengubah notifikasi circle.")
+		return
+	}
+
+	for _, room := range rooms {
+		if room.ID != roomID {
+			continue
+		}
+		if err := b.room.SetRoomMuted(ctx, telegramID, roomID, !room.Muted); err != nil {
+			b.answerCallback(callback.ID, "❌ Gagal mengubah notifikasi circle.")
+			return
+		}
+		room.Muted = !room.Muted
+		if room.Muted {
+			b.answerCallback(callback.ID, "🔕 Pesan dari "+room.Name+" dibisukan.")
+		} else {
+			b.answerCallback(callback.ID, "🔔 Pesan dari "+room.Name+" dinyalakan.")
+		}
+
+		kb := MyCirclesKeyboard(rooms)
+		b.sendAPI("edit_my_circles", tgbotapi.NewEditMessageReplyMarkup(telegramID, callback.Message.MessageID, kb))
+		return
+	}
+	b.answerCallback(callback.ID, "⚠️ Kamu bukan anggota circle ini.")
+}
+
+func (b *Bot) leaveCircleByID(ctx context.Context, telegramID, roomID int64, callback *tgbotapi.CallbackQuery) {
+	room, err := b.db.GetRoomByID(ctx, roomID)
+	if err != nil || room == nil {
+		b.answerCallback(callback.ID, "⚠️ Circle tidak ditemukan.")
+		return
+	}
+
+	b.deleteMessage(telegramID, callback.Message.MessageID, "delete_my_circles")
+	b.leaveCircle(ctx, telegramID, room)
+}
+
 func (b *Bot) handleCircleMessage(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
 
-	members, roomName, err := b.room.GetRoomMembers(ctx, telegramID)
-	if err != nil {
+	room, err := b.room.GetUserRoom(ctx, telegramID)
+	if err != nil || room == nil {
 		logIfErr("set_state_none_circle_err", b.db.SetUserState(ctx, telegramID, models.StateNone, ""))
 		b.sendMessage(telegramID, "⚠️ Kamu tidak berada di circle aktif. Gunakan /circles untuk bergabung.", nil)
 		return
 	}
 
+	if !room.IsActive {
+		b.sendMessage(telegramID, "📦 Circle ini sudah diarsipkan karena tidak aktif. Gunakan /my_circles untuk pindah ke circle lain.", nil)
+		return
+	}
+
+	mutedUntil, err := b.room.MutedUntil(ctx, room.ID, telegramID)
+	logIfErr("get_circle_mute", err)
+	if mutedUntil != nil {
+		b.sendMessage(telegramID, fmt.Sprintf("🔇 Kamu sedang di-mute di circle ini sampai *%s*.", mutedUntil.Format("02 Jan 15:04")), nil)
+		return
+	}
+
+	fingerprint := msg.Text
+	if fingerprint == "" {
+		_, fingerprint = storedMedia(msg)
+	}
+	exempt := b.circleRole(ctx, room.ID, telegramID) != models.RoomRoleMember
+	if err := b.room.CheckFlood(ctx, room, telegramID, fingerprint, exempt); err != nil {
+		b.sendMessage(telegramID, "⏳ "+err.Error()+".", nil)
+		return
+	}
+
+	members, err := b.db.GetRoomRecipients(ctx, room.ID)
+	if err != nil {
+		b.sendMessage(telegramID, "❌ Gagal mengirim pesan ke circle.", nil)
+		return
+	}
+
+	alias := b.circleAlias(ctx, room.ID, telegramID)
+
 	user, _ := b.db.GetUser(ctx, telegramID)
-	senderInfo := "Anonymous"
+	senderInfo := alias
 	if user != nil {
-		senderInfo = fmt.Sprintf("%s %s", models.GenderEmoji(user.Gender), string(user.Department))
+		senderInfo = fmt.Sprintf("%s · %s %s", alias, models.GenderEmoji(user.Gender), string(user.Department))
+	}
+
+	text := msg.Text
+	if text != "" {
+		if b.profanity.IsBad(text) {
+			text = b.profanity.Clean(text)
+			b.sendMessage(telegramID, "⚠️ *Peringatan:* Pesan kamu mengandung kata-kata yang tidak pantas dan telah disensor.", nil)
+		}
+	} else if _, cfg := mediaConfig(telegramID, msg, ""); cfg == nil {
+		return
+	} else if safe, reason, _ := b.isSafeMedia(ctx, msg); !safe {
+		b.sendMessage(telegramID, "🚫 *Konten diblokir:* "+reason, nil)
+		return
 	}
 
+	entry := &models.RoomMessage{RoomID: room.ID, SenderID: telegramID, Alias: alias, Content: text}
+	if text == "" {
+		entry.MediaType, entry.FileID = storedMedia(msg)
+		entry.Content = msg.Caption
+	}
+	historyID, err := b.room.LogMessage(ctx, entry)
+	logIfErr("log_circle_message", err)
+
+	recipients := make([]int64, 0, len(members))
 	for _, memberID := range members {
-		if memberID == telegramID {
-			continue
+		if memberID != telegramID {
+			recipients = append(recipients, memberID)
 		}
+	}
 
-		if msg.Text != "" {
-			text := msg.Text
-			if b.profanity.IsBad(text) {
-				text = b.profanity.Clean(text)
-				b.sendMessage(telegramID, "⚠️ *Peringatan:* Pesan kamu mengandung kata-kata yang tidak pantas dan telah disensor.", nil)
+	header := fmt.Sprintf("👥 <b>[%s]</b>\n👤 %s: ", html.EscapeString(room.Name), html.EscapeString(senderInfo))
+	captionPrefix := fmt.Sprintf("👥 [%s] 👤 %s", room.Name, senderInfo)
+	job := circleDelivery{
+		roomID:     room.ID,
+		senderID:   telegramID,
+		messageID:  msg.MessageID,
+		historyID:  historyID,
+		recipients: recipients,
+		build: func(chatID int64) (string, tgbotapi.Chattable) {
+			if text == "" {
+				op, cfg := mediaConfig(chatID, msg, captionPrefix)
+				if historyID != 0 {
+					cfg = withReplyMarkup(cfg, ReportContentKeyboard(models.ContentCircleMsg, historyID))
+				}
+				return op, cfg
 			}
-			msgOut := fmt.Sprintf("👥 <b>[%s]</b>\n👤 %s: %s", roomName, senderInfo, html.EscapeString(text))
-			b.sendMessageHTML(memberID, msgOut, nil)
-		} else {
-			if safe, reason := b.isSafeMedia(ctx, msg); !safe {
-				b.sendMessage(telegramID, "🚫 *Konten diblokir:* "+reason, nil)
-				return
+			out := tgbotapi.NewMessage(chatID, header+html.EscapeString(text))
+			out.ParseMode = "HTML"
+			if historyID != 0 {
+				out.ReplyMarkup = ReportContentKeyboard(models.ContentCircleMsg, historyID)
 			}
-			b.forwardMedia(memberID, msg, fmt.Sprintf("👥 [%s] 👤 %s", roomName, senderInfo))
+			return "circle_message", out
+		},
+	}
+
+	if !b.enqueueCircleDelivery(job) {
+		b.sendMessage(telegramID, "⏳ Circle sedang ramai, pesan kamu belum terkirim. Coba kirim lagi sebentar lagi.", nil)
+	}
+}
+
+// circleRole returns the caller's role in the circle; staff allowed to
+// moderate circles act as at least a moderator of every circle.
+func (b *Bot) circleRole(ctx context.Context, roomID, telegramID int64) models.RoomRole {
+	role, err := b.room.GetRole(ctx, roomID, telegramID)
+	logIfErr("get_circle_role", err)
+	if role == models.RoomRoleMember && b.can(ctx, telegramID, models.PermModerateCircles) {
+		return models.RoomRoleModerator
+	}
+	return role
+}
+
+// circleOverride reports whether the caller only moderates the circle through
+// the staff override, without a circle role of their own.
+func (b *Bot) circleOverride(ctx context.Context, roomID, telegramID int64) bool {
+	role, err := b.room.GetRole(ctx, roomID, telegramID)
+	logIfErr("get_circle_role", err)
+	return role == models.RoomRoleMember
+}
+
+func (b *Bot) circleAlias(ctx context.Context, roomID, telegramID int64) string {
+	alias, err := b.room.MemberAlias(ctx, roomID, telegramID)
+	logIfErr("get_circle_alias", err)
+	if alias == "" {
+		return "Anonymous"
+	}
+	return alias
+}
+
+// circleAliasLabel names a member by their circle alias in moderation replies.
+func (b *Bot) circleAliasLabel(ctx context.Context, roomID, telegramID int64) string {
+	alias, err := b.room.MemberAlias(ctx, roomID, telegramID)
+	logIfErr("get_circle_alias", err)
+	if alias == "" {
+		return "Anggota tersebut"
+	}
+	return "<b>" + html.EscapeString(alias) + "</b>"
+}
+
+// circleModerationTarget resolves the circle and author of the circle message
+// that msg replies to and checks that the caller outranks them there.
+func (b *Bot) circleModerationTarget(ctx context.Context, msg *tgbotapi.Message) (*models.Room, int64, bool) {
+	telegramID := msg.From.ID
+
+	if msg.ReplyToMessage == nil {
+		b.sendMessage(telegramID, "↩️ Balas (reply) pesan circle dari anggota yang ingin kamu tindak dengan perintah ini.", nil)
+		return nil, 0, false
+	}
+
+	roomID, targetID, _ := b.room.ResolveDelivery(ctx, telegramID, msg.ReplyToMessage.MessageID)
+	room, err := b.db.GetRoomByID(ctx, roomID)
+	if targetID == 0 || err != nil || room == nil {
+		b.sendMessage(telegramID, "⚠️ Pesan tersebut bukan pesan circle atau sudah terlalu lama.", nil)
+		return nil, 0, false
+	}
+
+	role := b.circleRole(ctx, room.ID, telegramID)
+	if role == models.RoomRoleMember {
+		b.sendMessage(telegramID, "🚫 Hanya pemilik dan moderator circle yang bisa menggunakan perintah ini.", nil)
+		return nil, 0, false
+	}
+
+	if targetID == telegramID {
+		b.sendMessage(telegramID, "⚠️ Kamu tidak bisa menindak diri sendiri.", nil)
+		return nil, 0, false
+	}
+
+	targetRole, err := b.room.GetRole(ctx, room.ID, targetID)
+	logIfErr("get_circle_target_role", err)
+	if !role.Outranks(targetRole) {
+		b.sendMessage(telegramID, "🚫 Kamu tidak punya wewenang untuk menindak anggota ini.", nil)
+		return nil, 0, false
+	}
+
+	return room, targetID, true
+}
+
+func (b *Bot) handleCircleKick(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	room, targetID, ok := b.circleModerationTarget(ctx, msg)
+	if !ok {
+		return
+	}
+
+	label := b.circleAliasLabel(ctx, room.ID, targetID)
+	override := b.circleOverride(ctx, room.ID, telegramID)
+	if err := b.room.KickMember(ctx, room.ID, targetID, telegramID); err != nil {
+		b.sendMessage(telegramID, "❌ Gagal mengeluarkan anggota.", nil)
+		return
+	}
+	if override {
+		b.recordAudit(ctx, telegramID, models.AuditCircleKick, targetID, map[string]any{"room_id": room.ID, "slug": room.Slug})
+	}
+
+	b.sendMessageHTML(targetID, fmt.Sprintf("🚫 <b>Kamu telah dikeluarkan dari circle %s</b> oleh moderator.", html.EscapeString(room.Name)), nil)
+	b.sendMessageHTML(telegramID, fmt.Sprintf("✅ %s telah dikeluarkan dan tidak bisa bergabung lagi ke circle ini.", label), nil)
+}
+
+func (b *Bot) handleCircleMute(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	duration, ok := validation.ParsePollDuration(msg.CommandArguments())
+	if !ok {
+		b.sendMessage(telegramID, "⚠️ Format: balas pesan circle dengan `/circle_mute <durasi>`\nContoh: `/circle_mute 30m`, `/circle_mute 2h`, `/circle_mute 1d`", nil)
+		return
+	}
+
+	room, targetID, ok := b.circleModerationTarget(ctx, msg)
+	if !ok {
+		return
+	}
+
+	override := b.circleOverride(ctx, room.ID, telegramID)
+	until, err := b.room.MuteMember(ctx, room.ID, targetID, duration, telegramID)
+	if err != nil {
+		b.sendMessage(telegramID, "❌ Gagal me-mute anggota.", nil)
+		return
+	}
+	if override {
+		b.recordAudit(ctx, telegramID, models.AuditCircleMute, targetID, map[string]any{"room_id": room.ID, "slug": room.Slug, "until": until})
+	}
+
+	b.sendMessageHTML(targetID, fmt.Sprintf("🔇 <b>Kamu di-mute di circle %s</b> sampai %s.", html.EscapeString(room.Name), until.Format("02 Jan 15:04")), nil)
+	b.sendMessageHTML(telegramID, fmt.Sprintf("✅ %s di-mute sampai <b>%s</b>.", b.circleAliasLabel(ctx, room.ID, targetID), until.Format("02 Jan 15:04")), nil)
+}
+
+func (b *Bot) handleCircleSlowMode(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	room, err := b.room.GetUserRoom(ctx, telegramID)
+	if err != nil || room == nil {
+		b.sendMessage(telegramID, "⚠️ Kamu tidak sedang berada di circle mana pun.", nil)
+		return
+	}
+	if b.circleRole(ctx, room.ID, telegramID) == models.RoomRoleMember {
+		b.sendMessage(telegramID, "🚫 Hanya pemilik dan moderator circle yang bisa mengatur slow mode.", nil)
+		return
+	}
+
+	arg := strings.ToLower(strings.TrimSpace(msg.CommandArguments()))
+	if arg == "" {
+		status := "nonaktif"
+		if room.SlowMode > 0 {
+			status = fmt.Sprintf("1 pesan per %d detik", room.SlowMode)
 		}
+		b.sendMessage(telegramID, fmt.Sprintf("🐢 Slow mode circle *%s*: %s\n\nFormat: `/circle_slowmode <detik>` (contoh: `30`, `2m`) atau `/circle_slowmode off`", room.Name, status), nil)
+		return
+	}
+
+	seconds := 0
+	if arg != "off" {
+		if n, err := strconv.Atoi(arg); err == nil {
+			seconds = n
+		} else if d, err := time.ParseDuration(arg); err == nil {
+			seconds = int(d.Seconds())
+		} else {
+			b.sendMessage(telegramID, "⚠️ Format: `/circle_slowmode <detik>` (contoh: `30`, `2m`) atau `/circle_slowmode off`", nil)
+			return
+		}
+	}
+
+	if err := b.room.SetSlowMode(ctx, room.ID, seconds); err != nil {
+		b.sendMessage(telegramID, "❌ "+err.Error(), nil)
+		return
+	}
+
+	if seconds == 0 {
+		b.sendMessageHTML(telegramID, fmt.Sprintf("🐇 Slow mode circle <b>%s</b> dimatikan.", html.EscapeString(room.Name)), nil)
+		return
+	}
+	b.sendMessageHTML(telegramID, fmt.Sprintf("🐢 Slow mode circle <b>%s</b> aktif: setiap anggota bisa mengirim 1 pesan per %d detik.", html.EscapeString(room.Name), seconds), nil)
+}
+
+func (b *Bot) handleCirclePin(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	reply := msg.ReplyToMessage
+	if reply == nil {
+		b.sendMessage(telegramID, "↩️ Balas (reply) pesan yang ingin kamu sematkan dengan `/circle_pin`.", nil)
+		return
+	}
+
+	// Your own messages are pinned in the circle you speak in; anything else
+	// is pinned in the circle it was delivered from.
+	var room *models.Room
+	if reply.From != nil && reply.From.ID == telegramID {
+		room, _ = b.room.GetUserRoom(ctx, telegramID)
+	} else if roomID, _, _ := b.room.ResolveDelivery(ctx, telegramID, reply.MessageID); roomID != 0 {
+		room, _ = b.db.GetRoomByID(ctx, roomID)
+	}
+	if room == nil {
+		b.sendMessage(telegramID, "⚠️ Pesan tersebut bukan pesan circle atau sudah terlalu lama.", nil)
+		return
+	}
+
+	if b.circleRole(ctx, room.ID, telegramID) == models.RoomRoleMember {
+		b.sendMessage(telegramID, "🚫 Hanya pemilik dan moderator circle yang bisa menggunakan perintah ini.", nil)
+		return
+	}
+
+	content := reply.Text
+	if content == "" {
+		content = reply.Caption
+	}
+	if err := b.room.PinMessage(ctx, room.ID, content, telegramID); err != nil {
+		b.sendMessage(telegramID, fmt.Sprintf("❌ %s", err.Error()), nil)
+		return
+	}
+	if b.circleOverride(ctx, room.ID, telegramID) {
+		b.recordAudit(ctx, telegramID, models.AuditCirclePin, 0, map[string]any{"room_id": room.ID, "slug": room.Slug, "content": content})
+	}
+
+	recipients, err := b.db.GetRoomRecipients(ctx, room.ID)
+	if err != nil {
+		logIfErr("get_circle_recipients_for_pin", err)
+		return
+	}
+	if len(recipients) > 0 && !b.circleNotice(room.ID, recipients, formatCirclePin(room.Name, content)) {
+		b.sendMessage(telegramID, "⏳ Pesan disematkan, tapi circle sedang ramai sehingga pemberitahuan belum terkirim.", nil)
+	}
+}
+
+func (b *Bot) handleCircleMod(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	room, targetID, ok := b.circleModerationTarget(ctx, msg)
+	if !ok {
+		return
+	}
+	if b.circleRole(ctx, room.ID, telegramID) != models.RoomRoleOwner {
+		b.sendMessage(telegramID, "🚫 Hanya pemilik circle yang bisa mengangkat moderator.", nil)
+		return
+	}
+
+	role, err := b.room.ToggleModerator(ctx, room.ID, targetID)
+	if err != nil {
+		b.sendMessage(telegramID, fmt.Sprintf("❌ %s", err.Error()), nil)
+		return
+	}
+
+	if role == models.RoomRoleModerator {
+		b.sendMessageHTML(targetID, fmt.Sprintf("🛡️ Kamu diangkat menjadi <b>moderator</b> circle %s.\n\nBalas pesan anggota dengan /circle_kick, /circle_mute atau /circle_pin untuk menjaga circle tetap nyaman.", html.EscapeString(room.Name)), nil)
+		b.sendMessageHTML(telegramID, fmt.Sprintf("✅ %s sekarang menjadi moderator.", b.circleAliasLabel(ctx, room.ID, targetID)), nil)
+		return
+	}
+	b.sendMessageHTML(targetID, fmt.Sprintf("ℹ️ Peran moderator kamu di circle %s telah dicabut.", html.EscapeString(room.Name)), nil)
+	b.sendMessageHTML(telegramID, fmt.Sprintf("✅ %s bukan moderator lagi.", b.circleAliasLabel(ctx, room.ID, targetID)), nil)
+}
+
+func formatCirclePin(roomName, content string) string {
+	return fmt.Sprintf("📌 <b>Pesan Tersemat — %s</b>\n\n%s", html.EscapeString(roomName), html.EscapeString(content))
+}
+
+// showCirclePin sends the circle's pinned message to a member who just joined.
+func (b *Bot) showCirclePin(ctx context.Context, telegramID int64, room *models.Room) {
+	pin, err := b.room.GetPin(ctx, room.ID)
+	if err != nil {
+		logIfErr("get_circle_pin", err)
+		return
+	}
+	if pin != nil {
+		b.sendMessageHTML(telegramID, formatCirclePin(room.Name, pin.Content), nil)
 	}
 }
 
@@ -97,6 +680,10 @@ func (b *Bot) handleRoomNameInput(ctx context.Context, msg *tgbotapi.Message) {
 		b.sendMessage(telegramID, errMsg, nil)
 		return
 	}
+	if b.profanity.IsBad(name) {
+		b.sendMessage(telegramID, "⚠️ Nama circle mengandung kata-kata yang tidak pantas. Silakan pilih nama lain:", nil)
+		return
+	}
 
 	logIfErr("set_state_room_desc", b.db.SetUserState(ctx, telegramID, models.StateAwaitingRoomDesc, name))
 	b.sendMessage(telegramID, fmt.Sprintf("📝 *Nama Circle:* %s\n\nSekarang tulis *Deskripsi Singkat* untuk circle ini:", name), nil)
@@ -111,8 +698,19 @@ func (b *Bot) handleRoomDescInput(ctx context.Context, msg *tgbotapi.Message) {
 		b.sendMessage(telegramID, errMsg, nil)
 		return
 	}
+	if b.profanity.IsBad(desc) {
+		b.sendMessage(telegramID, "⚠️ Deskripsi circle mengandung kata-kata yang tidak pantas. Silakan tulis ulang:", nil)
+		return
+	}
+	if !b.can(ctx, telegramID, models.PermManageCircles) {
+		if err := b.room.CanCreateRoom(ctx, telegramID); err != nil {
+			logIfErr("set_state_none_room_limit", b.db.SetUserState(ctx, telegramID, models.StateNone, ""))
+			b.sendMessage(telegramID, "⚠️ "+err.Error(), nil)
+			return
+		}
+	}
 
-	room, err := b.room.CreateRoom(ctx, name, desc)
+	room, err := b.room.CreateRoom(ctx, telegramID, name, desc)
 	if err != nil {
 		b.sendMessage(telegramID, fmt.Sprintf("❌ %s", err.Error()), nil)
 		logIfErr("set_state_none_room_err", b.db.SetUserState(ctx, telegramID, models.StateNone, ""))
@@ -128,5 +726,162 @@ func (b *Bot) handleRoomDescInput(ctx context.Context, msg *tgbotapi.Message) {
 	metrics.CircleJoinsTotal.Inc()
 
 	kb := LeaveCircleKeyboard()
-	b.sendMessageHTML(telegramID, fmt.Sprintf("🎉 Kamu otomatis bergabung ke circle <b>%s</b>. Selamat ngobrol!", room.Name), &kb)
+	b.sendMessageHTML(telegramID, fmt.Sprintf("🎉 Kamu otomatis bergabung ke circle <b>%s</b> sebagai <b>%s</b>. Selamat ngobrol!", room.Name, html.EscapeString(b.circleAlias(ctx, room.ID, telegramID))), &kb)
+}
+
+const circleHistoryPageSize = 10
+
+// sendCircleHistory replays up to limit circle messages older than beforeID
+// (the newest when beforeID is 0) and offers a button to page further back.
+func (b *Bot) sendCircleHistory(ctx context.Context, telegramID int64, room *models.Room, beforeID int64, limit int) {
+	if limit <= 0 {
+		return
+	}
+
+	messages, err := b.room.History(ctx, room.ID, beforeID, limit)
+	if err != nil {
+		logIfErr("get_circle_history", err)
+		return
+	}
+	if len(messages) == 0 {
+		if beforeID > 0 {
+			b.sendMessage(telegramID, "🕘 Tidak ada pesan yang lebih lama.", nil)
+		}
+		return
+	}
+
+	var block strings.Builder
+	block.WriteString(fmt.Sprintf("🕘 <b>Riwayat Circle %s</b>\n", html.EscapeString(room.Name)))
+	flush := func() {
+		if block.Len() > 0 {
+			b.sendMessageHTML(telegramID, block.String(), nil)
+			block.Reset()
+		}
+	}
+
+	for _, m := range messages {
+		label := fmt.Sprintf("👤 <b>%s</b> <i>%s</i>", html.EscapeString(m.Alias), m.CreatedAt.Format("02 Jan 15:04"))
+		if m.MediaType == "" {
+			line := fmt.Sprintf("\n%s\n%s\n", label, html.EscapeString(m.Content))
+			if block.Len()+len(line) > 4000 {
+				flush()
+			}
+			block.WriteString(line)
+			continue
+		}
+
+		flush()
+		caption := fmt.Sprintf("🕘 %s · %s", m.Alias, m.CreatedAt.Format("02 Jan 15:04"))
+		if m.Content != "" {
+			caption += "\n\n" + m.Content
+		}
+		if cfg := storedMediaConfig(telegramID, m.MediaType, m.FileID, caption); cfg != nil {
+			b.sendAPI("send_circle_history_media", cfg)
+		}
+	}
+	flush()
+
+	if len(messages) == limit {
+		kb := CircleHistoryKeyboard(messages[0].ID)
+		b.sendMessage(telegramID, "⬆️ Masih ada pesan sebelumnya.", &kb)
+	}
+}
+
+func (b *Bot) handleCircleHistory(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	room, err := b.room.GetUserRoom(ctx, telegramID)
+	if err != nil || room == nil {
+		b.sendMessage(telegramID, "⚠️ Kamu tidak sedang berada di circle mana pun.", nil)
+		return
+	}
+
+	if messages, _ := b.room.History(ctx, room.ID, 0, 1); len(messages) == 0 {
+		b.sendMessage(telegramID, "🕘 Belum ada pesan di circle ini.", nil)
+		return
+	}
+	b.sendCircleHistory(ctx, telegramID, room, 0, circleHistoryPageSize)
+}
+
+func (b *Bot) handleCircleHistoryCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	beforeID, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		return
+	}
+
+	room, err := b.room.GetUserRoom(ctx, telegramID)
+	if err != nil || room == nil {
+		b.answerCallback(callback.ID, "⚠️ Kamu tidak sedang berada di circle mana pun.")
+		return
+	}
+
+	b.answerCallback(callback.ID, "")
+	b.deleteMessage(telegramID, callback.Message.MessageID, "delete_circle_history_more")
+	b.sendCircleHistory(ctx, telegramID, room, beforeID, circleHistoryPageSize)
+}
+
+func (b *Bot) handleCircleRemove(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	room, authorID, ok := b.circleModerationTarget(ctx, msg)
+	if !ok {
+		return
+	}
+
+	_, _, historyID := b.room.ResolveDelivery(ctx, telegramID, msg.ReplyToMessage.MessageID)
+	if historyID == 0 {
+		b.sendMessage(telegramID, "⚠️ Pesan tersebut tidak ada di riwayat circle.", nil)
+		return
+	}
+
+	if err := b.room.RemoveMessage(ctx, room.ID, historyID); err != nil {
+		b.sendMessage(telegramID, "❌ Gagal menghapus pesan.", nil)
+		return
+	}
+	if b.circleOverride(ctx, room.ID, telegramID) {
+		b.recordAudit(ctx, telegramID, models.AuditCircleRemove, authorID, map[string]any{"room_id": room.ID, "slug": room.Slug, "message_id": historyID})
+	}
+
+	b.deleteMessage(telegramID, msg.ReplyToMessage.MessageID, "delete_removed_circle_message")
+	b.sendMessage(telegramID, "🗑️ Pesan dihapus dari riwayat circle dan tidak akan ditampilkan ke anggota baru.", nil)
+}
+
+// startCircleArchiver archives inactive circles once at startup and then
+// daily, telling their members.
+func (b *Bot) startCircleArchiver(ctx context.Context) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		b.archiveInactiveCircles(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *Bot) archiveInactiveCircles(ctx context.Context) {
+	rooms, err := b.room.ArchiveInactiveRooms(ctx)
+	if err != nil {
+		logger.Error("⚠️ Circle archiver error", zap.Error(err))
+		return
+	}
+
+	for _, room := range rooms {
+		logger.Info("Archived inactive circle", zap.Int64("room_id", room.ID), zap.String("slug", room.Slug))
+
+		members, err := b.db.GetRoomMembers(ctx, room.ID)
+		if err != nil {
+			logIfErr("get_archived_circle_members", err)
+			continue
+		}
+		notice := fmt.Sprintf("📦 <b>Circle %s diarsipkan</b> karena tidak ada pesan selama %d hari.\n\nGunakan /my_circles untuk pindah atau keluar dari circle ini.",
+			html.EscapeString(room.Name), b.cfg.CircleInactiveDays)
+		if len(members) > 0 && !b.circleNotice(room.ID, members, notice) {
+			logger.Warn("Circle fan-out queue full, skipped archive notice", zap.Int64("room_id", room.ID))
+		}
+	}
 }
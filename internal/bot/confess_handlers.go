@@ -9,6 +9,7 @@ import (
 
 	"github.com/pnj-anonymous-bot/internal/metrics"
 	"github.com/pnj-anonymous-bot/internal/models"
+	"github.com/pnj-anonymous-bot/internal/service"
 	"github.com/pnj-anonymous-bot/internal/validation"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -24,7 +25,7 @@ func (b *Bot) handleConfess(ctx context.Context, msg *tgbotapi.Message) {
 ━━━━━━━━━━━━━━━━━━━
 Kirim confession anonim yang bisa dibaca semua pengguna.
 
-📝 Ketik confession kamu sekarang...
+📝 Ketik confession kamu sekarang, atau kirim *satu foto* dengan caption.
 Atau ketik /cancel untuk membatalkan.
 
 ⚠️ _Confession akan menampilkan jurusan kamu tapi TIDAK identitas kamu._`, nil)
@@ -33,8 +34,13 @@ Atau ketik /cancel untuk membatalkan.
 func (b *Bot) handleConfessionInput(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
 
+	if len(msg.Photo) > 0 {
+		b.handlePhotoConfessionInput(ctx, msg)
+		return
+	}
+
 	if msg.Text == "" {
-		b.sendMessage(telegramID, "⚠️ Confession harus berupa teks.", nil)
+		b.sendMessage(telegramID, "⚠️ Confession harus berupa teks atau satu foto dengan caption.", nil)
 		return
 	}
 
@@ -68,6 +74,130 @@ func (b *Bot) handleConfessionInput(ctx context.Context, msg *tgbotapi.Message)
 Confession kamu sekarang bisa dilihat semua pengguna melalui /confessions.`, confession.ID), nil)
 }
 
+func (b *Bot) handlePhotoConfessionInput(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	if msg.MediaGroupID != "" {
+		b.sendMessage(telegramID, "⚠️ Confession hanya bisa berisi *satu* foto. Kirim ulang satu foto dengan caption.", nil)
+		return
+	}
+
+	caption := validation.SanitizeText(msg.Caption)
+	if caption == "" {
+		b.sendMessage(telegramID, "⚠️ Foto confession wajib disertai caption.", nil)
+		return
+	}
+	if errMsg := validation.ValidateText(caption, validation.ConfessionLimits); errMsg != "" {
+		b.sendMessage(telegramID, errMsg, nil)
+		return
+	}
+
+	if b.profanity.IsBad(caption) {
+		caption = b.profanity.Clean(caption)
+		metrics.ProfanityFiltered.Inc()
+		b.sendMessage(telegramID, "⚠️ *Peringatan:* Caption kamu mengandung kata-kata yang tidak pantas dan telah disensor.", nil)
+	}
+
+	// Photos the moderation API could not check wait for an admin, just like
+	// when moderation is disabled.
+	needsApproval := !b.moderation.IsEnabled()
+	if !needsApproval {
+		safe, reason, err := b.isSafeMedia(ctx, msg)
+		if !safe {
+			b.sendMessage(telegramID, "🚫 *Foto ditolak:* "+reason, nil)
+			return
+		}
+		needsApproval = err != nil
+	}
+
+	photo := msg.Photo[len(msg.Photo)-1]
+	confession, err := b.confession.CreatePhotoConfession(ctx, telegramID, caption, photo.FileID, needsApproval)
+	if err != nil {
+		b.sendMessage(telegramID, fmt.Sprintf("⚠️ %s", err.Error()), nil)
+		return
+	}
+
+	logIfErr("set_state_none_after_photo_confess", b.db.SetUserState(ctx, telegramID, models.StateNone, ""))
+
+	if needsApproval {
+		b.notifyAdminPendingConfession(ctx, confession)
+		b.sendMessage(telegramID, fmt.Sprintf(`⏳ *Confession Menunggu Persetujuan*
+
+📷 Confession #%d berisi foto dan akan ditinjau admin sebelum tampil di feed.
+Kamu akan diberi tahu setelah ditinjau.`, confession.ID), nil)
+		return
+	}
+
+	metrics.ConfessionsTotal.Inc()
+	b.checkAchievements(ctx, telegramID)
+	b.processReward(ctx, telegramID, "confession_created")
+
+	b.sendMessage(telegramID, fmt.Sprintf(`✅ *Confession Terkirim!*
+
+📷 Confession foto #%d berhasil dikirim.
+Confession kamu sekarang bisa dilihat semua pengguna melalui /confessions.`, confession.ID), nil)
+}
+
+// sendConfessionPhotoCards sends a single card for the first photo
+// confession in the list; its arrows page through photo confessions by
+// editing the card in place.
+func (b *Bot) sendConfessionPhotoCards(ctx context.Context, chatID int64, confessions []*models.Confession) {
+	for _, c := range confessions {
+		if c.PhotoFileID == "" {
+			continue
+		}
+
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(c.PhotoFileID))
+		photo.Caption = confessionPhotoCaption(c)
+		photo.ParseMode = "HTML"
+		photo.ReplyMarkup = b.confessionPhotoKeyboard(ctx, c.ID)
+		b.sendAPI("send_confession_photo", photo)
+		return
+	}
+}
+
+func confessionPhotoCaption(c *models.Confession) string {
+	return fmt.Sprintf("📷 <b>#%d</b> | %s %s\n%s",
+		c.ID, models.DepartmentEmoji(models.Department(c.Department)),
+		html.EscapeString(c.Department), html.EscapeString(truncateText(c.Content, 900)))
+}
+
+func (b *Bot) confessionPhotoKeyboard(ctx context.Context, confessionID int64) tgbotapi.InlineKeyboardMarkup {
+	counts, _ := b.confession.GetReactionCounts(ctx, confessionID)
+	kb := ConfessionReactionKeyboard(confessionID, counts)
+	kb.InlineKeyboard = append(kb.InlineKeyboard, ConfessionPhotoNavRow(confessionID))
+	return kb
+}
+
+func (b *Bot) handleConfessionPhotoCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	direction, rawID, _ := strings.Cut(data, ":")
+	confessionID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || callback.Message == nil {
+		return
+	}
+
+	c, err := b.confession.GetAdjacentPhotoConfession(ctx, confessionID, direction == "older")
+	if err != nil || c == nil {
+		b.answerCallback(callback.ID, "📭 Tidak ada confession foto lagi.")
+		return
+	}
+
+	media := tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(c.PhotoFileID))
+	media.Caption = confessionPhotoCaption(c)
+	media.ParseMode = "HTML"
+	kb := b.confessionPhotoKeyboard(ctx, c.ID)
+	edit := tgbotapi.EditMessageMediaConfig{
+		BaseEdit: tgbotapi.BaseEdit{
+			ChatID:      telegramID,
+			MessageID:   callback.Message.MessageID,
+			ReplyMarkup: &kb,
+		},
+		Media: media,
+	}
+	b.answerCallback(callback.ID, "")
+	b.sendAPI("edit_confession_photo", edit)
+}
+
 func (b *Bot) handleConfessions(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
 
@@ -83,6 +213,16 @@ func (b *Bot) handleConfessions(ctx context.Context, msg *tgbotapi.Message) {
 	}
 
 	header := "<b>📋 Confession Terbaru</b>\n━━━━━━━━━━━━━━━━━━━\n\n"
+	header += b.formatConfessionList(ctx, confessions)
+	header += "\n<i>React: ketik</i> /react &lt;id&gt; &lt;emoji&gt;\n<i>Balas: ketik</i> /reply &lt;id&gt; &lt;pesan&gt;\n<i>Lihat: ketik</i> /view_replies &lt;id&gt;\n<i>Cari: ketik</i> /search_confess &lt;kata&gt; <i>atau</i> /tag &lt;nama&gt;"
+
+	kb := ConfessionFeedKeyboard(confessions)
+	b.sendMessageHTML(telegramID, header, &kb)
+	b.sendConfessionPhotoCards(ctx, telegramID, confessions)
+}
+
+func (b *Bot) formatConfessionList(ctx context.Context, confessions []*models.Confession) string {
+	var sb strings.Builder
 
 	for _, c := range confessions {
 		emoji := models.DepartmentEmoji(models.Department(c.Department))
@@ -99,18 +239,99 @@ func (b *Bot) handleConfessions(ctx context.Context, msg *tgbotapi.Message) {
 			replyStr = fmt.Sprintf("💬 %d Replies", replyCount)
 		}
 
-		text := fmt.Sprintf(`💬 <b>#%d</b> | %s %s
+		editedStr := ""
+		if c.EditedAt != nil {
+			editedStr = " <i>(diedit)</i>"
+		}
+		if c.PhotoFileID != "" {
+			editedStr += " 📷"
+		}
+
+		sb.WriteString(fmt.Sprintf(`💬 <b>#%d</b> | %s %s%s
 %s
 %s %s
 ─────────────────
-`, c.ID, emoji, html.EscapeString(c.Department), html.EscapeString(c.Content), reactionStr, replyStr)
+`, c.ID, emoji, html.EscapeString(c.Department), editedStr, html.EscapeString(c.Content), reactionStr, replyStr))
+	}
+
+	return sb.String()
+}
+
+func (b *Bot) handleSearchConfess(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	query := validation.SanitizeText(msg.CommandArguments())
+
+	if query == "" {
+		b.sendMessage(telegramID, "💡 Cara mencari: `/search_confess <kata kunci>`\nContoh: `/search_confess dosen killer`", nil)
+		return
+	}
+	if errMsg := validation.ValidateText(query, validation.SearchQueryLimits); errMsg != "" {
+		b.sendMessage(telegramID, errMsg, nil)
+		return
+	}
+
+	confessions, err := b.confession.SearchConfessions(ctx, query, 10)
+	if err != nil {
+		b.sendMessage(telegramID, "❌ Gagal mencari confession.", nil)
+		return
+	}
+
+	if len(confessions) == 0 {
+		b.sendMessageHTML(telegramID, fmt.Sprintf("🔎 Tidak ada confession yang cocok dengan <b>%s</b>.", html.EscapeString(query)), nil)
+		return
+	}
+
+	text := fmt.Sprintf("<b>🔎 Hasil Pencarian: %s</b>\n━━━━━━━━━━━━━━━━━━━\n\n", html.EscapeString(query))
+	text += b.formatConfessionList(ctx, confessions)
+
+	kb := ConfessionFeedKeyboard(confessions)
+	b.sendMessageHTML(telegramID, text, &kb)
+	b.sendConfessionPhotoCards(ctx, telegramID, confessions)
+}
+
+func (b *Bot) handleTag(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+	args := strings.TrimSpace(msg.CommandArguments())
 
-		header += text
+	if args == "" {
+		tags, err := b.confession.GetPopularTags(ctx, 15)
+		if err != nil {
+			b.sendMessage(telegramID, "❌ Gagal mengambil daftar tag.", nil)
+			return
+		}
+		if len(tags) == 0 {
+			b.sendMessage(telegramID, "🏷️ Belum ada hashtag. Tambahkan `#tag` di confession kamu!", nil)
+			return
+		}
+
+		text := "<b>🏷️ Hashtag Populer (30 hari)</b>\n━━━━━━━━━━━━━━━━━━━\n\n"
+		for _, t := range tags {
+			text += fmt.Sprintf("#%s — %d confession\n", html.EscapeString(t.Tag), t.Total)
+		}
+		text += "\n<i>Lihat: ketik</i> /tag &lt;nama&gt;"
+		b.sendMessageHTML(telegramID, text, nil)
+		return
+	}
+
+	tag := strings.Fields(args)[0]
+	confessions, err := b.confession.GetConfessionsByTag(ctx, tag, 10)
+	if err != nil {
+		b.sendMessage(telegramID, fmt.Sprintf("⚠️ %s", err.Error()), nil)
+		return
+	}
+
+	label := html.EscapeString(service.NormalizeTag(tag))
+	if len(confessions) == 0 {
+		b.sendMessageHTML(telegramID, fmt.Sprintf("🏷️ Belum ada confession dengan tag <b>#%s</b>.", label), nil)
+		return
 	}
 
-	header += "\n<i>React: ketik</i> /react &lt;id&gt; &lt;emoji&gt;\n<i>Balas: ketik</i> /reply &lt;id&gt; &lt;pesan&gt;\n<i>Lihat: ketik</i> /view_replies &lt;id&gt;"
+	text := fmt.Sprintf("<b>🏷️ Confession #%s</b>\n━━━━━━━━━━━━━━━━━━━\n\n", label)
+	text += b.formatConfessionList(ctx, confessions)
 
-	b.sendMessageHTML(telegramID, header, nil)
+	kb := ConfessionFeedKeyboard(confessions)
+	b.sendMessageHTML(telegramID, text, &kb)
+	b.sendConfessionPhotoCards(ctx, telegramID, confessions)
 }
 
 func (b *Bot) handleReact(ctx context.Context, msg *tgbotapi.Message) {
@@ -165,6 +386,12 @@ func (b *Bot) handleReply(ctx context.Context, msg *tgbotapi.Message) {
 		return
 	}
 
+	confession, err := b.db.GetConfession(ctx, confessionID)
+	if err != nil || confession == nil {
+		b.sendMessage(telegramID, "❌ Confession tidak ditemukan.", nil)
+		return
+	}
+
 	content := parts[1]
 	if b.profanity.IsBad(content) {
 		content = b.profanity.Clean(content)
@@ -177,8 +404,7 @@ func (b *Bot) handleReply(ctx context.Context, msg *tgbotapi.Message) {
 		return
 	}
 
-	confession, _ := b.db.GetConfession(ctx, confessionID)
-	if confession != nil && confession.AuthorID != telegramID {
+	if confession.AuthorID != telegramID {
 		logIfErr("increment_karma_reply", b.db.IncrementUserKarma(ctx, confession.AuthorID, 1))
 		b.checkAchievements(ctx, confession.AuthorID)
 	}
@@ -228,5 +454,106 @@ func (b *Bot) handleViewReplies(ctx context.Context, msg *tgbotapi.Message) {
 		response += fmt.Sprintf("<b>%d.</b> %s\n\n", i+1, html.EscapeString(r.Content))
 	}
 
-	b.sendMessageHTML(telegramID, response, nil)
+	kb := RepliesKeyboard(confessionID, replies)
+	b.sendMessageHTML(telegramID, response, &kb)
+}
+
+func (b *Bot) handleMyConfessions(ctx context.Context, msg *tgbotapi.Message) {
+	telegramID := msg.From.ID
+
+	confessions, err := b.confession.GetUserConfessions(ctx, telegramID, 10)
+	if err != nil {
+		b.sendMessage(telegramID, "❌ Gagal mengambil confession kamu.", nil)
+		return
+	}
+
+	if len(confessions) == 0 {
+		b.sendMessage(telegramID, "📋 Kamu belum punya confession. Kirim yang pertama dengan /confess!", nil)
+		return
+	}
+
+	text := "<b>🗂️ Confession Kamu</b>\n━━━━━━━━━━━━━━━━━━━\n\n"
+	editable := make(map[int64]bool)
+
+	for _, c := range confessions {
+		status := ""
+		if c.PhotoFileID != "" {
+			status = " | 📷"
+		}
+		if c.Status == models.ConfessionPending {
+			status += " | ⏳ <i>menunggu persetujuan admin</i>"
+		} else if c.Status == models.ConfessionRejected {
+			status += " | ❌ <i>ditolak admin</i>"
+		} else if c.IsHidden {
+			status += " | 🚩 <i>disembunyikan, menunggu review admin</i>"
+		} else if b.confession.CanEdit(c) {
+			editable[c.ID] = true
+			status += " | ✏️ <i>bisa diedit</i>"
+		}
+		if c.EditedAt != nil {
+			status += " | <i>(diedit)</i>"
+		}
+
+		text += fmt.Sprintf("💬 <b>#%d</b> | ❤️ %d%s\n%s\n─────────────────\n",
+			c.ID, c.LikeCount, status, html.EscapeString(c.Content))
+	}
+
+	text += fmt.Sprintf("\n<i>Confession hanya bisa diedit dalam %d menit setelah dikirim. Confession yang dihapus tidak akan tampil lagi di feed.</i>",
+		int(service.ConfessionEditWindow.Minutes()))
+
+	kb := MyConfessionsKeyboard(confessions, editable)
+	b.sendMessageHTML(telegramID, text, &kb)
+}
+
+func (b *Bot) handleConfessionEditInput(ctx context.Context, msg *tgbotapi.Message, stateData string) {
+	telegramID := msg.From.ID
+
+	confessionID, err := strconv.ParseInt(stateData, 10, 64)
+	if err != nil {
+		logIfErr("set_state_none_confess_edit_err", b.db.SetUserState(ctx, telegramID, models.StateNone, ""))
+		b.sendMessage(telegramID, "⚠️ Terjadi kesalahan. Coba lagi melalui /myconfessions.", nil)
+		return
+	}
+
+	if msg.Text == "" {
+		b.sendMessage(telegramID, "⚠️ Confession harus berupa teks.", nil)
+		return
+	}
+
+	text := validation.SanitizeText(msg.Text)
+	if errMsg := validation.ValidateText(text, validation.ConfessionLimits); errMsg != "" {
+		b.sendMessage(telegramID, errMsg, nil)
+		return
+	}
+
+	content := text
+	if b.profanity.IsBad(content) {
+		content = b.profanity.Clean(content)
+		metrics.ProfanityFiltered.Inc()
+		b.sendMessage(telegramID, "⚠️ *Peringatan:* Confession kamu mengandung kata-kata yang tidak pantas dan telah disensor.", nil)
+	}
+
+	logIfErr("set_state_none_after_confess_edit", b.db.SetUserState(ctx, telegramID, models.StateNone, ""))
+
+	if _, err := b.confession.EditConfession(ctx, confessionID, telegramID, content); err != nil {
+		b.sendMessage(telegramID, fmt.Sprintf("⚠️ %s", err.Error()), nil)
+		return
+	}
+
+	b.sendMessage(telegramID, fmt.Sprintf("✅ *Confession #%d berhasil diedit!*", confessionID), nil)
+}
+
+// hasPhotoNav reports whether msg is a photo card with paging arrows.
+func hasPhotoNav(msg *tgbotapi.Message) bool {
+	if msg == nil || msg.ReplyMarkup == nil {
+		return false
+	}
+	for _, row := range msg.ReplyMarkup.InlineKeyboard {
+		for _, btn := range row {
+			if btn.CallbackData != nil && strings.HasPrefix(*btn.CallbackData, "cphoto:") {
+				return true
+			}
+		}
+	}
+	return false
 }
@@ -0,0 +1,233 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/logger"
+	"github.com/pnj-anonymous-bot/internal/metrics"
+	"go.uber.org/zap"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const fanoutLimiterPruneSize = 10000
+
+// circleDelivery is a moderated circle message waiting to be sent to the
+// other members of the circle. Notices from the bot itself have no sender.
+type circleDelivery struct {
+	roomID     int64
+	senderID   int64
+	messageID  int
+	historyID  int64
+	recipients []int64
+	build      func(chatID int64) (string, tgbotapi.Chattable)
+}
+
+// fanoutLimiter spaces out sends both globally and per chat so circle
+// fan-out stays under Telegram's flood limits.
+type fanoutLimiter struct {
+	globalGap  time.Duration
+	perChatGap time.Duration
+	lastSend   time.Time
+	lastByChat map[int64]time.Time
+}
+
+func newFanoutLimiter(perSecond, perChatPerMinute int) *fanoutLimiter {
+	return &fanoutLimiter{
+		globalGap:  time.Second / time.Duration(perSecond),
+		perChatGap: time.Minute / time.Duration(perChatPerMinute),
+		lastByChat: make(map[int64]time.Time),
+	}
+}
+
+// delay returns how long to wait before chatID may receive another message.
+func (l *fanoutLimiter) delay(chatID int64, now time.Time) time.Duration {
+	wait := l.lastSend.Add(l.globalGap).Sub(now)
+	if last, ok := l.lastByChat[chatID]; ok {
+		if d := last.Add(l.perChatGap).Sub(now); d > wait {
+			wait = d
+		}
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+func (l *fanoutLimiter) record(chatID int64, now time.Time) {
+	l.lastSend = now
+	l.lastByChat[chatID] = now
+
+	if len(l.lastByChat) > fanoutLimiterPruneSize {
+		for id, last := range l.lastByChat {
+			if now.Sub(last) >= l.perChatGap {
+				delete(l.lastByChat, id)
+			}
+		}
+	}
+}
+
+// pause holds back every send until d has passed, used after a 429.
+func (l *fanoutLimiter) pause(now time.Time, d time.Duration) {
+	l.lastSend = now.Add(d - l.globalGap)
+}
+
+// isBotBlockedError reports whether the user blocked the bot or deleted their
+// account. Other 403s, such as a chat the bot was kicked from, are not about
+// the user's reachability.
+func isBotBlockedError(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+		return false
+	}
+	msg := strings.ToLower(apiErr.Message)
+	return strings.Contains(msg, "bot was blocked by the user") || strings.Contains(msg, "user is deactivated")
+}
+
+func retryAfter(err error) time.Duration {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return time.Duration(apiErr.RetryAfter) * time.Second
+	}
+	return 0
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// enqueueCircleDelivery hands a circle message to the fan-out worker without
+// blocking the sender's update.
+func (b *Bot) enqueueCircleDelivery(job circleDelivery) bool {
+	select {
+	case b.fanoutQ <- job:
+		metrics.CircleFanoutQueueDepth.Set(float64(len(b.fanoutQ)))
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *Bot) startCircleFanout(ctx context.Context) {
+	limiter := newFanoutLimiter(b.cfg.CircleFanoutPerSecond, b.cfg.CircleFanoutPerChatPerMinute)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if pending := len(b.fanoutQ); pending > 0 {
+				logger.Warn("Circle fan-out stopped with pending messages", zap.Int("pending", pending))
+			}
+			return
+		case job := <-b.fanoutQ:
+			metrics.CircleFanoutQueueDepth.Set(float64(len(b.fanoutQ)))
+			b.deliverCircleMessage(ctx, limiter, job)
+		}
+	}
+}
+
+func (b *Bot) deliverCircleMessage(ctx context.Context, limiter *fanoutLimiter, job circleDelivery) {
+	pending := append([]int64(nil), job.recipients...)
+	delivered := 0
+
+	for len(pending) > 0 {
+		now := time.Now()
+		next, wait := 0, limiter.delay(pending[0], now)
+		for i := 1; i < len(pending) && wait > 0; i++ {
+			if d := limiter.delay(pending[i], now); d < wait {
+				next, wait = i, d
+			}
+		}
+		if wait > 0 && !sleepContext(ctx, wait) {
+			return
+		}
+
+		chatID := pending[next]
+		pending = append(pending[:next], pending[next+1:]...)
+		if b.sendCircleDelivery(ctx, limiter, job, chatID) {
+			delivered++
+		}
+	}
+
+	if job.senderID == 0 {
+		return
+	}
+
+	report := tgbotapi.NewMessage(job.senderID, fmt.Sprintf("✓ Terkirim ke %d anggota", delivered))
+	report.ReplyToMessageID = job.messageID
+	report.DisableNotification = true
+	b.sendAPI("circle_delivery_report", report)
+}
+
+func (b *Bot) sendCircleDelivery(ctx context.Context, limiter *fanoutLimiter, job circleDelivery, chatID int64) bool {
+	operation, cfg := job.build(chatID)
+	if cfg == nil {
+		return false
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		limiter.record(chatID, time.Now())
+		sent, err := b.api.Send(cfg)
+		if err == nil {
+			metrics.CircleDeliveriesTotal.WithLabelValues("delivered").Inc()
+			if job.senderID != 0 {
+				logIfErr("record_circle_delivery", b.room.RecordDelivery(ctx, chatID, sent.MessageID, job.roomID, job.senderID, job.historyID))
+			}
+			return true
+		}
+
+		if isBotBlockedError(err) {
+			metrics.CircleDeliveriesTotal.WithLabelValues("blocked").Inc()
+			logger.Info("Circle member blocked the bot",
+				zap.Int64("room_id", job.roomID),
+				zap.Int64("member_id", chatID),
+			)
+			b.markUnreachable(ctx, chatID)
+			return false
+		}
+
+		if d := retryAfter(err); d > 0 {
+			metrics.CircleDeliveriesTotal.WithLabelValues("throttled").Inc()
+			limiter.pause(time.Now(), d)
+			if !sleepContext(ctx, d) {
+				return false
+			}
+			continue
+		}
+
+		logger.Warn("Failed to deliver circle message",
+			zap.Int64("room_id", job.roomID),
+			zap.Int64("member_id", chatID),
+			zap.Error(err),
+		)
+		metrics.TelegramAPIErrors.WithLabelValues(operation).Inc()
+		break
+	}
+
+	metrics.CircleDeliveriesTotal.WithLabelValues("failed").Inc()
+	return false
+}
+
+// circleNotice queues an HTML notice from the bot to the given circle members.
+func (b *Bot) circleNotice(roomID int64, recipients []int64, text string) bool {
+	return b.enqueueCircleDelivery(circleDelivery{
+		roomID:     roomID,
+		recipients: recipients,
+		build: func(chatID int64) (string, tgbotapi.Chattable) {
+			out := tgbotapi.NewMessage(chatID, text)
+			out.ParseMode = "HTML"
+			return "circle_notice", out
+		},
+	})
+}
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestFanoutLimiterDelay(t *testing.T) {
+	l := newFanoutLimiter(10, 30)
+	now := time.Now()
+
+	if d := l.delay(1, now); d != 0 {
+		t.Fatalf("first send should not wait, got %v", d)
+	}
+	l.record(1, now)
+
+	if d := l.delay(2, now); d != 100*time.Millisecond {
+		t.Fatalf("global gap should apply to other chats, got %v", d)
+	}
+	if d := l.delay(1, now); d != 2*time.Second {
+		t.Fatalf("per-chat gap should apply to the same chat, got %v", d)
+	}
+	if d := l.delay(1, now.Add(3*time.Second)); d != 0 {
+		t.Fatalf("chat should be ready after its gap, got %v", d)
+	}
+
+	l.pause(now, 5*time.Second)
+	if d := l.delay(2, now); d != 5*time.Second {
+		t.Fatalf("pause should hold back every chat, got %v", d)
+	}
+}
+
+func TestFanoutErrorClassification(t *testing.T) {
+	blocked := &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+	if !isBotBlockedError(blocked) {
+		t.Error("403 should be treated as blocked")
+	}
+	deactivated := &tgbotapi.Error{Code: 403, Message: "Forbidden: user is deactivated"}
+	if !isBotBlockedError(deactivated) {
+		t.Error("deactivated user should be treated as blocked")
+	}
+	kicked := &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was kicked from the group chat"}
+	if isBotBlockedError(kicked) {
+		t.Error("other 403s should not be treated as blocked")
+	}
+
+	throttled := &tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 3}}
+	if isBotBlockedError(throttled) {
+		t.Error("429 should not be treated as blocked")
+	}
+	if d := retryAfter(throttled); d != 3*time.Second {
+		t.Errorf("retryAfter = %v, want 3s", d)
+	}
+	if d := retryAfter(errors.New("network down")); d != 0 {
+		t.Errorf("retryAfter for plain errors = %v, want 0", d)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"html"
+	"strings"
 
 	"github.com/pnj-anonymous-bot/internal/logger"
 	"github.com/pnj-anonymous-bot/internal/models"
@@ -67,6 +68,11 @@ Ketik /regist dan ikuti proses verifikasi email PNJ kamu.`, html.EscapeString(ms
 		return
 	}
 
+	if code, ok := strings.CutPrefix(msg.CommandArguments(), "circle_"); ok {
+		b.joinCircleByInvite(ctx, telegramID, code)
+		return
+	}
+
 	b.showMainMenu(ctx, telegramID, user)
 }
 
@@ -118,14 +124,25 @@ func (b *Bot) handleHelp(ctx context.Context, msg *tgbotapi.Message) {
 /search — Cari partner chat
 /next — Skip ke partner baru
 /stop — Hentikan chat
+/chatrequests — Atur ajakan chat dari pembaca confession
 
 💬 <b>Fitur Interaksi</b>
 /confess — Kirim confession anonim
+/myconfessions — Edit/hapus confession kamu
+/search_confess — Cari confession
+/tag — Jelajahi confession per #hashtag
 /reply — Balas confession
 /poll — Buat polling anonim
+/dept_poll — Polling khusus jurusanmu
 /whisper — Pesan ke jurusan
 /circles — Gabung circle (group chat)
+/my_circles — Pilih, bisukan atau keluar dari circle kamu
 /leave_circle — Keluar dari circle
+/circle_kick, /circle_mute, /circle_pin — Moderasi circle (reply pesan)
+/circle_invite, /circle_visibility — Undangan & visibilitas circle
+/circle_history — Riwayat pesan circle
+/circle_remove — Hapus pesan dari riwayat (reply pesan)
+/circle_slowmode — Atur slow mode circle
 
 👤 <b>Profil & Achievement</b>
 /profile — Lihat profil & lencana
@@ -135,6 +152,7 @@ func (b *Bot) handleHelp(ctx context.Context, msg *tgbotapi.Message) {
 
 🛡️ <b>Keamanan & Legal</b>
 /report — Laporkan pelanggaran
+🚩 Tombol <b>Laporkan</b> — Laporkan confession, balasan, atau whisper
 /about — Informasi hukum & privasi
 /cancel — Batalkan aksi
 
@@ -188,6 +206,9 @@ func (b *Bot) handleCancel(ctx context.Context, msg *tgbotapi.Message) {
 	case models.StateAwaitingConfess:
 		logIfErr("set_state_none_cancel_confess", b.db.SetUserState(ctx, telegramID, models.StateNone, ""))
 		b.sendMessage(telegramID, "❌ Confession dibatalkan.", nil)
+	case models.StateAwaitingConfessEdit:
+		logIfErr("set_state_none_cancel_confess_edit", b.db.SetUserState(ctx, telegramID, models.StateNone, ""))
+		b.sendMessage(telegramID, "❌ Edit confession dibatalkan.", nil)
 	case models.StateAwaitingWhisper, models.StateAwaitingWhisperDept:
 		logIfErr("set_state_none_cancel_whisper", b.db.SetUserState(ctx, telegramID, models.StateNone, ""))
 		b.sendMessage(telegramID, "❌ Whisper dibatalkan.", nil)
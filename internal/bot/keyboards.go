@@ -2,6 +2,7 @@ package bot
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/pnj-anonymous-bot/internal/models"
 
@@ -222,6 +223,159 @@ func ConfessionReactionKeyboard(confessionID int64, counts map[string]int) tgbot
 
 	return tgbotapi.NewInlineKeyboardMarkup(
 		buttons,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💬 Chat penulis", fmt.Sprintf("authorchat:request:%d", confessionID)),
+			tgbotapi.NewInlineKeyboardButtonData("🚩 Laporkan", fmt.Sprintf("creport:%s:%d", models.ContentConfession, confessionID)),
+		),
+	)
+}
+
+func MyConfessionsKeyboard(confessions []*models.Confession, editable map[int64]bool) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, c := range confessions {
+		if c.IsHidden || c.Status != models.ConfessionPublished {
+			continue
+		}
+		var row []tgbotapi.InlineKeyboardButton
+		if editable[c.ID] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("✏️ Edit #%d", c.ID),
+				fmt.Sprintf("myconf:edit:%d", c.ID),
+			))
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("🗑️ Hapus #%d", c.ID),
+			fmt.Sprintf("myconf:delete:%d", c.ID),
+		))
+		rows = append(rows, row)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Kembali", "menu:main"),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func ConfessionFeedKeyboard(confessions []*models.Confession) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, c := range confessions {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("💬 Chat penulis #%d", c.ID),
+				fmt.Sprintf("authorchat:request:%d", c.ID),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("🚩 Laporkan #%d", c.ID),
+				fmt.Sprintf("creport:%s:%d", models.ContentConfession, c.ID),
+			),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func RepliesKeyboard(confessionID int64, replies []*models.ConfessionReply) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💬 Chat penulis", fmt.Sprintf("authorchat:request:%d", confessionID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("🚩 Laporkan Confession #%d", confessionID),
+				fmt.Sprintf("creport:%s:%d", models.ContentConfession, confessionID),
+			),
+		),
+	}
+
+	for i := 0; i < len(replies); i += 4 {
+		var row []tgbotapi.InlineKeyboardButton
+		for j := 0; j < 4 && i+j < len(replies); j++ {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("🚩 Balasan %d", i+j+1),
+				fmt.Sprintf("creport:%s:%d", models.ContentReply, replies[i+j].ID),
+			))
+		}
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func ConfessionPhotoNavRow(confessionID int64) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("◀️ Lebih baru", fmt.Sprintf("cphoto:newer:%d", confessionID)),
+		tgbotapi.NewInlineKeyboardButtonData("Lebih lama ▶️", fmt.Sprintf("cphoto:older:%d", confessionID)),
+	)
+}
+
+func ReportContentKeyboard(contentType models.ContentType, contentID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚩 Laporkan", fmt.Sprintf("creport:%s:%d", contentType, contentID)),
+		),
+	)
+}
+
+var contentReportReasons = []struct {
+	key   string
+	label string
+}{
+	{"harassment", "😡 Pelecehan / Bullying"},
+	{"sara", "🚫 SARA / Ujaran Kebencian"},
+	{"sexual", "🔞 Konten Seksual"},
+	{"doxxing", "🕵️ Menyebar Data Pribadi"},
+	{"spam", "📢 Spam"},
+}
+
+func contentReportReasonLabel(key string) string {
+	for _, r := range contentReportReasons {
+		if r.key == key {
+			return r.label
+		}
+	}
+	return ""
+}
+
+func ReportReasonKeyboard(contentType models.ContentType, contentID int64) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, r := range contentReportReasons {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(r.label, fmt.Sprintf("creport:send:%s:%d:%s", contentType, contentID, r.key)),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Batal", "creport:cancel"),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func PendingConfessionKeyboard(confessionID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Setujui", fmt.Sprintf("pconf:approve:%d", confessionID)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Tolak", fmt.Sprintf("pconf:reject:%d", confessionID)),
+		),
+	)
+}
+
+func AuthorChatRequestKeyboard(requestID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Terima", fmt.Sprintf("authorchat:accept:%d", requestID)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Tolak", fmt.Sprintf("authorchat:decline:%d", requestID)),
+		),
+	)
+}
+
+func ContentReviewKeyboard(flagID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Pulihkan", fmt.Sprintf("modq:restore:%d", flagID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑️ Hapus Permanen", fmt.Sprintf("modq:remove:%d", flagID)),
+		),
 	)
 }
 
@@ -272,20 +426,56 @@ func HelpKeyboard() tgbotapi.InlineKeyboardMarkup {
 	)
 }
 
-func PollVoteKeyboard(pollID int64, options []*models.PollOption) tgbotapi.InlineKeyboardMarkup {
+// PollVoteKeyboard lists the options of a poll. Single-choice polls vote on
+// tap; multi-choice and ranked polls toggle the draft ballot in selected and
+// are sent with the submit row.
+func PollVoteKeyboard(p *models.Poll, selected []int64) tgbotapi.InlineKeyboardMarkup {
 	var rows [][]tgbotapi.InlineKeyboardButton
-	for _, opt := range options {
+	for _, opt := range p.Options {
 		label := opt.OptionText
-		if opt.VoteCount > 0 {
+		if p.Type != models.PollRanked && opt.VoteCount > 0 {
 			label = fmt.Sprintf("%s (%d)", opt.OptionText, opt.VoteCount)
 		}
+		if i := slices.Index(selected, opt.ID); i >= 0 {
+			if p.Type == models.PollRanked {
+				label = fmt.Sprintf("%d. %s", i+1, label)
+			} else {
+				label = "✅ " + label
+			}
+		}
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("vote:%d:%d", pollID, opt.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("vote:%d:%d", p.ID, opt.ID)),
 		))
 	}
+	if p.Type == models.PollMulti || p.Type == models.PollRanked {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("📨 Kirim pilihan (%d)", len(selected)), fmt.Sprintf("vote:%d:submit", p.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📊 Lihat hasil", fmt.Sprintf("pollres:%d", p.ID)),
+	))
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
+func PollBreakdownKeyboard(pollID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏛️ Per jurusan", fmt.Sprintf("pollbd:%d:department", pollID)),
+			tgbotapi.NewInlineKeyboardButtonData("🎓 Per angkatan", fmt.Sprintf("pollbd:%d:year", pollID)),
+			tgbotapi.NewInlineKeyboardButtonData("👤 Per gender", fmt.Sprintf("pollbd:%d:gender", pollID)),
+		),
+	)
+}
+
+func PollManageKeyboard(pollID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔒 Tutup sekarang", fmt.Sprintf("pollclose:%d", pollID)),
+		),
+	)
+}
+
 func LegalAgreementKeyboard() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -297,8 +487,15 @@ func LegalAgreementKeyboard() tgbotapi.InlineKeyboardMarkup {
 func RoomsKeyboard(rooms []*models.Room) tgbotapi.InlineKeyboardMarkup {
 	var rows [][]tgbotapi.InlineKeyboardButton
 	for _, r := range rooms {
+		label := fmt.Sprintf("%s (%d members)", r.Name, r.MemberCount)
+		switch r.Visibility {
+		case models.RoomDepartmentOnly:
+			label = "🏛️ " + label
+		case models.RoomInviteOnly:
+			label = "🔒 " + label
+		}
 		btn := tgbotapi.NewInlineKeyboardButtonData(
-			fmt.Sprintf("%s (%d members)", r.Name, r.MemberCount),
+			label,
 			fmt.Sprintf("circle:join:%s", r.Slug),
 		)
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(btn))
@@ -306,6 +503,7 @@ func RoomsKeyboard(rooms []*models.Room) tgbotapi.InlineKeyboardMarkup {
 
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("➕ Buat Circle Baru", "circle:create"),
+		tgbotapi.NewInlineKeyboardButtonData("📋 Circle Saya", "circle:mine"),
 	))
 
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
@@ -315,6 +513,52 @@ func RoomsKeyboard(rooms []*models.Room) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
+func MyCirclesKeyboard(rooms []*models.Room) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, r := range rooms {
+		label := r.Name
+		if !r.IsActive {
+			label = "📦 " + label
+		}
+		if r.Speaking {
+			label = "🗣️ " + label
+		}
+		mute := "🔔"
+		if r.Muted {
+			mute = "🔕"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("circle:speak:%d", r.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(mute, fmt.Sprintf("circle:silence:%d", r.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🚪", fmt.Sprintf("circle:leave_id:%d", r.ID)),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("➕ Gabung Circle Lain", "menu:circles"),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func CircleVisibilityKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🌐 Publik", "circle:vis:public"),
+			tgbotapi.NewInlineKeyboardButtonData("🏛️ Jurusan", "circle:vis:department"),
+			tgbotapi.NewInlineKeyboardButtonData("🔒 Undangan", "circle:vis:invite"),
+		),
+	)
+}
+
+func CircleHistoryKeyboard(beforeID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Pesan lebih lama", fmt.Sprintf("circlehist:%d", beforeID)),
+		),
+	)
+}
+
 func LeaveCircleKeyboard() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
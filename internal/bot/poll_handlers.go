@@ -6,74 +6,277 @@ import (
 	"html"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/logger"
+	"github.com/pnj-anonymous-bot/internal/metrics"
+	"github.com/pnj-anonymous-bot/internal/models"
+	"github.com/pnj-anonymous-bot/internal/service"
+	"github.com/pnj-anonymous-bot/internal/validation"
+	"go.uber.org/zap"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// parsePollArgs parses "[durasi] [tipe] Pertanyaan | Opsi 1 | Opsi 2 | ...".
+// The returned poll carries the question, type and deadline; callers fill in
+// the author and scope.
+func parsePollArgs(args string) (*models.Poll, []string, time.Duration, string) {
+	parts := strings.Split(args, "|")
+	if len(parts) < 3 {
+		return nil, nil, 0, "⚠️ <b>Format salah.</b> Minimal harus ada pertanyaan dan 2 opsi jawaban."
+	}
+
+	var options []string
+	for i := 1; i < len(parts); i++ {
+		opt := strings.TrimSpace(parts[i])
+		if opt != "" {
+			options = append(options, opt)
+		}
+	}
+
+	if len(options) < 2 {
+		return nil, nil, 0, "⚠️ <b>Format salah.</b> Minimal harus ada 2 opsi jawaban yang valid."
+	}
+
+	poll := &models.Poll{Type: models.PollSingle, MaxChoices: 1}
+	var duration time.Duration
+	question := strings.TrimSpace(parts[0])
+	for {
+		fields := strings.Fields(question)
+		if len(fields) < 2 {
+			break
+		}
+
+		token := strings.ToLower(fields[0])
+		if d, ok := validation.ParsePollDuration(token); ok && duration == 0 {
+			if errMsg := validation.ValidatePollDuration(d); errMsg != "" {
+				return nil, nil, 0, errMsg
+			}
+			duration = d
+		} else if poll.Type != models.PollSingle {
+			break
+		} else if token == "ranked" {
+			poll.Type = models.PollRanked
+			poll.MaxChoices = len(options)
+		} else if token == "multi" || strings.HasPrefix(token, "multi:") {
+			maxChoices := len(options)
+			if k, found := strings.CutPrefix(token, "multi:"); found {
+				n, err := strconv.Atoi(k)
+				if err != nil || n < 2 || n > len(options) {
+					return nil, nil, 0, fmt.Sprintf("⚠️ Batas pilihan harus antara 2 dan %d (jumlah opsi).", len(options))
+				}
+				maxChoices = n
+			}
+			poll.Type = models.PollMulti
+			poll.MaxChoices = maxChoices
+		} else {
+			break
+		}
+		question = strings.TrimSpace(strings.TrimPrefix(question, fields[0]))
+	}
+
+	if errMsg := validation.ValidatePoll(question, options); errMsg != "" {
+		return nil, nil, 0, errMsg
+	}
+
+	poll.Question = question
+	poll.ExpiresAt = pollExpiry(duration)
+	return poll, options, duration, ""
+}
+
+func pollExpiry(duration time.Duration) *time.Time {
+	if duration <= 0 {
+		return nil
+	}
+	expiresAt := time.Now().Add(duration)
+	return &expiresAt
+}
+
 func (b *Bot) handlePoll(ctx context.Context, msg *tgbotapi.Message) {
+	b.createUserPoll(ctx, msg, models.PollPersonal)
+}
+
+func (b *Bot) handleDeptPoll(ctx context.Context, msg *tgbotapi.Message) {
+	b.createUserPoll(ctx, msg, models.PollDepartment)
+}
+
+func (b *Bot) createUserPoll(ctx context.Context, msg *tgbotapi.Message, scope models.PollScope) {
 	telegramID := msg.From.ID
 	args := msg.CommandArguments()
 
+	command, audience := "/poll", "Semua mahasiswa"
+	if scope == models.PollDepartment {
+		command, audience = "/dept_poll", "Hanya mahasiswa jurusanmu"
+	}
+
 	if args == "" {
-		b.sendMessageHTML(telegramID, `<b>🗳️ Cara Membuat Polling Anonim</b>
+		b.sendMessageHTML(telegramID, fmt.Sprintf(`<b>🗳️ Cara Membuat Polling Anonim</b>
+
+Ketik: <code>%[1]s [durasi] [tipe] Pertanyaan | Opsi 1 | Opsi 2 | ...</code>
 
-Ketik: <code>/poll Pertanyaan | Opsi 1 | Opsi 2 | ...</code>
+Contoh: <code>%[1]s Setuju gak harga parkir naik? | Setuju | Tidak Setuju</code>
+Dengan batas waktu: <code>%[1]s 24h Setuju gak harga parkir naik? | Setuju | Tidak Setuju</code>
+Pilih maksimal 2: <code>%[1]s multi:2 Menu kantin favorit? | Bakso | Soto | Nasi Goreng</code>
+Urutkan preferensi: <code>%[1]s ranked Ketua BEM pilihanmu? | Calon A | Calon B | Calon C</code>
 
-Contoh: <code>/poll Setuju gak harga parkir naik? | Setuju | Tidak Setuju</code>`, nil)
+<i>%[2]s bisa ikut memilih. Durasi opsional: m (menit), h (jam), d (hari). Polling tanpa durasi tetap terbuka sampai kamu menutupnya. Tipe opsional: <code>multi:K</code> (pilih hingga K opsi) atau <code>ranked</code> (urutkan pilihan, pemenang dihitung dengan sistem eliminasi).</i>`, command, audience), nil)
 		return
 	}
 
-	parts := strings.Split(args, "|")
-	if len(parts) < 3 {
-		b.sendMessageHTML(telegramID, "⚠️ <b>Format salah.</b> Minimal harus ada pertanyaan dan 2 opsi jawaban.", nil)
+	poll, options, duration, errMsg := parsePollArgs(args)
+	if errMsg != "" {
+		b.sendMessageHTML(telegramID, errMsg, nil)
 		return
 	}
 
-	question := strings.TrimSpace(parts[0])
-	var options []string
-	for i := 1; i < len(parts); i++ {
-		opt := strings.TrimSpace(parts[i])
-		if opt != "" {
-			options = append(options, opt)
+	poll.AuthorID = &telegramID
+	poll.Scope = scope
+	if scope == models.PollDepartment {
+		user, userErr := b.db.GetUser(ctx, telegramID)
+		if userErr != nil || user == nil || user.Department == "" {
+			b.sendMessageHTML(telegramID, "⚠️ Lengkapi jurusan di profil kamu terlebih dahulu.", nil)
+			return
 		}
+		poll.Department = string(user.Department)
 	}
 
-	if len(options) < 2 {
-		b.sendMessageHTML(telegramID, "⚠️ <b>Format salah.</b> Minimal harus ada 2 opsi jawaban yang valid.", nil)
+	pollID, err := b.poll.CreatePoll(ctx, poll, options)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "⚠️ "+html.EscapeString(err.Error()), nil)
 		return
 	}
 
-	pollID, err := b.db.CreatePoll(ctx, telegramID, question, options)
-	if err != nil {
-		b.sendMessageHTML(telegramID, "❌ Gagal membuat polling.", nil)
+	text := fmt.Sprintf("✅ <b>Polling #%d berhasil dibuat!</b>\n%s sekarang bisa memberikan suara secara anonim.", pollID, audience)
+	text += fmt.Sprintf("\n\n⭐ Kamu dapat karma setelah polling ini dipilih minimal <b>%d orang</b>.", b.cfg.PollKarmaMinVotes)
+	if duration > 0 {
+		text += fmt.Sprintf("\n\n⏳ Polling otomatis ditutup dalam <b>%s</b> dan hasilnya akan dikirim ke kamu.", formatPollDuration(duration))
+	}
+	kb := PollManageKeyboard(pollID)
+	b.sendMessageHTML(telegramID, text, &kb)
+}
+
+func (b *Bot) rewardPollAuthor(ctx context.Context, pollID int64) {
+	authorID, ok := b.poll.RewardAuthor(ctx, pollID)
+	if !ok {
 		return
 	}
+	b.sendMessageHTML(authorID, fmt.Sprintf("⭐ <b>Polling #%d sudah dipilih %d orang!</b> Kamu mendapat karma.", pollID, b.cfg.PollKarmaMinVotes), nil)
+	b.checkAchievements(ctx, authorID)
+}
+
+func formatPollDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d hari", int(d.Hours()/24))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%d jam", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d menit", int(d.Minutes()))
+	}
+}
 
-	_ = b.db.IncrementUserKarma(ctx, telegramID, 3)
-	b.checkAchievements(ctx, telegramID)
+func pollStatusLabel(p *models.Poll) string {
+	if !p.IsOpen(time.Now()) {
+		return "🔒 Ditutup"
+	}
+	if p.ExpiresAt != nil {
+		return "⏳ Berakhir " + p.ExpiresAt.Format("02 Jan 15:04")
+	}
+	return "🟢 Terbuka"
+}
+
+func formatPollResults(p *models.Poll) string {
+	total := 0
+	for _, opt := range p.Options {
+		total += opt.VoteCount
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📊 <b>Hasil Polling #%d</b>\n\n<b>%s</b>\n\n", p.ID, html.EscapeString(p.Question)))
+	for _, opt := range p.Options {
+		percent := 0
+		if total > 0 {
+			percent = opt.VoteCount * 100 / total
+		}
+		bar := strings.Repeat("█", percent/10) + strings.Repeat("░", 10-percent/10)
+		sb.WriteString(fmt.Sprintf("%s\n<code>%s</code> %d%% (%d)\n\n", html.EscapeString(opt.OptionText), bar, percent, opt.VoteCount))
+	}
+	sb.WriteString(fmt.Sprintf("👥 <i>Total %d suara</i>", total))
+	if p.Type == models.PollRanked {
+		sb.WriteString("\n<i>Angka di atas adalah pilihan pertama tiap pemilih.</i>")
+	}
+	return sb.String()
+}
 
-	b.sendMessageHTML(telegramID, fmt.Sprintf("✅ <b>Polling #%d berhasil dibuat!</b>\nSemua mahasiswa sekarang bisa memberikan suara secara anonim.", pollID), nil)
+// rankedSummary describes the instant-runoff winner of a ranked poll, or
+// returns "" for other poll types.
+func (b *Bot) rankedSummary(ctx context.Context, p *models.Poll) string {
+	if p.Type != models.PollRanked {
+		return ""
+	}
+	result, err := b.poll.RankedResult(ctx, p)
+	if err != nil {
+		logger.Warn("Failed to tally ranked poll", zap.Int64("poll_id", p.ID), zap.Error(err))
+		return ""
+	}
+	if result.Winner == 0 {
+		if service.PollTotalVotes(p) == 0 {
+			return ""
+		}
+		return "\n\n🏆 <i>Belum ada pemenang, hasil eliminasi masih imbang.</i>"
+	}
+	for _, opt := range p.Options {
+		if opt.ID == result.Winner {
+			return fmt.Sprintf("\n\n🏆 <b>Pemenang:</b> %s\n<i>Ditentukan dengan eliminasi bertahap dalam %d putaran.</i>",
+				html.EscapeString(opt.OptionText), len(result.Rounds))
+		}
+	}
+	return ""
 }
 
 func (b *Bot) handleViewPolls(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
 
-	polls, err := b.db.GetLatestPollsContext(ctx, 15)
+	department := ""
+	if user, _ := b.db.GetUser(ctx, telegramID); user != nil {
+		department = string(user.Department)
+	}
+
+	globalPolls, err := b.db.GetGlobalPolls(ctx, 3)
+	if err != nil {
+		b.sendMessageHTML(telegramID, "❌ Gagal mengambil polling.", nil)
+		return
+	}
+	polls, err := b.db.GetLatestPollsContext(ctx, department, 15)
 	if err != nil {
 		b.sendMessageHTML(telegramID, "❌ Gagal mengambil polling.", nil)
 		return
 	}
 
-	if len(polls) == 0 {
+	if len(polls) == 0 && len(globalPolls) == 0 {
 		b.sendMessageHTML(telegramID, "📋 Belum ada polling aktif. Buat yang pertama dengan /poll!", nil)
 		return
 	}
 
 	header := "<b>🗳️ Daftar Polling Terbaru</b>\n━━━━━━━━━━━━━━━━━━━\n\n"
 
+	if len(globalPolls) > 0 {
+		header += "📌 <b>Polling Global</b>\n\n"
+		for _, p := range globalPolls {
+			count, _ := b.db.GetPollVoteCountContext(ctx, p.ID)
+			header += fmt.Sprintf("🌐 <b>#%d</b>: %s\n👥 <i>%d Suara</i> · %s\n\n", p.ID, html.EscapeString(p.Question), count, pollStatusLabel(p))
+		}
+		header += "━━━━━━━━━━━━━━━━━━━\n\n"
+	}
+
 	for _, p := range polls {
 		count, _ := b.db.GetPollVoteCountContext(ctx, p.ID)
-		header += fmt.Sprintf("📊 <b>#%d</b>: %s\n👥 <i>%d Suara</i>\n\n", p.ID, html.EscapeString(p.Question), count)
+		icon := "📊"
+		if p.Scope == models.PollDepartment {
+			icon = models.DepartmentEmoji(models.Department(p.Department))
+		}
+		header += fmt.Sprintf("%s <b>#%d</b>: %s\n👥 <i>%d Suara</i> · %s\n\n", icon, p.ID, html.EscapeString(p.Question), count, pollStatusLabel(p))
 	}
 
 	header += "━━━━━━━━━━━━━━━━━━━\n<i>Ikut memilih: ketik</i> <code>/vote_poll &lt;id&gt;</code>"
@@ -97,12 +300,324 @@ func (b *Bot) handleVotePoll(ctx context.Context, msg *tgbotapi.Message) {
 	}
 
 	p, err := b.db.GetPoll(ctx, pollID)
-	if err != nil || p == nil {
+	if err != nil || p == nil || !b.canAccessPoll(ctx, telegramID, p) {
 		b.sendMessageHTML(telegramID, "❌ Polling tidak ditemukan.", nil)
 		return
 	}
 
-	kb := PollVoteKeyboard(p.ID, p.Options)
-	text := fmt.Sprintf("🗳️ <b>Polling #%d</b>\n\n<b>Pertanyaan:</b>\n%s\n\n<i>Pilih opsi di bawah untuk memberikan suara secara anonim:</i>", p.ID, html.EscapeString(p.Question))
+	if !p.IsOpen(time.Now()) {
+		b.sendMessageHTML(telegramID, "🔒 <i>Polling ini sudah ditutup.</i>\n\n"+formatPollResults(p)+b.rankedSummary(ctx, p), nil)
+		return
+	}
+
+	if p.IsNative {
+		text := "🗳️ <i>Polling ini dipilih lewat polling Telegram yang dikirim bot ke chat kamu.</i>\n\n" + formatPollResults(p)
+		if b.canClosePoll(ctx, telegramID, p) {
+			kb := PollManageKeyboard(p.ID)
+			b.sendMessageHTML(telegramID, text, &kb)
+			return
+		}
+		b.sendMessageHTML(telegramID, text, nil)
+		return
+	}
+
+	kb := PollVoteKeyboard(p, b.poll.GetBallot(ctx, p.ID, telegramID))
+	if b.canClosePoll(ctx, telegramID, p) {
+		kb.InlineKeyboard = append(kb.InlineKeyboard, PollManageKeyboard(p.ID).InlineKeyboard...)
+	}
+
+	title := fmt.Sprintf("🗳️ <b>Polling #%d</b>", p.ID)
+	switch p.Scope {
+	case models.PollGlobal:
+		title = fmt.Sprintf("🌐 <b>Polling Global #%d</b>", p.ID)
+	case models.PollDepartment:
+		title = fmt.Sprintf("%s <b>Polling Jurusan #%d</b> — %s", models.DepartmentEmoji(models.Department(p.Department)), p.ID, html.EscapeString(p.Department))
+	}
+
+	instruction := "Pilih opsi di bawah untuk memberikan suara secara anonim:"
+	switch p.Type {
+	case models.PollMulti:
+		instruction = fmt.Sprintf("Pilih hingga %d opsi, lalu tekan Kirim pilihan:", p.MaxChoices)
+	case models.PollRanked:
+		instruction = "Tekan opsi sesuai urutan preferensimu (pertama = paling disukai), lalu tekan Kirim pilihan:"
+	}
+	text := fmt.Sprintf("%s\n\n<b>Pertanyaan:</b>\n%s\n\n<i>%s</i>", title, html.EscapeString(p.Question), instruction)
+	if p.ExpiresAt != nil {
+		text += fmt.Sprintf("\n\n⏳ <i>Ditutup pada %s</i>", p.ExpiresAt.Format("02 Jan 2006 15:04"))
+	}
 	b.sendMessageHTML(telegramID, text, &kb)
 }
+
+// canAccessPoll restricts department polls to members of that department.
+func (b *Bot) canAccessPoll(ctx context.Context, telegramID int64, p *models.Poll) bool {
+	if p.Scope != models.PollDepartment || p.IsAuthor(telegramID) {
+		return true
+	}
+	user, err := b.db.GetUser(ctx, telegramID)
+	return err == nil && user != nil && string(user.Department) == p.Department
+}
+
+func (b *Bot) canClosePoll(ctx context.Context, telegramID int64, p *models.Poll) bool {
+	if p.Scope == models.PollGlobal {
+		return b.can(ctx, telegramID, models.PermGlobalPoll)
+	}
+	return p.IsAuthor(telegramID)
+}
+
+func (b *Bot) handlePollResultsCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	pollID, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		return
+	}
+
+	p, err := b.db.GetPoll(ctx, pollID)
+	if err != nil || p == nil || !b.canAccessPoll(ctx, telegramID, p) {
+		b.answerCallback(callback.ID, "❌ Polling tidak ditemukan.")
+		return
+	}
+
+	b.answerCallback(callback.ID, "")
+	b.sendPollChart(ctx, telegramID, p)
+}
+
+// sendPollChart sends the results as a bar chart image, reusing the uploaded
+// file when the vote count has not changed since it was last rendered.
+func (b *Bot) sendPollChart(ctx context.Context, chatID int64, p *models.Poll) {
+	caption := fmt.Sprintf("📊 <b>Hasil Polling #%d</b> · %s", p.ID, pollStatusLabel(p))
+	if p.Scope == models.PollGlobal {
+		caption = fmt.Sprintf("🌐 <b>Hasil Polling Global #%d</b> · %s", p.ID, pollStatusLabel(p))
+	}
+	if p.Type == models.PollRanked {
+		caption += "\n<i>Grafik menunjukkan pilihan pertama.</i>" + b.rankedSummary(ctx, p)
+	}
+
+	var file tgbotapi.RequestFileData
+	if fileID := b.pollChart.CachedFileID(ctx, p); fileID != "" {
+		file = tgbotapi.FileID(fileID)
+	} else {
+		chart, err := service.RenderPollChart(p)
+		if err != nil {
+			logger.Warn("Failed to render poll chart", zap.Int64("poll_id", p.ID), zap.Error(err))
+			kb := PollBreakdownKeyboard(p.ID)
+			b.sendMessageHTML(chatID, formatPollResults(p)+b.rankedSummary(ctx, p), &kb)
+			return
+		}
+		file = tgbotapi.FileBytes{Name: fmt.Sprintf("poll_%d.png", p.ID), Bytes: chart}
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, file)
+	photo.Caption = caption
+	photo.ParseMode = "HTML"
+	photo.ReplyMarkup = PollBreakdownKeyboard(p.ID)
+	sent, err := b.api.Send(photo)
+	if err != nil {
+		logger.Warn("Failed to send poll chart", zap.Int64("poll_id", p.ID), zap.Error(err))
+		metrics.TelegramAPIErrors.WithLabelValues("send_poll_chart").Inc()
+		return
+	}
+
+	if _, rendered := file.(tgbotapi.FileBytes); rendered && len(sent.Photo) > 0 {
+		b.pollChart.StoreFileID(ctx, p, sent.Photo[len(sent.Photo)-1].FileID)
+	}
+}
+
+var pollBreakdownTitles = map[string]string{
+	"department": "🏛️ Per Jurusan",
+	"year":       "🎓 Per Angkatan",
+	"gender":     "👤 Per Gender",
+}
+
+func (b *Bot) handlePollBreakdownCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	parts := strings.SplitN(data, ":", 2)
+	if len(parts) < 2 {
+		return
+	}
+	pollID, err := strconv.ParseInt(parts[0], 10, 64)
+	title, ok := pollBreakdownTitles[parts[1]]
+	if err != nil || !ok {
+		return
+	}
+
+	p, err := b.db.GetPoll(ctx, pollID)
+	if err != nil || p == nil || !b.canAccessPoll(ctx, telegramID, p) {
+		b.answerCallback(callback.ID, "❌ Polling tidak ditemukan.")
+		return
+	}
+
+	buckets, err := b.poll.Breakdown(ctx, p, parts[1])
+	if err != nil {
+		logger.Warn("Failed to load poll breakdown", zap.Int64("poll_id", p.ID), zap.Error(err))
+		b.answerCallback(callback.ID, "❌ Gagal memuat rincian hasil.")
+		return
+	}
+
+	b.answerCallback(callback.ID, "")
+	b.sendMessageHTML(telegramID, formatPollBreakdown(p, title, parts[1], buckets), nil)
+}
+
+func formatPollBreakdown(p *models.Poll, title, dimension string, buckets []service.PollBreakdownBucket) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📊 <b>Rincian Polling #%d — %s</b>\n<b>%s</b>\n\n", p.ID, title, html.EscapeString(p.Question)))
+
+	if len(buckets) == 0 {
+		sb.WriteString(fmt.Sprintf("<i>Belum cukup suara untuk ditampilkan. Setiap kelompok butuh minimal %d pemilih agar pilihan individu tidak bisa ditebak.</i>", service.PollBreakdownMinVoters))
+		return sb.String()
+	}
+
+	for _, bucket := range buckets {
+		label := bucket.Label
+		switch {
+		case bucket.Merged:
+			label = "Kelompok lainnya"
+		case label == "" || (dimension == "year" && label == "0"):
+			label = "Belum diisi"
+		case dimension == "year":
+			label = "Angkatan " + label
+		}
+		sb.WriteString(fmt.Sprintf("<b>%s</b> · %d pemilih\n", html.EscapeString(label), bucket.Voters))
+		for _, opt := range p.Options {
+			n := bucket.Votes[opt.ID]
+			sb.WriteString(fmt.Sprintf("  • %s: %d (%d%%)\n", html.EscapeString(opt.OptionText), n, n*100/bucket.Voters))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("<i>Kelompok dengan kurang dari %d pemilih digabung atau disembunyikan demi anonimitas.</i>", service.PollBreakdownMinVoters))
+	if p.Type == models.PollRanked {
+		sb.WriteString("\n<i>Polling berperingkat: hanya pilihan pertama yang dihitung.</i>")
+	}
+	return sb.String()
+}
+
+func (b *Bot) handlePollCloseCallback(ctx context.Context, telegramID int64, data string, callback *tgbotapi.CallbackQuery) {
+	pollID, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		return
+	}
+
+	p, err := b.db.GetPoll(ctx, pollID)
+	if err != nil || p == nil || !b.canClosePoll(ctx, telegramID, p) {
+		b.answerCallback(callback.ID, "❌ Polling tidak ditemukan.")
+		return
+	}
+
+	if err := b.db.ClosePoll(ctx, pollID); err != nil {
+		b.answerCallback(callback.ID, "⚠️ Polling ini sudah ditutup.")
+		return
+	}
+
+	if p.Scope == models.PollGlobal {
+		b.recordAudit(ctx, telegramID, models.AuditPollClose, 0, map[string]any{"poll_id": pollID})
+	}
+	b.answerCallback(callback.ID, "🔒 Polling ditutup.")
+	b.deleteMessage(telegramID, callback.Message.MessageID, "delete_poll_close")
+	b.announcePollResults(ctx, pollID)
+}
+
+// announcePollResults sends the final tally to the poll creator, or to the
+// staff running global polls, and queues it for every voter of a global poll
+// through the broadcast worker.
+func (b *Bot) announcePollResults(ctx context.Context, pollID int64) {
+	p, err := b.db.GetPoll(ctx, pollID)
+	if err != nil || p == nil {
+		logger.Warn("Failed to load closed poll", zap.Int64("poll_id", pollID), zap.Error(err))
+		return
+	}
+
+	text := "🔒 <b>Polling telah ditutup!</b>\n\n" + formatPollResults(p) + b.rankedSummary(ctx, p)
+	if p.Scope != models.PollGlobal {
+		if p.AuthorID != nil {
+			b.sendMessageHTML(*p.AuthorID, text, nil)
+		}
+		return
+	}
+
+	b.notifyStaff(ctx, models.PermGlobalPoll, text, nil)
+	id, count, err := b.broadcast.AnnouncePollResults(ctx, pollID, text, 0)
+	if err != nil {
+		logger.Warn("Failed to queue poll results", zap.Int64("poll_id", pollID), zap.Error(err))
+		return
+	}
+	logger.Info("Poll results queued", zap.Int64("poll_id", pollID), zap.Int64("broadcast_id", id), zap.Int("recipients", count))
+	b.wakeBroadcastWorker()
+}
+
+// sendNativePoll posts the poll as an anonymous native Telegram poll and
+// remembers its Telegram ID so the poll updates carrying its per-option
+// voter counts can be mapped back.
+func (b *Bot) sendNativePoll(ctx context.Context, chatID int64, p *models.Poll) error {
+	options := make([]string, len(p.Options))
+	for i, opt := range p.Options {
+		options[i] = opt.OptionText
+	}
+
+	cfg := tgbotapi.NewPoll(chatID, p.Question, options...)
+	cfg.IsAnonymous = true
+	cfg.AllowsMultipleAnswers = p.Type == models.PollMulti
+	if p.ExpiresAt != nil {
+		cfg.CloseDate = int(p.ExpiresAt.Unix())
+	}
+
+	sent, err := b.api.Send(cfg)
+	if err != nil {
+		return err
+	}
+	if sent.Poll == nil {
+		return fmt.Errorf("telegram did not return the sent poll")
+	}
+	return b.db.SaveTelegramPoll(ctx, &models.TelegramPoll{
+		TelegramPollID: sent.Poll.ID,
+		PollID:         p.ID,
+		ChatID:         chatID,
+		MessageID:      sent.MessageID,
+	})
+}
+
+// stopChatNativePoll closes the native poll a chat received for pollID, if
+// any, so its tally freezes alongside the results announcement.
+func (b *Bot) stopChatNativePoll(ctx context.Context, pollID, chatID int64) {
+	tp, err := b.db.GetChatTelegramPoll(ctx, pollID, chatID)
+	if err != nil || tp == nil {
+		logIfErr("get_chat_telegram_poll", err)
+		return
+	}
+	if _, err := b.api.Request(tgbotapi.NewStopPoll(tp.ChatID, tp.MessageID)); err != nil {
+		logger.Debug("Failed to stop native poll", zap.String("telegram_poll_id", tp.TelegramPollID), zap.Error(err))
+	}
+}
+
+// handleNativePollUpdate records the anonymous tally of a native poll.
+func (b *Bot) handleNativePollUpdate(ctx context.Context, p *tgbotapi.Poll) {
+	counts := make([]int, len(p.Options))
+	for i, opt := range p.Options {
+		counts[i] = opt.VoterCount
+	}
+	if err := b.poll.RecordNativeCounts(ctx, p.ID, p.TotalVoterCount, counts); err != nil {
+		logger.Warn("Failed to record native poll counts", zap.String("telegram_poll_id", p.ID), zap.Error(err))
+	}
+}
+
+func (b *Bot) startPollWorker(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			polls, err := b.db.GetExpiredPolls(ctx, time.Now())
+			if err != nil {
+				logger.Error("⚠️ Poll worker error", zap.Error(err))
+				continue
+			}
+
+			for _, p := range polls {
+				if err := b.db.ClosePoll(ctx, p.ID); err != nil {
+					continue
+				}
+				b.announcePollResults(ctx, p.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/pnj-anonymous-bot/internal/logger"
+	"github.com/pnj-anonymous-bot/internal/metrics"
+	"go.uber.org/zap"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleMyChatMember tracks users blocking and unblocking the bot in their
+// private chat.
+func (b *Bot) handleMyChatMember(ctx context.Context, update *tgbotapi.ChatMemberUpdated) {
+	if !update.Chat.IsPrivate() {
+		return
+	}
+
+	switch update.NewChatMember.Status {
+	case "kicked", "left":
+		b.markUnreachable(ctx, update.From.ID)
+	case "member":
+		returned, err := b.db.SetUserReachable(ctx, update.From.ID, true)
+		logIfErr("set_user_reachable", err)
+		if returned {
+			logger.Info("User unblocked the bot", zap.Int64("user_id", update.From.ID))
+		}
+	}
+}
+
+// handleSendError marks the recipient unreachable when Telegram says the bot
+// was blocked.
+func (b *Bot) handleSendError(chatID int64, err error) {
+	if chatID == 0 || !isBotBlockedError(err) {
+		return
+	}
+	b.markUnreachable(context.Background(), chatID)
+}
+
+// markUnreachable flags a user who blocked the bot, ends their chat session,
+// drops them from the search queue and removes them from their circles. It
+// does nothing when the user was already unreachable.
+func (b *Bot) markUnreachable(ctx context.Context, telegramID int64) {
+	changed, err := b.db.SetUserReachable(ctx, telegramID, false)
+	if err != nil || !changed {
+		logIfErr("set_user_unreachable", err)
+		return
+	}
+
+	metrics.UsersBlockedBot.Inc()
+	logger.Info("User blocked the bot, cleaning up", zap.Int64("user_id", telegramID))
+
+	partnerID, err := b.chat.StopChat(ctx, telegramID)
+	logIfErr("stop_chat_unreachable", err)
+	if partnerID != 0 {
+		b.sendMessageHTML(partnerID, "👋 <b>Partner telah meninggalkan chat.</b>\nKetik /search untuk mencari partner baru.", nil)
+	}
+
+	logIfErr("leave_rooms_unreachable", b.room.LeaveAllRooms(ctx, telegramID))
+}
+
+// chattableChatID returns the chat a config is addressed to, or 0 for
+// configs the bot does not send through sendAPI.
+func chattableChatID(c tgbotapi.Chattable) int64 {
+	switch v := c.(type) {
+	case tgbotapi.MessageConfig:
+		return v.ChatID
+	case tgbotapi.PhotoConfig:
+		return v.ChatID
+	case tgbotapi.VoiceConfig:
+		return v.ChatID
+	case tgbotapi.VideoConfig:
+		return v.ChatID
+	case tgbotapi.DocumentConfig:
+		return v.ChatID
+	case tgbotapi.AnimationConfig:
+		return v.ChatID
+	case tgbotapi.StickerConfig:
+		return v.ChatID
+	case tgbotapi.VideoNoteConfig:
+		return v.ChatID
+	case tgbotapi.AudioConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageMediaConfig:
+		return v.ChatID
+	case tgbotapi.DeleteMessageConfig:
+		return v.ChatID
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,22 @@
+package bot
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestChattableChatID(t *testing.T) {
+	if id := chattableChatID(tgbotapi.NewMessage(42, "hi")); id != 42 {
+		t.Errorf("expected message chat 42, got %d", id)
+	}
+	if id := chattableChatID(tgbotapi.NewPhoto(43, tgbotapi.FileID("f"))); id != 43 {
+		t.Errorf("expected photo chat 43, got %d", id)
+	}
+	if id := chattableChatID(tgbotapi.NewDeleteMessage(44, 1)); id != 44 {
+		t.Errorf("expected delete chat 44, got %d", id)
+	}
+	if id := chattableChatID(tgbotapi.NewCallback("id", "text")); id != 0 {
+		t.Errorf("expected unknown config to have no chat, got %d", id)
+	}
+}
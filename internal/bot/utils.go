@@ -28,6 +28,7 @@ func (b *Bot) sendAPI(operation string, c tgbotapi.Chattable) {
 			zap.Error(err),
 		)
 		metrics.TelegramAPIErrors.WithLabelValues(operation).Inc()
+		b.handleSendError(chattableChatID(c), err)
 	}
 }
 
@@ -49,6 +50,14 @@ func maskEmail(emailAddr string) string {
 	return name[:3] + "***@" + parts[1]
 }
 
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
+
 func escapeMarkdown(text string) string {
 	replacer := strings.NewReplacer(
 		"_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(",
@@ -60,6 +69,14 @@ func escapeMarkdown(text string) string {
 }
 
 func (b *Bot) forwardMedia(targetID int64, msg *tgbotapi.Message, captionPrefix string) {
+	if operation, cfg := mediaConfig(targetID, msg, captionPrefix); cfg != nil {
+		b.sendAPI(operation, cfg)
+	}
+}
+
+// mediaConfig builds the message that re-sends msg's media to targetID, or
+// returns a nil config when msg carries no supported media.
+func mediaConfig(targetID int64, msg *tgbotapi.Message, captionPrefix string) (string, tgbotapi.Chattable) {
 	if msg.Sticker != nil {
 		stickerCfg := tgbotapi.StickerConfig{
 			BaseFile: tgbotapi.BaseFile{
@@ -67,7 +84,7 @@ func (b *Bot) forwardMedia(targetID int64, msg *tgbotapi.Message, captionPrefix
 				File:     tgbotapi.FileID(msg.Sticker.FileID),
 			},
 		}
-		b.sendAPI("forward_sticker", stickerCfg)
+		return "forward_sticker", stickerCfg
 	} else if msg.Photo != nil {
 		photos := msg.Photo
 		photo := photos[len(photos)-1]
@@ -76,34 +93,115 @@ func (b *Bot) forwardMedia(targetID int64, msg *tgbotapi.Message, captionPrefix
 		if msg.Caption != "" {
 			photoMsg.Caption += "\n\n" + msg.Caption
 		}
-		b.sendAPI("forward_photo", photoMsg)
+		return "forward_photo", photoMsg
 	} else if msg.Voice != nil {
 		voice := tgbotapi.NewVoice(targetID, tgbotapi.FileID(msg.Voice.FileID))
 		voice.Caption = captionPrefix
-		b.sendAPI("forward_voice", voice)
+		return "forward_voice", voice
 	} else if msg.Video != nil {
 		video := tgbotapi.NewVideo(targetID, tgbotapi.FileID(msg.Video.FileID))
 		video.Caption = captionPrefix
 		if msg.Caption != "" {
 			video.Caption += "\n\n" + msg.Caption
 		}
-		b.sendAPI("forward_video", video)
+		return "forward_video", video
 	} else if msg.Document != nil {
 		doc := tgbotapi.NewDocument(targetID, tgbotapi.FileID(msg.Document.FileID))
 		doc.Caption = captionPrefix
 		if msg.Caption != "" {
 			doc.Caption += "\n\n" + msg.Caption
 		}
-		b.sendAPI("forward_document", doc)
+		return "forward_document", doc
 	} else if msg.Animation != nil {
 		anim := tgbotapi.NewAnimation(targetID, tgbotapi.FileID(msg.Animation.FileID))
-		b.sendAPI("forward_animation", anim)
+		return "forward_animation", anim
+	}
+	return "", nil
+}
+
+// withReplyMarkup attaches an inline keyboard to a config built by
+// mediaConfig.
+func withReplyMarkup(c tgbotapi.Chattable, kb tgbotapi.InlineKeyboardMarkup) tgbotapi.Chattable {
+	switch v := c.(type) {
+	case tgbotapi.StickerConfig:
+		v.ReplyMarkup = kb
+		return v
+	case tgbotapi.PhotoConfig:
+		v.ReplyMarkup = kb
+		return v
+	case tgbotapi.VoiceConfig:
+		v.ReplyMarkup = kb
+		return v
+	case tgbotapi.VideoConfig:
+		v.ReplyMarkup = kb
+		return v
+	case tgbotapi.DocumentConfig:
+		v.ReplyMarkup = kb
+		return v
+	case tgbotapi.AnimationConfig:
+		v.ReplyMarkup = kb
+		return v
+	default:
+		return c
+	}
+}
+
+// storedMedia returns the media type and file_id of msg for the circle
+// history, or empty strings when msg carries no supported media.
+func storedMedia(msg *tgbotapi.Message) (string, string) {
+	switch {
+	case msg.Sticker != nil:
+		return "sticker", msg.Sticker.FileID
+	case len(msg.Photo) > 0:
+		return "photo", msg.Photo[len(msg.Photo)-1].FileID
+	case msg.Voice != nil:
+		return "voice", msg.Voice.FileID
+	case msg.Video != nil:
+		return "video", msg.Video.FileID
+	case msg.Document != nil:
+		return "document", msg.Document.FileID
+	case msg.Animation != nil:
+		return "animation", msg.Animation.FileID
+	}
+	return "", ""
+}
+
+// storedMediaConfig re-sends media saved by storedMedia.
+func storedMediaConfig(targetID int64, mediaType, fileID, caption string) tgbotapi.Chattable {
+	file := tgbotapi.FileID(fileID)
+	switch mediaType {
+	case "sticker":
+		return tgbotapi.NewSticker(targetID, file)
+	case "photo":
+		photo := tgbotapi.NewPhoto(targetID, file)
+		photo.Caption = caption
+		return photo
+	case "voice":
+		voice := tgbotapi.NewVoice(targetID, file)
+		voice.Caption = caption
+		return voice
+	case "video":
+		video := tgbotapi.NewVideo(targetID, file)
+		video.Caption = caption
+		return video
+	case "document":
+		doc := tgbotapi.NewDocument(targetID, file)
+		doc.Caption = caption
+		return doc
+	case "animation":
+		anim := tgbotapi.NewAnimation(targetID, file)
+		anim.Caption = caption
+		return anim
 	}
+	return nil
 }
 
-func (b *Bot) isSafeMedia(ctx context.Context, msg *tgbotapi.Message) (bool, string) {
+// isSafeMedia checks the media in msg with the moderation API. The error is
+// set when the media could not be checked; safe is then true so chats keep
+// flowing, and callers that must not publish unchecked media handle it.
+func (b *Bot) isSafeMedia(ctx context.Context, msg *tgbotapi.Message) (bool, string, error) {
 	if !b.moderation.IsEnabled() {
-		return true, ""
+		return true, "", nil
 	}
 
 	var fileID string
@@ -117,7 +215,7 @@ func (b *Bot) isSafeMedia(ctx context.Context, msg *tgbotapi.Message) (bool, str
 	}
 
 	if fileID == "" {
-		return true, ""
+		return true, "", nil
 	}
 
 	url, err := b.api.GetFileDirectURL(fileID)
@@ -125,7 +223,7 @@ func (b *Bot) isSafeMedia(ctx context.Context, msg *tgbotapi.Message) (bool, str
 		logger.Warn("Failed to get file direct URL for moderation",
 			zap.Error(err),
 		)
-		return true, ""
+		return true, "", err
 	}
 
 	safe, reason, err := b.moderation.IsSafe(ctx, url)
@@ -137,5 +235,5 @@ func (b *Bot) isSafeMedia(ctx context.Context, msg *tgbotapi.Message) (bool, str
 	if !safe {
 		metrics.ModerationBlocked.Inc()
 	}
-	return safe, reason
+	return safe, reason, err
 }
@@ -45,7 +45,7 @@ func (b *Bot) handleWhisperInput(ctx context.Context, msg *tgbotapi.Message, tar
 		b.sendMessage(telegramID, "⚠️ *Peringatan:* Whisper kamu mengandung kata-kata yang tidak pantas dan telah disensor.", nil)
 	}
 
-	targets, err := b.profile.SendWhisper(ctx, telegramID, targetDept, content)
+	whisperID, targets, err := b.profile.SendWhisper(ctx, telegramID, targetDept, content)
 	if err != nil {
 		b.sendMessage(telegramID, fmt.Sprintf("⚠️ %s", err.Error()), nil)
 		return
@@ -62,6 +62,7 @@ func (b *Bot) handleWhisperInput(ctx context.Context, msg *tgbotapi.Message, tar
 		senderGender = string(user.Gender)
 	}
 
+	kb := ReportContentKeyboard(models.ContentWhisper, whisperID)
 	for _, targetID := range targets {
 		whisperMsg := fmt.Sprintf(`📢 *Whisper dari %s %s*
 
@@ -77,7 +78,7 @@ _Pesan anonim untuk jurusan %s_`,
 			escapeMarkdown(content),
 			targetDept,
 		)
-		b.sendMessage(targetID, whisperMsg, nil)
+		b.sendMessage(targetID, whisperMsg, &kb)
 	}
 
 	b.sendMessageHTML(telegramID, fmt.Sprintf("✅ <b>Whisper Terkirim!</b>\n\n📤 Dikirim ke <b>%d</b> mahasiswa %s %s",
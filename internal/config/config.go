@@ -19,6 +19,23 @@ type Config struct {
 	MaxUpdateWorkers int
 	MaxUpdateQueue   int
 
+	CircleFanoutQueue            int
+	CircleFanoutPerSecond        int
+	CircleFanoutPerChatPerMinute int
+
+	BroadcastPerSecond int
+
+	CircleHistoryLimit         int
+	CircleHistoryOnJoin        int
+	CircleHistoryRetentionDays int
+
+	CircleInactiveDays int
+
+	CircleBurstPerMinute         int
+	CircleFloodStrikes           int
+	CircleFloodMuteMinutes       int
+	CircleDuplicateWindowSeconds int
+
 	SMTPHost     string
 	SMTPPort     int
 	SMTPUsername string
@@ -43,10 +60,14 @@ type Config struct {
 	MaxReportsPerDay      int
 	MaxWhispersPerHour    int
 	MaxRepliesPerHour     int
+	MaxPollsPerDay        int
+	PollKarmaMinVotes     int
 
-	AutoBanReportCount int
+	AutoBanReportCount         int
+	ContentReportHideThreshold int
 
 	MaintenanceAccountID int64
+	CircleInviteSecret   string
 	BrevoAPIKey          string
 	SightengineAPIUser   string
 	SightengineAPISecret string
@@ -63,38 +84,54 @@ func Load() *Config {
 	}
 
 	cfg := &Config{
-		BotToken:              getEnv("BOT_TOKEN", ""),
-		CSBotToken:            getEnv("CS_BOT_TOKEN", ""),
-		BotDebug:              getEnvBool("BOT_DEBUG", false),
-		MaxUpdateWorkers:      getEnvInt("MAX_UPDATE_WORKERS", 16),
-		MaxUpdateQueue:        getEnvInt("MAX_UPDATE_QUEUE", 256),
-		SMTPHost:              getEnv("SMTP_HOST", "smtp.gmail.com"),
-		SMTPPort:              getEnvInt("SMTP_PORT", 587),
-		SMTPUsername:          getEnv("SMTP_USERNAME", ""),
-		SMTPPassword:          getEnv("SMTP_PASSWORD", ""),
-		SMTPFrom:              getEnv("SMTP_FROM", ""),
-		DBType:                getEnv("DB_TYPE", "sqlite"),
-		DBPath:                getEnv("DB_PATH", "./data/pnj_anonymous.db"),
-		DBHost:                getEnv("DB_HOST", "localhost"),
-		DBPort:                getEnv("DB_PORT", "5432"),
-		DBUser:                getEnv("DB_USER", "postgres"),
-		DBPassword:            getEnv("DB_PASSWORD", ""),
-		DBName:                getEnv("DB_NAME", "pnjbot"),
-		RedisURL:              getEnv("REDIS_URL", "localhost:6379"),
-		OTPLength:             getEnvInt("OTP_LENGTH", 6),
-		OTPExpiryMinutes:      getEnvInt("OTP_EXPIRY_MINUTES", 10),
-		MaxSearchPerMinute:    getEnvInt("MAX_SEARCH_PER_MINUTE", 5),
-		MaxConfessionsPerHour: getEnvInt("MAX_CONFESSIONS_PER_HOUR", 3),
-		MaxReportsPerDay:      getEnvInt("MAX_REPORTS_PER_DAY", 5),
-		MaxWhispersPerHour:    getEnvInt("MAX_WHISPERS_PER_HOUR", 5),
-		MaxRepliesPerHour:     getEnvInt("MAX_REPLIES_PER_HOUR", 10),
-		AutoBanReportCount:    getEnvInt("AUTO_BAN_REPORT_COUNT", 3),
-		MaintenanceAccountID:  getEnvInt64("MAINTENANCE_ID", 0),
-		BrevoAPIKey:           getEnv("BREVO_API_KEY", ""),
-		SightengineAPIUser:    getEnv("SIGHTENGINE_API_USER", ""),
-		SightengineAPISecret:  getEnv("SIGHTENGINE_API_SECRET", ""),
-		SentryDSN:             getEnv("SENTRY_DSN", ""),
-		SentryEnv:             getEnv("SENTRY_ENV", "production"),
+		BotToken:                     getEnv("BOT_TOKEN", ""),
+		CSBotToken:                   getEnv("CS_BOT_TOKEN", ""),
+		BotDebug:                     getEnvBool("BOT_DEBUG", false),
+		MaxUpdateWorkers:             getEnvInt("MAX_UPDATE_WORKERS", 16),
+		MaxUpdateQueue:               getEnvInt("MAX_UPDATE_QUEUE", 256),
+		CircleFanoutQueue:            getEnvInt("CIRCLE_FANOUT_QUEUE", 512),
+		CircleFanoutPerSecond:        getEnvInt("CIRCLE_FANOUT_PER_SECOND", 25),
+		CircleFanoutPerChatPerMinute: getEnvInt("CIRCLE_FANOUT_PER_CHAT_PER_MINUTE", 20),
+		BroadcastPerSecond:           getEnvInt("BROADCAST_PER_SECOND", 10),
+		CircleHistoryLimit:           getEnvInt("CIRCLE_HISTORY_LIMIT", 200),
+		CircleHistoryOnJoin:          getEnvInt("CIRCLE_HISTORY_ON_JOIN", 10),
+		CircleHistoryRetentionDays:   getEnvInt("CIRCLE_HISTORY_RETENTION_DAYS", 7),
+		CircleInactiveDays:           getEnvInt("CIRCLE_INACTIVE_DAYS", 30),
+		CircleBurstPerMinute:         getEnvInt("CIRCLE_BURST_PER_MINUTE", 20),
+		CircleFloodStrikes:           getEnvInt("CIRCLE_FLOOD_STRIKES", 2),
+		CircleFloodMuteMinutes:       getEnvInt("CIRCLE_FLOOD_MUTE_MINUTES", 30),
+		CircleDuplicateWindowSeconds: getEnvInt("CIRCLE_DUPLICATE_WINDOW_SECONDS", 120),
+		SMTPHost:                     getEnv("SMTP_HOST", "smtp.gmail.com"),
+		SMTPPort:                     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:                 getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:                 getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:                     getEnv("SMTP_FROM", ""),
+		DBType:                       getEnv("DB_TYPE", "sqlite"),
+		DBPath:                       getEnv("DB_PATH", "./data/pnj_anonymous.db"),
+		DBHost:                       getEnv("DB_HOST", "localhost"),
+		DBPort:                       getEnv("DB_PORT", "5432"),
+		DBUser:                       getEnv("DB_USER", "postgres"),
+		DBPassword:                   getEnv("DB_PASSWORD", ""),
+		DBName:                       getEnv("DB_NAME", "pnjbot"),
+		RedisURL:                     getEnv("REDIS_URL", "localhost:6379"),
+		OTPLength:                    getEnvInt("OTP_LENGTH", 6),
+		OTPExpiryMinutes:             getEnvInt("OTP_EXPIRY_MINUTES", 10),
+		MaxSearchPerMinute:           getEnvInt("MAX_SEARCH_PER_MINUTE", 5),
+		MaxConfessionsPerHour:        getEnvInt("MAX_CONFESSIONS_PER_HOUR", 3),
+		MaxReportsPerDay:             getEnvInt("MAX_REPORTS_PER_DAY", 5),
+		MaxWhispersPerHour:           getEnvInt("MAX_WHISPERS_PER_HOUR", 5),
+		MaxRepliesPerHour:            getEnvInt("MAX_REPLIES_PER_HOUR", 10),
+		MaxPollsPerDay:               getEnvInt("MAX_POLLS_PER_DAY", 3),
+		PollKarmaMinVotes:            getEnvInt("POLL_KARMA_MIN_VOTES", 5),
+		AutoBanReportCount:           getEnvInt("AUTO_BAN_REPORT_COUNT", 3),
+		ContentReportHideThreshold:   getEnvInt("CONTENT_REPORT_HIDE_THRESHOLD", 3),
+		MaintenanceAccountID:         getEnvInt64("MAINTENANCE_ID", 0),
+		CircleInviteSecret:           getEnv("CIRCLE_INVITE_SECRET", ""),
+		BrevoAPIKey:                  getEnv("BREVO_API_KEY", ""),
+		SightengineAPIUser:           getEnv("SIGHTENGINE_API_USER", ""),
+		SightengineAPISecret:         getEnv("SIGHTENGINE_API_SECRET", ""),
+		SentryDSN:                    getEnv("SENTRY_DSN", ""),
+		SentryEnv:                    getEnv("SENTRY_ENV", "production"),
 	}
 
 	if cfg.BotToken == "" {
@@ -107,6 +144,18 @@ func Load() *Config {
 	if cfg.MaxUpdateQueue < 1 {
 		cfg.MaxUpdateQueue = 1
 	}
+	if cfg.CircleFanoutQueue < 1 {
+		cfg.CircleFanoutQueue = 1
+	}
+	if cfg.CircleFanoutPerSecond < 1 {
+		cfg.CircleFanoutPerSecond = 1
+	}
+	if cfg.CircleFanoutPerChatPerMinute < 1 {
+		cfg.CircleFanoutPerChatPerMinute = 1
+	}
+	if cfg.BroadcastPerSecond < 1 {
+		cfg.BroadcastPerSecond = 1
+	}
 
 	cfg.validate()
 
@@ -145,6 +194,10 @@ func (cfg *Config) validate() {
 		cfg.AutoBanReportCount = 3
 		warnings = append(warnings, "AUTO_BAN_REPORT_COUNT invalid, defaulting to 3")
 	}
+	if cfg.ContentReportHideThreshold <= 0 {
+		cfg.ContentReportHideThreshold = 3
+		warnings = append(warnings, "CONTENT_REPORT_HIDE_THRESHOLD invalid, defaulting to 3")
+	}
 
 	if cfg.OTPLength < 4 || cfg.OTPLength > 8 {
 		cfg.OTPLength = 6
@@ -13,6 +13,7 @@ import (
 
 	"github.com/pnj-anonymous-bot/internal/config"
 	"github.com/pnj-anonymous-bot/internal/logger"
+	"github.com/pnj-anonymous-bot/internal/models"
 	"github.com/pnj-anonymous-bot/internal/service"
 	"go.uber.org/zap"
 
@@ -23,11 +24,12 @@ type CSBot struct {
 	api        *tgbotapi.BotAPI
 	cfg        *config.Config
 	svc        service.CSSessionManager
+	access     service.AccessManager
 	startedAt  time.Time
 	background sync.WaitGroup
 }
 
-func New(cfg *config.Config, svc service.CSSessionManager) (*CSBot, error) {
+func New(cfg *config.Config, svc service.CSSessionManager, access service.AccessManager) (*CSBot, error) {
 	api, err := tgbotapi.NewBotAPI(cfg.CSBotToken)
 	if err != nil {
 		return nil, err
@@ -39,6 +41,7 @@ func New(cfg *config.Config, svc service.CSSessionManager) (*CSBot, error) {
 		api:       api,
 		cfg:       cfg,
 		svc:       svc,
+		access:    access,
 		startedAt: time.Now(),
 	}, nil
 }
@@ -48,7 +51,6 @@ func (b *CSBot) Start(ctx context.Context) {
 
 	logger.Info("🛠️ CS Bot authorized",
 		zap.String("username", username),
-		zap.Int64("admin_id", b.cfg.MaintenanceAccountID),
 	)
 
 	b.background.Add(1)
@@ -105,8 +107,9 @@ func (b *CSBot) startTimeoutWorker(ctx context.Context) {
 			}
 
 			for _, userID := range timedOutUsers {
-				b.endSession(ctx, userID, "⏰ <b>Sesi berakhir.</b> Tidak ada aktivitas selama 5 menit.")
-				b.processQueue(ctx)
+				agentID, _ := b.svc.GetActiveSessionByUser(ctx, userID)
+				b.endSession(ctx, userID, agentID, "⏰ <b>Sesi berakhir.</b> Tidak ada aktivitas selama 5 menit.")
+				b.processQueue(ctx, agentID)
 			}
 		}
 	}
@@ -115,15 +118,15 @@ func (b *CSBot) startTimeoutWorker(ctx context.Context) {
 func (b *CSBot) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 	telegramID := msg.From.ID
 
-	if telegramID == b.cfg.MaintenanceAccountID {
+	if b.access.Can(ctx, telegramID, models.PermCustomerService) {
 		userID, _ := b.svc.GetActiveSessionByAdmin(ctx, telegramID)
 		if userID > 0 {
 			_ = b.svc.UpdateSessionActivity(ctx, userID)
 			if msg.IsCommand() && (msg.Command() == "stop" || msg.Command() == "end") {
-				b.handleStop(ctx, userID)
+				b.handleStop(ctx, userID, telegramID)
 				return
 			}
-			b.handleAdminReply(userID, msg)
+			b.handleAdminReply(userID, telegramID, msg)
 			return
 		}
 
@@ -131,8 +134,12 @@ func (b *CSBot) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 			switch msg.Command() {
 			case "start", "help":
 				b.handleHelp(telegramID)
+			case "next":
+				if !b.processQueue(ctx, telegramID) {
+					b.sendMessage(telegramID, "📭 Antrean kosong.")
+				}
 			default:
-				b.sendMessage(telegramID, "💡 Kamu adalah Admin. Gunakan /chat pada akun User untuk mencoba, lalu balas dari sini.")
+				b.sendMessage(telegramID, "💡 Kamu adalah Agen CS. Ketik /next untuk melayani pengguna berikutnya di antrean, lalu balas dari sini.")
 			}
 		}
 		return
@@ -142,7 +149,7 @@ func (b *CSBot) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 	if adminID > 0 {
 		_ = b.svc.UpdateSessionActivity(ctx, telegramID)
 		if msg.IsCommand() && msg.Command() == "stop" {
-			b.handleStop(ctx, telegramID)
+			b.handleStop(ctx, telegramID, adminID)
 			return
 		}
 		b.forwardToAdmin(telegramID, adminID, msg)
@@ -178,10 +185,25 @@ Selamat datang di layanan bantuan resmi PNJ Anonymous Bot.
 	b.sendMessage(telegramID, helpText)
 }
 
+// idleAgent returns a CS agent without an active session, or 0 when every
+// agent is busy.
+func (b *CSBot) idleAgent(ctx context.Context) int64 {
+	agents, err := b.access.StaffWith(ctx, models.PermCustomerService)
+	if err != nil {
+		logger.Error("❌ Error getting CS agents", zap.Error(err))
+		return 0
+	}
+	for _, agentID := range agents {
+		if userID, _ := b.svc.GetActiveSessionByAdmin(ctx, agentID); userID == 0 {
+			return agentID
+		}
+	}
+	return 0
+}
+
 func (b *CSBot) handleChat(ctx context.Context, telegramID int64) {
-	activeUserID, _ := b.svc.GetActiveSessionByAdmin(ctx, b.cfg.MaintenanceAccountID)
-	if activeUserID == 0 {
-		b.startSession(ctx, telegramID)
+	if agentID := b.idleAgent(ctx); agentID != 0 {
+		b.startSession(ctx, telegramID, agentID)
 	} else {
 		_ = b.svc.JoinQueue(ctx, telegramID)
 		pos, _ := b.svc.GetQueuePosition(ctx, telegramID)
@@ -189,38 +211,47 @@ func (b *CSBot) handleChat(ctx context.Context, telegramID int64) {
 	}
 }
 
-func (b *CSBot) handleStop(ctx context.Context, userID int64) {
-	b.endSession(ctx, userID, "⏹️ <b>Sesi chat telah diakhiri.</b> Terima kasih telah menghubungi kami.")
-	b.processQueue(ctx)
+func (b *CSBot) handleStop(ctx context.Context, userID, agentID int64) {
+	b.endSession(ctx, userID, agentID, "⏹️ <b>Sesi chat telah diakhiri.</b> Terima kasih telah menghubungi kami.")
+	b.processQueue(ctx, agentID)
 }
 
-func (b *CSBot) startSession(ctx context.Context, userID int64) {
+func (b *CSBot) startSession(ctx context.Context, userID, agentID int64) {
 	logger.Info("🚀 Starting CS session",
 		zap.Int64("user_id", userID),
-		zap.Int64("admin_id", b.cfg.MaintenanceAccountID),
+		zap.Int64("admin_id", agentID),
 	)
 	_ = b.svc.LeaveQueue(ctx, userID)
-	err := b.svc.CreateSession(ctx, userID, b.cfg.MaintenanceAccountID)
+	err := b.svc.CreateSession(ctx, userID, agentID)
 	if err != nil {
 		logger.Error("❌ Error creating CS session", zap.Error(err))
 		return
 	}
 
 	b.sendMessage(userID, "🎧 <b>Terhubung dengan agen!</b>\nSilakan sampaikan pertanyaan atau kendala kamu.")
-	b.sendMessage(b.cfg.MaintenanceAccountID, fmt.Sprintf("📩 <b>SESSION BARU</b>\nUser: %d\n\nSilakan balas pesan untuk memulai percakapan.", userID))
+	b.sendMessage(agentID, fmt.Sprintf("📩 <b>SESSION BARU</b>\nUser: %d\n\nSilakan balas pesan untuk memulai percakapan.", userID))
 }
 
-func (b *CSBot) endSession(ctx context.Context, userID int64, message string) {
+func (b *CSBot) endSession(ctx context.Context, userID, agentID int64, message string) {
 	_ = b.svc.EndSession(ctx, userID)
 	b.sendMessage(userID, message)
-	b.sendMessage(b.cfg.MaintenanceAccountID, fmt.Sprintf("🛑 <b>Sesi dengan user %d berakhir.</b>", userID))
+	if agentID != 0 {
+		b.sendMessage(agentID, fmt.Sprintf("🛑 <b>Sesi dengan user %d berakhir.</b>", userID))
+	}
 }
 
-func (b *CSBot) processQueue(ctx context.Context) {
+// processQueue hands the next queued user to agentID and reports whether
+// anyone was waiting.
+func (b *CSBot) processQueue(ctx context.Context, agentID int64) bool {
+	if agentID == 0 || !b.access.Can(ctx, agentID, models.PermCustomerService) {
+		return false
+	}
 	nextUserID, err := b.svc.GetNextInQueue(ctx)
-	if err == nil && nextUserID > 0 {
-		b.startSession(ctx, nextUserID)
+	if err != nil || nextUserID == 0 {
+		return false
 	}
+	b.startSession(ctx, nextUserID, agentID)
+	return true
 }
 
 func (b *CSBot) forwardToAdmin(userID, adminID int64, msg *tgbotapi.Message) {
@@ -233,13 +264,13 @@ func (b *CSBot) forwardToAdmin(userID, adminID int64, msg *tgbotapi.Message) {
 	b.sendMessage(adminID, text)
 }
 
-func (b *CSBot) handleAdminReply(userID int64, adminMsg *tgbotapi.Message) {
+func (b *CSBot) handleAdminReply(userID, agentID int64, adminMsg *tgbotapi.Message) {
 	reply := tgbotapi.NewMessage(userID, fmt.Sprintf("🎧 <b>Customer Service:</b>\n\n%s", adminMsg.Text))
 	reply.ParseMode = "HTML"
 
 	_, err := b.api.Send(reply)
 	if err != nil {
-		b.sendMessage(b.cfg.MaintenanceAccountID, "❌ Gagal mengirim balasan ke user.")
+		b.sendMessage(agentID, "❌ Gagal mengirim balasan ke user.")
 	}
 }
 
@@ -42,16 +42,18 @@ func (d *DB) GetUserAchievementsContext(ctx context.Context, telegramID int64) (
 	return achievements, err
 }
 
+// GetUserPollCountContext counts the user's polls that reached enough voters
+// to earn karma, so throwaway polls don't count toward POLL_MAKER.
 func (d *DB) GetUserPollCountContext(ctx context.Context, telegramID int64) (int, error) {
 	var count int
-	builder := d.Builder.Select("COUNT(*)").From("polls").Where("author_id = ?", telegramID)
+	builder := d.Builder.Select("COUNT(*)").From("polls").Where("author_id = ? AND karma_awarded = TRUE", telegramID)
 	err := d.GetBuilderContext(ctx, &count, builder)
 	return count, err
 }
 
 func (d *DB) GetUserMaxConfessionReactionsContext(ctx context.Context, telegramID int64) (int, error) {
 	var maxReactions int
-	builder := d.Builder.Select("COALESCE(MAX(like_count), 0)").From("confessions").Where("author_id = ?", telegramID)
+	builder := d.Builder.Select("COALESCE(MAX(like_count), 0)").From("confessions").Where("author_id = ? AND is_deleted = FALSE", telegramID)
 	err := d.GetBuilderContext(ctx, &maxReactions, builder)
 	if err == sql.ErrNoRows {
 		return 0, nil
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+// AddAuditEntry appends to the admin audit log. The table rejects updates
+// and deletes.
+func (d *DB) AddAuditEntry(ctx context.Context, e *models.AuditEntry) error {
+	builder := d.Builder.Insert("admin_audit").
+		Columns("actor_id", "action", "target_id", "details", "created_at").
+		Values(e.ActorID, string(e.Action), e.TargetID, e.Details, time.Now())
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to add audit entry: %w", err)
+	}
+	return nil
+}
+
+// GetAuditEntries returns the newest entries first, optionally limited to
+// those where userID is the actor or the target. A limit of 0 returns every
+// matching entry.
+func (d *DB) GetAuditEntries(ctx context.Context, userID int64, limit int) ([]*models.AuditEntry, error) {
+	builder := d.Builder.Select("id", "actor_id", "action", "target_id", "details", "created_at").
+		From("admin_audit").
+		OrderBy("id DESC")
+	if userID != 0 {
+		builder = builder.Where(squirrel.Or{squirrel.Eq{"actor_id": userID}, squirrel.Eq{"target_id": userID}})
+	}
+	if limit > 0 {
+		builder = builder.Limit(uint64(limit))
+	}
+
+	var entries []*models.AuditEntry
+	if err := d.SelectBuilderContext(ctx, &entries, builder); err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+	return entries, nil
+}
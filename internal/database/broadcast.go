@@ -0,0 +1,273 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+var broadcastColumns = []string{
+	"id", "kind", "content", "poll_id", "status",
+	"department", "year", "gender", "min_level", "active_days",
+	"scheduled_at", "created_by", "created_at", "finished_at",
+}
+
+var unfinishedBroadcastStatuses = []string{
+	string(models.BroadcastDraft), string(models.BroadcastScheduled), string(models.BroadcastRunning),
+}
+
+// broadcastAudience matches the verified, unbanned and reachable users in a
+// segment.
+func broadcastAudience(seg models.BroadcastSegment, now time.Time) squirrel.And {
+	where := squirrel.And{squirrel.Eq{"is_verified": true, "is_banned": false, "is_reachable": true}}
+	if seg.Department != "" {
+		where = append(where, squirrel.Eq{"department": string(seg.Department)})
+	}
+	if seg.Year != 0 {
+		where = append(where, squirrel.Eq{"year": seg.Year})
+	}
+	if seg.Gender != "" {
+		where = append(where, squirrel.Eq{"gender": string(seg.Gender)})
+	}
+	if seg.MinLevel > 0 {
+		where = append(where, squirrel.GtOrEq{"level": seg.MinLevel})
+	}
+	if seg.ActiveDays > 0 {
+		where = append(where, squirrel.GtOrEq{"last_active_at": now.AddDate(0, 0, -seg.ActiveDays)})
+	}
+	return where
+}
+
+// broadcastRecipients matches the users a broadcast is delivered to: the
+// segment for announcements and polls, and for poll results everyone who
+// voted through the bot or received a native poll.
+func broadcastRecipients(bc *models.Broadcast, now time.Time) squirrel.Sqlizer {
+	if bc.Kind != models.BroadcastPollResults || bc.PollID == nil {
+		return broadcastAudience(bc.BroadcastSegment, now)
+	}
+	return squirrel.And{
+		squirrel.Eq{"is_banned": false, "is_reachable": true},
+		squirrel.Or{
+			squirrel.Expr("telegram_id IN (SELECT telegram_id FROM poll_votes WHERE poll_id = ?)", *bc.PollID),
+			squirrel.Expr("telegram_id IN (SELECT chat_id FROM telegram_polls WHERE poll_id = ?)", *bc.PollID),
+		},
+	}
+}
+
+// CreateBroadcast stores a broadcast job without recipients; they are
+// snapshotted by ActivateBroadcast.
+func (d *DB) CreateBroadcast(ctx context.Context, bc *models.Broadcast) (int64, error) {
+	status := bc.Status
+	if status == "" {
+		status = models.BroadcastDraft
+	}
+	builder := d.Builder.Insert("broadcasts").
+		Columns("kind", "content", "poll_id", "status",
+			"department", "year", "gender", "min_level", "active_days",
+			"scheduled_at", "created_by", "created_at").
+		Values(string(bc.Kind), bc.Content, bc.PollID, string(status),
+			string(bc.Department), bc.Year, string(bc.Gender), bc.MinLevel, bc.ActiveDays,
+			bc.ScheduledAt, bc.CreatedBy, time.Now())
+
+	id, err := d.InsertGetIDContext(ctx, builder, "id")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create broadcast: %w", err)
+	}
+	return id, nil
+}
+
+func (d *DB) CountBroadcastAudience(ctx context.Context, seg models.BroadcastSegment) (int, error) {
+	var count int
+	builder := d.Builder.Select("COUNT(*)").From("users").Where(broadcastAudience(seg, time.Now()))
+	if err := d.GetBuilderContext(ctx, &count, builder); err != nil {
+		return 0, fmt.Errorf("failed to count broadcast audience: %w", err)
+	}
+	return count, nil
+}
+
+// ActivateBroadcast moves a draft or scheduled broadcast to running and
+// snapshots its segment as pending recipients. It returns the number of
+// recipients and whether the broadcast could still be activated.
+func (d *DB) ActivateBroadcast(ctx context.Context, bc *models.Broadcast) (int, bool, error) {
+	tx, err := d.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query, args, _ := d.Builder.Update("broadcasts").
+		Set("status", string(models.BroadcastRunning)).
+		Where(squirrel.Eq{"id": bc.ID, "status": []string{string(models.BroadcastDraft), string(models.BroadcastScheduled)}}).
+		ToSql()
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to activate broadcast: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, false, nil
+	}
+
+	recipients := d.Builder.Select().
+		Column(squirrel.Expr("?", bc.ID)).
+		Column("telegram_id").
+		Column(squirrel.Expr("?", models.RecipientPending)).
+		From("users").
+		Where(broadcastRecipients(bc, time.Now()))
+	query, args, err = d.Builder.Insert("broadcast_recipients").
+		Columns("broadcast_id", "telegram_id", "status").
+		Select(recipients).
+		ToSql()
+	if err != nil {
+		return 0, false, err
+	}
+	res, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to snapshot broadcast recipients: %w", err)
+	}
+	count, _ := res.RowsAffected()
+	return int(count), true, tx.Commit()
+}
+
+// ScheduleBroadcast moves a confirmed draft to scheduled. It reports whether
+// the broadcast was still a draft.
+func (d *DB) ScheduleBroadcast(ctx context.Context, id int64) (bool, error) {
+	builder := d.Builder.Update("broadcasts").
+		Set("status", string(models.BroadcastScheduled)).
+		Where("id = ? AND status = ?", id, string(models.BroadcastDraft))
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return false, fmt.Errorf("failed to schedule broadcast: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (d *DB) GetDueBroadcasts(ctx context.Context, now time.Time) ([]*models.Broadcast, error) {
+	builder := d.Builder.Select(broadcastColumns...).From("broadcasts").
+		Where("status = ? AND scheduled_at <= ?", string(models.BroadcastScheduled), now).
+		OrderBy("scheduled_at", "id")
+
+	var broadcasts []*models.Broadcast
+	if err := d.SelectBuilderContext(ctx, &broadcasts, builder); err != nil {
+		return nil, fmt.Errorf("failed to get due broadcasts: %w", err)
+	}
+	return broadcasts, nil
+}
+
+func (d *DB) GetBroadcast(ctx context.Context, id int64) (*models.Broadcast, error) {
+	var bc models.Broadcast
+	builder := d.Builder.Select(broadcastColumns...).From("broadcasts").Where("id = ?", id)
+
+	err := d.GetBuilderContext(ctx, &bc, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast: %w", err)
+	}
+	return &bc, nil
+}
+
+func (d *DB) GetRecentBroadcasts(ctx context.Context, limit int) ([]*models.Broadcast, error) {
+	builder := d.Builder.Select(broadcastColumns...).From("broadcasts").
+		OrderBy("id DESC").Limit(uint64(limit))
+
+	var broadcasts []*models.Broadcast
+	if err := d.SelectBuilderContext(ctx, &broadcasts, builder); err != nil {
+		return nil, fmt.Errorf("failed to get broadcasts: %w", err)
+	}
+	return broadcasts, nil
+}
+
+// GetNextRunningBroadcast returns the oldest broadcast that still has to be
+// delivered, or nil.
+func (d *DB) GetNextRunningBroadcast(ctx context.Context) (*models.Broadcast, error) {
+	var bc models.Broadcast
+	builder := d.Builder.Select(broadcastColumns...).From("broadcasts").
+		Where("status = ?", string(models.BroadcastRunning)).
+		OrderBy("id").Limit(1)
+
+	err := d.GetBuilderContext(ctx, &bc, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get running broadcast: %w", err)
+	}
+	return &bc, nil
+}
+
+func (d *DB) GetPendingRecipients(ctx context.Context, broadcastID int64, limit int) ([]int64, error) {
+	builder := d.Builder.Select("telegram_id").From("broadcast_recipients").
+		Where("broadcast_id = ? AND status = ?", broadcastID, models.RecipientPending).
+		OrderBy("telegram_id").Limit(uint64(limit))
+
+	var ids []int64
+	if err := d.SelectBuilderContext(ctx, &ids, builder); err != nil {
+		return nil, fmt.Errorf("failed to get pending recipients: %w", err)
+	}
+	return ids, nil
+}
+
+func (d *DB) MarkBroadcastRecipient(ctx context.Context, broadcastID, telegramID int64, status, errText string) error {
+	builder := d.Builder.Update("broadcast_recipients").
+		Set("status", status).
+		Set("error", errText).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("updated_at", time.Now()).
+		Where("broadcast_id = ? AND telegram_id = ?", broadcastID, telegramID)
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to mark broadcast recipient: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) GetBroadcastProgress(ctx context.Context, broadcastID int64) (models.BroadcastProgress, error) {
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	builder := d.Builder.Select("status", "COUNT(*) AS count").From("broadcast_recipients").
+		Where("broadcast_id = ?", broadcastID).
+		GroupBy("status")
+
+	var progress models.BroadcastProgress
+	if err := d.SelectBuilderContext(ctx, &rows, builder); err != nil {
+		return progress, fmt.Errorf("failed to get broadcast progress: %w", err)
+	}
+	for _, r := range rows {
+		switch r.Status {
+		case models.RecipientPending:
+			progress.Pending = r.Count
+		case models.RecipientSent:
+			progress.Sent = r.Count
+		case models.RecipientFailed:
+			progress.Failed = r.Count
+		case models.RecipientBlocked:
+			progress.Blocked = r.Count
+		}
+	}
+	return progress, nil
+}
+
+// FinishBroadcast moves an unfinished broadcast to its final status. It
+// reports whether the broadcast was still unfinished.
+func (d *DB) FinishBroadcast(ctx context.Context, id int64, status models.BroadcastStatus) (bool, error) {
+	builder := d.Builder.Update("broadcasts").
+		Set("status", string(status)).
+		Set("finished_at", time.Now()).
+		Where(squirrel.Eq{"id": id, "status": unfinishedBroadcastStatuses})
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return false, fmt.Errorf("failed to finish broadcast: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
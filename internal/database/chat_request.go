@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+var chatRequestColumns = []string{"id", "confession_id", "requester_id", "author_id", "status", "created_at", "responded_at"}
+
+func (d *DB) CreateChatRequest(ctx context.Context, confessionID, requesterID, authorID int64) (*models.ChatRequest, error) {
+	now := time.Now()
+	builder := d.Builder.Insert("chat_requests").
+		Columns("confession_id", "requester_id", "author_id", "status", "created_at").
+		Values(confessionID, requesterID, authorID, string(models.ChatRequestPending), now)
+
+	id, err := d.InsertGetIDContext(ctx, builder, "id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat request: %w", err)
+	}
+
+	return &models.ChatRequest{
+		ID:           id,
+		ConfessionID: confessionID,
+		RequesterID:  requesterID,
+		AuthorID:     authorID,
+		Status:       models.ChatRequestPending,
+		CreatedAt:    now,
+	}, nil
+}
+
+func (d *DB) GetChatRequest(ctx context.Context, requestID int64) (*models.ChatRequest, error) {
+	req := &models.ChatRequest{}
+	builder := d.Builder.Select(chatRequestColumns...).From("chat_requests").Where("id = ?", requestID)
+
+	err := d.GetBuilderContext(ctx, req, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat request: %w", err)
+	}
+	return req, nil
+}
+
+func (d *DB) HasPendingChatRequest(ctx context.Context, confessionID, requesterID int64, since time.Time) (bool, error) {
+	var count int
+	builder := d.Builder.Select("COUNT(*)").From("chat_requests").
+		Where("confession_id = ? AND requester_id = ? AND status = ? AND created_at > ?",
+			confessionID, requesterID, string(models.ChatRequestPending), since)
+
+	err := d.GetBuilderContext(ctx, &count, builder)
+	return count > 0, err
+}
+
+func (d *DB) UpdateChatRequestStatus(ctx context.Context, requestID int64, status models.ChatRequestStatus) error {
+	builder := d.Builder.Update("chat_requests").
+		Set("status", string(status)).
+		Set("responded_at", time.Now()).
+		Where("id = ? AND status = ?", requestID, string(models.ChatRequestPending))
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return fmt.Errorf("failed to update chat request: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -12,11 +12,20 @@ import (
 	"go.uber.org/zap"
 )
 
+var confessionColumns = []string{"id", "author_id", "content", "department", "like_count",
+	"photo_file_id", "status", "is_deleted", "deleted_at", "edited_at", "is_hidden", "created_at"}
+
+const visibleConfession = "is_deleted = FALSE AND is_hidden = FALSE AND status = 'published'"
+
 func (d *DB) CreateConfession(ctx context.Context, authorID int64, content, department string) (*models.Confession, error) {
+	return d.CreatePhotoConfession(ctx, authorID, content, department, "", models.ConfessionPublished)
+}
+
+func (d *DB) CreatePhotoConfession(ctx context.Context, authorID int64, content, department, photoFileID string, status models.ConfessionStatus) (*models.Confession, error) {
 	now := time.Now()
 	builder := d.Builder.Insert("confessions").
-		Columns("author_id", "content", "department", "created_at").
-		Values(authorID, content, department, now)
+		Columns("author_id", "content", "department", "photo_file_id", "status", "created_at").
+		Values(authorID, content, department, photoFileID, string(status), now)
 
 	id, err := d.InsertGetIDContext(ctx, builder, "id")
 	if err != nil {
@@ -24,19 +33,38 @@ func (d *DB) CreateConfession(ctx context.Context, authorID int64, content, depa
 	}
 
 	return &models.Confession{
-		ID:         id,
-		AuthorID:   authorID,
-		Content:    content,
-		Department: department,
-		LikeCount:  0,
-		CreatedAt:  now,
+		ID:          id,
+		AuthorID:    authorID,
+		Content:     content,
+		Department:  department,
+		LikeCount:   0,
+		PhotoFileID: photoFileID,
+		Status:      status,
+		CreatedAt:   now,
 	}, nil
 }
 
 func (d *DB) GetConfession(ctx context.Context, id int64) (*models.Confession, error) {
 	c := &models.Confession{}
-	builder := d.Builder.Select("id", "author_id", "content", "department", "like_count", "created_at").
-		From("confessions").Where("id = ?", id)
+	builder := d.Builder.Select(confessionColumns...).
+		From("confessions").Where("id = ?", id).Where(visibleConfession)
+
+	err := d.GetBuilderContext(ctx, c, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get confession: %w", err)
+	}
+	return c, nil
+}
+
+// GetAuthorConfession returns one of the author's own confessions whether it
+// is published, pending or hidden. Deleted confessions are not returned.
+func (d *DB) GetAuthorConfession(ctx context.Context, id, authorID int64) (*models.Confession, error) {
+	c := &models.Confession{}
+	builder := d.Builder.Select(confessionColumns...).
+		From("confessions").Where("id = ? AND author_id = ? AND is_deleted = FALSE", id, authorID)
 
 	err := d.GetBuilderContext(ctx, c, builder)
 	if err == sql.ErrNoRows {
@@ -48,13 +76,35 @@ func (d *DB) GetConfession(ctx context.Context, id int64) (*models.Confession, e
 	return c, nil
 }
 
+// GetAdjacentPhotoConfession returns the visible photo confession right
+// before (older) or after id, or nil at either end of the feed.
+func (d *DB) GetAdjacentPhotoConfession(ctx context.Context, id int64, older bool) (*models.Confession, error) {
+	builder := d.Builder.Select(confessionColumns...).
+		From("confessions").Where(visibleConfession).Where("photo_file_id <> ''").Limit(1)
+	if older {
+		builder = builder.Where("id < ?", id).OrderBy("id DESC")
+	} else {
+		builder = builder.Where("id > ?", id).OrderBy("id ASC")
+	}
+
+	c := &models.Confession{}
+	err := d.GetBuilderContext(ctx, c, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photo confession: %w", err)
+	}
+	return c, nil
+}
+
 func (d *DB) GetLatestConfessions(ctx context.Context, limit int) ([]*models.Confession, error) {
 	var safeLimit uint64
 	if limit > 0 {
 		safeLimit = uint64(limit)
 	}
-	builder := d.Builder.Select("id", "author_id", "content", "department", "like_count", "created_at").
-		From("confessions").OrderBy("created_at DESC").Limit(safeLimit)
+	builder := d.Builder.Select(confessionColumns...).
+		From("confessions").Where(visibleConfession).OrderBy("created_at DESC").Limit(safeLimit)
 
 	var confessions []*models.Confession
 	err := d.SelectBuilderContext(ctx, &confessions, builder)
@@ -64,6 +114,105 @@ func (d *DB) GetLatestConfessions(ctx context.Context, limit int) ([]*models.Con
 	return confessions, nil
 }
 
+func (d *DB) GetConfessionsByAuthor(ctx context.Context, authorID int64, limit int) ([]*models.Confession, error) {
+	var safeLimit uint64
+	if limit > 0 {
+		safeLimit = uint64(limit)
+	}
+	builder := d.Builder.Select(confessionColumns...).
+		From("confessions").
+		Where("author_id = ? AND is_deleted = FALSE", authorID).
+		OrderBy("created_at DESC").Limit(safeLimit)
+
+	var confessions []*models.Confession
+	err := d.SelectBuilderContext(ctx, &confessions, builder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get author confessions: %w", err)
+	}
+	return confessions, nil
+}
+
+// UpdateConfessionContent rewrites a confession that nobody has reported yet,
+// so reported text stays as evidence for the review queue.
+func (d *DB) UpdateConfessionContent(ctx context.Context, id, authorID int64, content string) error {
+	builder := d.Builder.Update("confessions").
+		Set("content", content).
+		Set("edited_at", time.Now()).
+		Where("id = ? AND author_id = ? AND is_deleted = FALSE", id, authorID).
+		Where("NOT EXISTS (SELECT 1 FROM content_reports WHERE content_type = ? AND content_id = confessions.id)", string(models.ContentConfession))
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return fmt.Errorf("failed to update confession: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (d *DB) SoftDeleteConfession(ctx context.Context, id, authorID int64) error {
+	builder := d.Builder.Update("confessions").
+		Set("is_deleted", true).
+		Set("deleted_at", time.Now()).
+		Where("id = ? AND author_id = ? AND is_deleted = FALSE", id, authorID)
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return fmt.Errorf("failed to delete confession: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (d *DB) GetPendingConfession(ctx context.Context, id int64) (*models.Confession, error) {
+	c := &models.Confession{}
+	builder := d.Builder.Select(confessionColumns...).From("confessions").
+		Where("id = ? AND status = ? AND is_deleted = FALSE", id, string(models.ConfessionPending))
+
+	err := d.GetBuilderContext(ctx, c, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending confession: %w", err)
+	}
+	return c, nil
+}
+
+func (d *DB) GetPendingConfessions(ctx context.Context, limit int) ([]*models.Confession, error) {
+	var safeLimit uint64
+	if limit > 0 {
+		safeLimit = uint64(limit)
+	}
+	builder := d.Builder.Select(confessionColumns...).From("confessions").
+		Where("status = ? AND is_deleted = FALSE", string(models.ConfessionPending)).
+		OrderBy("created_at ASC").Limit(safeLimit)
+
+	var confessions []*models.Confession
+	if err := d.SelectBuilderContext(ctx, &confessions, builder); err != nil {
+		return nil, fmt.Errorf("failed to get pending confessions: %w", err)
+	}
+	return confessions, nil
+}
+
+func (d *DB) ReviewConfession(ctx context.Context, id int64, status models.ConfessionStatus) error {
+	builder := d.Builder.Update("confessions").
+		Set("status", string(status)).
+		Where("id = ? AND status = ?", id, string(models.ConfessionPending))
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return fmt.Errorf("failed to review confession: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 func (d *DB) AddConfessionReaction(ctx context.Context, confessionID, telegramID int64, reaction string) error {
 	builder := d.Builder.Insert("confession_reactions").
 		Columns("confession_id", "telegram_id", "reaction", "created_at").
@@ -141,7 +290,7 @@ func (d *DB) GetUserConfessionCount(ctx context.Context, telegramID int64, since
 
 func (d *DB) GetTotalConfessions(ctx context.Context, telegramID int64) (int, error) {
 	var count int
-	builder := d.Builder.Select("COUNT(*)").From("confessions").Where("author_id = ?", telegramID)
+	builder := d.Builder.Select("COUNT(*)").From("confessions").Where("author_id = ? AND is_deleted = FALSE", telegramID)
 
 	err := d.GetBuilderContext(ctx, &count, builder)
 	return count, err
@@ -163,7 +312,7 @@ func (d *DB) CreateConfessionReply(ctx context.Context, confessionID, authorID i
 
 func (d *DB) GetConfessionReplies(ctx context.Context, confessionID int64) ([]*models.ConfessionReply, error) {
 	builder := d.Builder.Select("id", "confession_id", "author_id", "content", "created_at").
-		From("confession_replies").Where("confession_id = ?", confessionID).OrderBy("created_at ASC")
+		From("confession_replies").Where("confession_id = ? AND is_hidden = FALSE", confessionID).OrderBy("created_at ASC")
 
 	var replies []*models.ConfessionReply
 	err := d.SelectBuilderContext(ctx, &replies, builder)
@@ -172,7 +321,7 @@ func (d *DB) GetConfessionReplies(ctx context.Context, confessionID int64) ([]*m
 
 func (d *DB) GetConfessionReplyCount(ctx context.Context, confessionID int64) (int, error) {
 	var count int
-	builder := d.Builder.Select("COUNT(*)").From("confession_replies").Where("confession_id = ?", confessionID)
+	builder := d.Builder.Select("COUNT(*)").From("confession_replies").Where("confession_id = ? AND is_hidden = FALSE", confessionID)
 
 	err := d.GetBuilderContext(ctx, &count, builder)
 	return count, err
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+const maxSearchTerms = 8
+
+func searchTerms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(fields) > maxSearchTerms {
+		fields = fields[:maxSearchTerms]
+	}
+	return fields
+}
+
+func (d *DB) SearchConfessions(ctx context.Context, query string, limit int) ([]*models.Confession, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var match squirrel.Sqlizer
+	if d.DBType == "postgres" {
+		prefixes := make([]string, len(terms))
+		for i, t := range terms {
+			prefixes[i] = t + ":*"
+		}
+		match = squirrel.Expr("search_vector @@ to_tsquery('simple', ?)", strings.Join(prefixes, " & "))
+	} else {
+		prefixes := make([]string, len(terms))
+		for i, t := range terms {
+			prefixes[i] = `"` + t + `"*`
+		}
+		match = squirrel.Expr("id IN (SELECT rowid FROM confessions_fts WHERE confessions_fts MATCH ?)", strings.Join(prefixes, " "))
+	}
+
+	var safeLimit uint64
+	if limit > 0 {
+		safeLimit = uint64(limit)
+	}
+	builder := d.Builder.Select(confessionColumns...).
+		From("confessions").
+		Where(match).
+		Where(visibleConfession).
+		OrderBy("created_at DESC").Limit(safeLimit)
+
+	var confessions []*models.Confession
+	if err := d.SelectBuilderContext(ctx, &confessions, builder); err != nil {
+		return nil, fmt.Errorf("failed to search confessions: %w", err)
+	}
+	return confessions, nil
+}
+
+func (d *DB) SetConfessionTags(ctx context.Context, confessionID int64, tags []string) error {
+	tx, err := d.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query, args, err := d.Builder.Delete("confession_tags").Where("confession_id = ?", confessionID).ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to clear confession tags: %w", err)
+	}
+
+	now := time.Now()
+	for _, tag := range tags {
+		query, args, err := d.Builder.Insert("confession_tags").
+			Columns("confession_id", "tag", "created_at").
+			Values(confessionID, tag, now).ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to add confession tag: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (d *DB) GetConfessionTags(ctx context.Context, confessionID int64) ([]string, error) {
+	builder := d.Builder.Select("tag").From("confession_tags").
+		Where("confession_id = ?", confessionID).OrderBy("tag ASC")
+
+	var tags []string
+	err := d.SelectBuilderContext(ctx, &tags, builder)
+	return tags, err
+}
+
+func (d *DB) GetConfessionsByTag(ctx context.Context, tag string, limit int) ([]*models.Confession, error) {
+	var safeLimit uint64
+	if limit > 0 {
+		safeLimit = uint64(limit)
+	}
+	builder := d.Builder.Select(confessionColumns...).
+		From("confessions").
+		Where("id IN (SELECT confession_id FROM confession_tags WHERE tag = ?)", tag).
+		Where(visibleConfession).
+		OrderBy("created_at DESC").Limit(safeLimit)
+
+	var confessions []*models.Confession
+	if err := d.SelectBuilderContext(ctx, &confessions, builder); err != nil {
+		return nil, fmt.Errorf("failed to get confessions by tag: %w", err)
+	}
+	return confessions, nil
+}
+
+func (d *DB) GetPopularTags(ctx context.Context, since time.Time, limit int) ([]models.TagCount, error) {
+	var safeLimit uint64
+	if limit > 0 {
+		safeLimit = uint64(limit)
+	}
+	builder := d.Builder.Select("t.tag", "COUNT(*) AS total").
+		From("confession_tags t").
+		Join("confessions c ON c.id = t.confession_id").
+		Where("c.is_deleted = FALSE AND c.is_hidden = FALSE AND c.status = ? AND c.created_at > ?", string(models.ConfessionPublished), since).
+		GroupBy("t.tag").
+		OrderBy("total DESC", "t.tag ASC").Limit(safeLimit)
+
+	var tags []models.TagCount
+	if err := d.SelectBuilderContext(ctx, &tags, builder); err != nil {
+		return nil, fmt.Errorf("failed to get popular tags: %w", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+var reportableContent = map[models.ContentType]struct {
+	table     string
+	authorCol string
+	hiddenCol string
+}{
+	models.ContentConfession: {"confessions", "author_id", "is_hidden"},
+	models.ContentReply:      {"confession_replies", "author_id", "is_hidden"},
+	models.ContentWhisper:    {"whispers", "sender_id", "is_hidden"},
+	models.ContentCircleMsg:  {"room_messages", "sender_id", "is_removed"},
+}
+
+func contentTable(contentType models.ContentType) (string, string, string, error) {
+	t, ok := reportableContent[contentType]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown content type: %s", contentType)
+	}
+	return t.table, t.authorCol, t.hiddenCol, nil
+}
+
+func (d *DB) GetContentAuthor(ctx context.Context, contentType models.ContentType, contentID int64) (int64, error) {
+	table, authorCol, hiddenCol, err := contentTable(contentType)
+	if err != nil {
+		return 0, err
+	}
+
+	builder := d.Builder.Select(authorCol).From(table).Where("id = ? AND "+hiddenCol+" = FALSE", contentID)
+	if contentType == models.ContentConfession {
+		builder = builder.Where(visibleConfession)
+	}
+
+	var authorID int64
+	err = d.GetBuilderContext(ctx, &authorID, builder)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get content author: %w", err)
+	}
+	return authorID, nil
+}
+
+func (d *DB) GetContentText(ctx context.Context, contentType models.ContentType, contentID int64) (string, error) {
+	table, _, _, err := contentTable(contentType)
+	if err != nil {
+		return "", err
+	}
+
+	var content string
+	builder := d.Builder.Select("content").From(table).Where("id = ?", contentID)
+	err = d.GetBuilderContext(ctx, &content, builder)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return content, err
+}
+
+func (d *DB) SetContentHidden(ctx context.Context, contentType models.ContentType, contentID int64, hidden bool) error {
+	table, _, hiddenCol, err := contentTable(contentType)
+	if err != nil {
+		return err
+	}
+
+	builder := d.Builder.Update(table).Set(hiddenCol, hidden).Where("id = ?", contentID)
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to update content visibility: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) CreateContentReport(ctx context.Context, contentType models.ContentType, contentID, reporterID int64, reason string) (bool, error) {
+	builder := d.Builder.Insert("content_reports").
+		Columns("content_type", "content_id", "reporter_id", "reason", "created_at").
+		Values(string(contentType), contentID, reporterID, reason, time.Now())
+
+	res, err := d.InsertIgnoreContext(ctx, builder, "content_type, content_id, reporter_id")
+	if err != nil {
+		return false, fmt.Errorf("failed to create content report: %w", err)
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+func (d *DB) CountContentReports(ctx context.Context, contentType models.ContentType, contentID int64) (int, error) {
+	var count int
+	builder := d.Builder.Select("COUNT(DISTINCT reporter_id)").From("content_reports").
+		Where("content_type = ? AND content_id = ?", string(contentType), contentID)
+
+	err := d.GetBuilderContext(ctx, &count, builder)
+	return count, err
+}
+
+func (d *DB) GetUserContentReportCount(ctx context.Context, reporterID int64, since time.Time) (int, error) {
+	var count int
+	builder := d.Builder.Select("COUNT(*)").From("content_reports").
+		Where("reporter_id = ? AND created_at > ?", reporterID, since)
+
+	err := d.GetBuilderContext(ctx, &count, builder)
+	return count, err
+}
+
+func (d *DB) CreateContentFlag(ctx context.Context, contentType models.ContentType, contentID, authorID int64, reportCount int) (bool, error) {
+	builder := d.Builder.Insert("content_flags").
+		Columns("content_type", "content_id", "author_id", "report_count", "status", "created_at").
+		Values(string(contentType), contentID, authorID, reportCount, string(models.FlagPending), time.Now())
+
+	res, err := d.InsertIgnoreContext(ctx, builder, "content_type, content_id")
+	if err != nil {
+		return false, fmt.Errorf("failed to create content flag: %w", err)
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+func (d *DB) UpdateContentFlagCount(ctx context.Context, contentType models.ContentType, contentID int64, reportCount int) error {
+	builder := d.Builder.Update("content_flags").
+		Set("report_count", reportCount).
+		Where("content_type = ? AND content_id = ?", string(contentType), contentID)
+
+	_, err := d.ExecBuilderContext(ctx, builder)
+	return err
+}
+
+var contentFlagColumns = []string{"id", "content_type", "content_id", "author_id", "report_count",
+	"status", "reviewed_by", "reviewed_at", "created_at"}
+
+func (d *DB) GetContentFlag(ctx context.Context, flagID int64) (*models.ContentFlag, error) {
+	f := &models.ContentFlag{}
+	builder := d.Builder.Select(contentFlagColumns...).From("content_flags").Where("id = ?", flagID)
+
+	err := d.GetBuilderContext(ctx, f, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content flag: %w", err)
+	}
+	return f, nil
+}
+
+func (d *DB) GetPendingContentFlags(ctx context.Context, limit int) ([]*models.ContentFlag, error) {
+	var safeLimit uint64
+	if limit > 0 {
+		safeLimit = uint64(limit)
+	}
+	builder := d.Builder.Select(contentFlagColumns...).From("content_flags").
+		Where("status = ?", string(models.FlagPending)).
+		OrderBy("created_at ASC").Limit(safeLimit)
+
+	var flags []*models.ContentFlag
+	if err := d.SelectBuilderContext(ctx, &flags, builder); err != nil {
+		return nil, fmt.Errorf("failed to get content flags: %w", err)
+	}
+	return flags, nil
+}
+
+func (d *DB) ResolveContentFlag(ctx context.Context, flagID int64, status models.FlagStatus, reviewerID int64) error {
+	builder := d.Builder.Update("content_flags").
+		Set("status", string(status)).
+		Set("reviewed_by", reviewerID).
+		Set("reviewed_at", time.Now()).
+		Where("id = ? AND status = ?", flagID, string(models.FlagPending))
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return fmt.Errorf("failed to resolve content flag: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
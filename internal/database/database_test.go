@@ -309,6 +309,183 @@ func TestConfessionCRUD(t *testing.T) {
 	}
 }
 
+func TestConfessionSoftDelete(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	authorID := int64(3010)
+	_, _ = db.CreateUser(ctx, authorID)
+
+	confession, _ := db.CreateConfession(ctx, authorID, "Confession to delete", "TIK")
+
+	if err := db.UpdateConfessionContent(ctx, confession.ID, authorID, "Edited content"); err != nil {
+		t.Fatalf("UpdateConfessionContent failed: %v", err)
+	}
+
+	own, err := db.GetConfessionsByAuthor(ctx, authorID, 10)
+	if err != nil {
+		t.Fatalf("GetConfessionsByAuthor failed: %v", err)
+	}
+	if len(own) != 1 || own[0].Content != "Edited content" || own[0].EditedAt == nil {
+		t.Fatal("expected one edited confession for author")
+	}
+
+	if err := db.SoftDeleteConfession(ctx, confession.ID, authorID); err != nil {
+		t.Fatalf("SoftDeleteConfession failed: %v", err)
+	}
+	if err := db.SoftDeleteConfession(ctx, confession.ID, authorID); err == nil {
+		t.Error("expected error when deleting an already deleted confession")
+	}
+
+	fetched, _ := db.GetConfession(ctx, confession.ID)
+	if fetched != nil {
+		t.Error("deleted confession should not be returned")
+	}
+
+	var rows int
+	if err := db.GetContext(ctx, &rows, "SELECT COUNT(*) FROM confessions WHERE id = ?", confession.ID); err != nil {
+		t.Fatalf("count query failed: %v", err)
+	}
+	if rows != 1 {
+		t.Error("soft delete should keep the confession row")
+	}
+
+	total, _ := db.GetTotalConfessions(ctx, authorID)
+	if total != 0 {
+		t.Errorf("expected 0 visible confessions, got %d", total)
+	}
+}
+
+func TestContentReportsAndHiding(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	authorID := int64(3020)
+	reporterID := int64(3021)
+	_, _ = db.CreateUser(ctx, authorID)
+	_, _ = db.CreateUser(ctx, reporterID)
+
+	confession, _ := db.CreateConfession(ctx, authorID, "Reported confession", "TIK")
+
+	created, err := db.CreateContentReport(ctx, models.ContentConfession, confession.ID, reporterID, "Spam")
+	if err != nil || !created {
+		t.Fatalf("CreateContentReport failed: created=%v err=%v", created, err)
+	}
+	created, _ = db.CreateContentReport(ctx, models.ContentConfession, confession.ID, reporterID, "Spam")
+	if created {
+		t.Error("duplicate report from the same reporter should be ignored")
+	}
+
+	count, _ := db.CountContentReports(ctx, models.ContentConfession, confession.ID)
+	if count != 1 {
+		t.Errorf("expected 1 report, got %d", count)
+	}
+
+	flagged, err := db.CreateContentFlag(ctx, models.ContentConfession, confession.ID, authorID, count)
+	if err != nil || !flagged {
+		t.Fatalf("CreateContentFlag failed: flagged=%v err=%v", flagged, err)
+	}
+	if err := db.SetContentHidden(ctx, models.ContentConfession, confession.ID, true); err != nil {
+		t.Fatalf("SetContentHidden failed: %v", err)
+	}
+
+	if c, _ := db.GetConfession(ctx, confession.ID); c != nil {
+		t.Error("hidden confession should not be returned")
+	}
+	if latest, _ := db.GetLatestConfessions(ctx, 10); len(latest) != 0 {
+		t.Error("hidden confession should not appear in the feed")
+	}
+	if author, _ := db.GetContentAuthor(ctx, models.ContentConfession, confession.ID); author != 0 {
+		t.Error("hidden content should not be reportable")
+	}
+
+	flags, err := db.GetPendingContentFlags(ctx, 10)
+	if err != nil || len(flags) != 1 {
+		t.Fatalf("expected 1 pending flag, got %d (err=%v)", len(flags), err)
+	}
+	if err := db.ResolveContentFlag(ctx, flags[0].ID, models.FlagRestored, authorID); err != nil {
+		t.Fatalf("ResolveContentFlag failed: %v", err)
+	}
+	if err := db.ResolveContentFlag(ctx, flags[0].ID, models.FlagRemoved, authorID); err == nil {
+		t.Error("expected error when resolving an already reviewed flag")
+	}
+}
+
+func TestSearchConfessions(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	authorID := int64(3030)
+	_, _ = db.CreateUser(ctx, authorID)
+
+	c1, _ := db.CreateConfession(ctx, authorID, "Dosen killer bikin ujian susah banget", "TIK")
+	c2, _ := db.CreateConfession(ctx, authorID, "Kantin baru enak dan murah", "TIK")
+	_, _ = db.CreateConfession(ctx, authorID, "Ujian praktikum minggu depan", "TIK")
+
+	results, err := db.SearchConfessions(ctx, "ujian", 10)
+	if err != nil {
+		t.Fatalf("SearchConfessions failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected 2 results, got %d", len(results))
+	}
+
+	results, _ = db.SearchConfessions(ctx, "dos kill", 10)
+	if len(results) != 1 || results[0].ID != c1.ID {
+		t.Error("prefix search should match the dosen killer confession")
+	}
+
+	if err := db.UpdateConfessionContent(ctx, c2.ID, authorID, "Kantin baru ternyata mahal"); err != nil {
+		t.Fatalf("UpdateConfessionContent failed: %v", err)
+	}
+	if results, _ = db.SearchConfessions(ctx, "murah", 10); len(results) != 0 {
+		t.Error("edited content should be reindexed")
+	}
+	if results, _ = db.SearchConfessions(ctx, "mahal", 10); len(results) != 1 {
+		t.Error("new content should be searchable after edit")
+	}
+
+	_ = db.SoftDeleteConfession(ctx, c1.ID, authorID)
+	if results, _ = db.SearchConfessions(ctx, "killer", 10); len(results) != 0 {
+		t.Error("deleted confession should not be searchable")
+	}
+
+	if results, err = db.SearchConfessions(ctx, `"*) OR (`, 10); err != nil || len(results) != 0 {
+		t.Errorf("query with only operators should return nothing, got %d (err=%v)", len(results), err)
+	}
+}
+
+func TestConfessionTags(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	authorID := int64(3031)
+	_, _ = db.CreateUser(ctx, authorID)
+
+	c1, _ := db.CreateConfession(ctx, authorID, "Confession #kampus #ujian", "TIK")
+	c2, _ := db.CreateConfession(ctx, authorID, "Confession #kampus", "TIK")
+
+	if err := db.SetConfessionTags(ctx, c1.ID, []string{"kampus", "ujian"}); err != nil {
+		t.Fatalf("SetConfessionTags failed: %v", err)
+	}
+	_ = db.SetConfessionTags(ctx, c2.ID, []string{"kampus"})
+
+	byTag, err := db.GetConfessionsByTag(ctx, "kampus", 10)
+	if err != nil || len(byTag) != 2 {
+		t.Fatalf("expected 2 confessions for #kampus, got %d (err=%v)", len(byTag), err)
+	}
+
+	popular, err := db.GetPopularTags(ctx, time.Now().Add(-time.Hour), 10)
+	if err != nil || len(popular) != 2 {
+		t.Fatalf("expected 2 popular tags, got %d (err=%v)", len(popular), err)
+	}
+	if popular[0].Tag != "kampus" || popular[0].Total != 2 {
+		t.Errorf("unexpected top tag: %+v", popular[0])
+	}
+
+	_ = db.SetConfessionTags(ctx, c1.ID, []string{"kantin"})
+	tags, _ := db.GetConfessionTags(ctx, c1.ID)
+	if len(tags) != 1 || tags[0] != "kantin" {
+		t.Errorf("expected tags to be replaced, got %v", tags)
+	}
+}
+
 func TestConfessionReactions(t *testing.T) {
 	db := setupTestDB(t)
 	ctx := context.Background()
@@ -375,6 +552,176 @@ func TestConfessionReplies(t *testing.T) {
 	}
 }
 
+func TestPollLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	author, voter1, voter2 := int64(4101), int64(4102), int64(4103)
+	for _, id := range []int64{author, voter1, voter2} {
+		_, _ = db.CreateUser(ctx, id)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	pollID, err := db.CreatePoll(ctx, &models.Poll{AuthorID: &author, Question: "Kantin buka sampai malam?", ExpiresAt: &expiresAt}, []string{"Ya", "Tidak"})
+	if err != nil {
+		t.Fatalf("CreatePoll failed: %v", err)
+	}
+
+	poll, _ := db.GetPoll(ctx, pollID)
+	if poll == nil || poll.ExpiresAt == nil || !poll.IsOpen(time.Now()) {
+		t.Fatalf("expected open poll with deadline, got %+v", poll)
+	}
+	if err := db.VotePoll(ctx, pollID, voter1, poll.Options[0].ID); err != nil {
+		t.Fatalf("VotePoll failed: %v", err)
+	}
+
+	expired, _ := db.GetExpiredPolls(ctx, time.Now())
+	if len(expired) != 0 {
+		t.Errorf("expected no expired polls yet, got %d", len(expired))
+	}
+	expired, _ = db.GetExpiredPolls(ctx, expiresAt.Add(time.Minute))
+	if len(expired) != 1 || expired[0].ID != pollID {
+		t.Fatalf("expected poll %d to be expired, got %+v", pollID, expired)
+	}
+
+	if err := db.ClosePoll(ctx, pollID); err != nil {
+		t.Fatalf("ClosePoll failed: %v", err)
+	}
+	if err := db.ClosePoll(ctx, pollID); err == nil {
+		t.Error("closing an already closed poll should fail")
+	}
+	if err := db.VotePoll(ctx, pollID, voter2, poll.Options[1].ID); err == nil {
+		t.Error("voting on a closed poll should fail")
+	}
+
+	voters, _ := db.GetPollVoters(ctx, pollID)
+	if len(voters) != 1 || voters[0] != voter1 {
+		t.Errorf("expected only voter1, got %v", voters)
+	}
+	expired, _ = db.GetExpiredPolls(ctx, expiresAt.Add(time.Minute))
+	if len(expired) != 0 {
+		t.Errorf("closed poll should not be returned as expired, got %d", len(expired))
+	}
+}
+
+func TestPollScopes(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	author := int64(4111)
+	_, _ = db.CreateUser(ctx, author)
+
+	personalID, err := db.CreatePoll(ctx, &models.Poll{AuthorID: &author, Question: "Personal?"}, []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("CreatePoll failed: %v", err)
+	}
+	deptID, err := db.CreatePoll(ctx, &models.Poll{AuthorID: &author, Scope: models.PollDepartment, Department: "Akuntansi", Question: "Jurusan?"}, []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("CreatePoll (department) failed: %v", err)
+	}
+	globalID, err := db.CreatePoll(ctx, &models.Poll{Scope: models.PollGlobal, Question: "Global?"}, []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("CreatePoll (global) failed: %v", err)
+	}
+
+	global, _ := db.GetPoll(ctx, globalID)
+	if global == nil || global.Scope != models.PollGlobal || global.AuthorID != nil {
+		t.Fatalf("expected authorless global poll, got %+v", global)
+	}
+	if global.Question != "Global?" {
+		t.Errorf("global question should not be prefixed, got %q", global.Question)
+	}
+
+	ids := func(polls []*models.Poll) map[int64]bool {
+		m := make(map[int64]bool)
+		for _, p := range polls {
+			m[p.ID] = true
+		}
+		return m
+	}
+
+	polls, err := db.GetLatestPollsContext(ctx, "Akuntansi", 10)
+	if err != nil {
+		t.Fatalf("GetLatestPollsContext failed: %v", err)
+	}
+	seen := ids(polls)
+	if !seen[personalID] || !seen[deptID] || seen[globalID] {
+		t.Errorf("unexpected polls for same department: %v", seen)
+	}
+
+	polls, _ = db.GetLatestPollsContext(ctx, "Teknik Sipil", 10)
+	seen = ids(polls)
+	if !seen[personalID] || seen[deptID] || seen[globalID] {
+		t.Errorf("unexpected polls for other department: %v", seen)
+	}
+
+	polls, _ = db.GetGlobalPolls(ctx, 10)
+	if len(polls) != 1 || polls[0].ID != globalID {
+		t.Errorf("expected only the global poll, got %d polls", len(polls))
+	}
+}
+
+func TestPollBallots(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	author, voter1, voter2 := int64(4121), int64(4122), int64(4123)
+	for _, id := range []int64{author, voter1, voter2} {
+		_, _ = db.CreateUser(ctx, id)
+	}
+
+	multiID, err := db.CreatePoll(ctx, &models.Poll{AuthorID: &author, Question: "Menu?", Type: models.PollMulti, MaxChoices: 2},
+		[]string{"A", "B", "C"})
+	if err != nil {
+		t.Fatalf("CreatePoll failed: %v", err)
+	}
+	multi, _ := db.GetPoll(ctx, multiID)
+	if multi.Type != models.PollMulti || multi.MaxChoices != 2 {
+		t.Fatalf("expected multi poll with 2 choices, got %s/%d", multi.Type, multi.MaxChoices)
+	}
+	a, b, c := multi.Options[0].ID, multi.Options[1].ID, multi.Options[2].ID
+
+	if err := db.SubmitPollBallot(ctx, multiID, voter1, []int64{a, b, c}); err == nil {
+		t.Error("ballot above the choice limit should fail")
+	}
+	if err := db.SubmitPollBallot(ctx, multiID, voter1, []int64{a, a}); err == nil {
+		t.Error("ballot with duplicate options should fail")
+	}
+	if err := db.SubmitPollBallot(ctx, multiID, voter1, []int64{a, c}); err != nil {
+		t.Fatalf("SubmitPollBallot failed: %v", err)
+	}
+	if err := db.SubmitPollBallot(ctx, multiID, voter1, []int64{b}); err == nil {
+		t.Error("voting twice should fail")
+	}
+	multi, _ = db.GetPoll(ctx, multiID)
+	if multi.Options[0].VoteCount != 1 || multi.Options[1].VoteCount != 0 || multi.Options[2].VoteCount != 1 {
+		t.Errorf("unexpected multi counts: %d %d %d", multi.Options[0].VoteCount, multi.Options[1].VoteCount, multi.Options[2].VoteCount)
+	}
+	if count, _ := db.GetPollVoteCountContext(ctx, multiID); count != 1 {
+		t.Errorf("expected 1 voter, got %d", count)
+	}
+
+	rankedID, _ := db.CreatePoll(ctx, &models.Poll{AuthorID: &author, Question: "Ketua?", Type: models.PollRanked},
+		[]string{"A", "B", "C"})
+	ranked, _ := db.GetPoll(ctx, rankedID)
+	a, b, c = ranked.Options[0].ID, ranked.Options[1].ID, ranked.Options[2].ID
+	if err := db.SubmitPollBallot(ctx, rankedID, voter1, []int64{c, a, b}); err != nil {
+		t.Fatalf("SubmitPollBallot (ranked) failed: %v", err)
+	}
+	if err := db.SubmitPollBallot(ctx, rankedID, voter2, []int64{b}); err != nil {
+		t.Fatalf("SubmitPollBallot (ranked) failed: %v", err)
+	}
+
+	ballots, err := db.GetPollBallots(ctx, rankedID)
+	if err != nil {
+		t.Fatalf("GetPollBallots failed: %v", err)
+	}
+	if len(ballots) != 4 || ballots[0].OptionID != c || ballots[0].Rank != 1 || ballots[2].OptionID != b || ballots[2].Rank != 3 {
+		t.Errorf("unexpected ranked ballots: %+v", ballots)
+	}
+	ranked, _ = db.GetPoll(ctx, rankedID)
+	if ranked.Options[0].VoteCount != 0 || ranked.Options[1].VoteCount != 1 || ranked.Options[2].VoteCount != 1 {
+		t.Errorf("ranked vote_count should track first preferences only")
+	}
+}
+
 func TestReportAndBlock(t *testing.T) {
 	db := setupTestDB(t)
 	ctx := context.Background()
@@ -549,7 +896,7 @@ func TestRoomCRUD(t *testing.T) {
 	db := setupTestDB(t)
 	ctx := context.Background()
 
-	room, err := db.CreateRoom(ctx, "test-room", "Test Room", "A test room")
+	room, err := db.CreateRoom(ctx, "test-room", "Test Room", "A test room", 7001)
 	if err != nil {
 		t.Fatalf("CreateRoom failed: %v", err)
 	}
@@ -579,10 +926,10 @@ func TestRoomMembers(t *testing.T) {
 	_, _ = db.CreateUser(ctx, user1)
 	_, _ = db.CreateUser(ctx, user2)
 
-	room, _ := db.CreateRoom(ctx, "member-test", "Member Test Room", "Testing room members")
+	room, _ := db.CreateRoom(ctx, "member-test", "Member Test Room", "Testing room members", 8001)
 
-	_ = db.AddRoomMember(ctx, room.ID, user1)
-	_ = db.AddRoomMember(ctx, room.ID, user2)
+	_ = db.AddRoomMember(ctx, room.ID, user1, "RedFox#1")
+	_ = db.AddRoomMember(ctx, room.ID, user2, "BlueOwl#2")
 
 	members, err := db.GetRoomMembers(ctx, room.ID)
 	if err != nil {
@@ -592,11 +939,22 @@ func TestRoomMembers(t *testing.T) {
 		t.Errorf("expected 2 members, got %d", len(members))
 	}
 
+	if userRoom, _ := db.GetUserRoom(ctx, user1); userRoom != nil {
+		t.Error("GetUserRoom should be empty until a speaking room is chosen")
+	}
+	_ = db.SetSpeakingRoom(ctx, user1, room.ID)
 	userRoom, _ := db.GetUserRoom(ctx, user1)
 	if userRoom == nil || userRoom.Slug != "member-test" {
 		t.Error("GetUserRoom should return the room user is in")
 	}
 
+	if alias, member, _ := db.GetRoomMemberAlias(ctx, room.ID, user1); !member || alias != "RedFox#1" {
+		t.Errorf("expected alias RedFox#1, got %q (member=%v)", alias, member)
+	}
+	if taken, _ := db.IsRoomAliasTaken(ctx, room.ID, "BlueOwl#2"); !taken {
+		t.Error("alias BlueOwl#2 should be taken")
+	}
+
 	_ = db.RemoveRoomMember(ctx, room.ID, user1)
 	members, _ = db.GetRoomMembers(ctx, room.ID)
 	if len(members) != 1 {
@@ -610,10 +968,10 @@ func TestRemoveMemberFromAllRooms(t *testing.T) {
 	userID := int64(8003)
 	_, _ = db.CreateUser(ctx, userID)
 
-	room1, _ := db.CreateRoom(ctx, "room-a", "Room A", "First test room")
-	room2, _ := db.CreateRoom(ctx, "room-b", "Room B", "Second test room")
-	_ = db.AddRoomMember(ctx, room1.ID, userID)
-	_ = db.AddRoomMember(ctx, room2.ID, userID)
+	room1, _ := db.CreateRoom(ctx, "room-a", "Room A", "First test room", 0)
+	room2, _ := db.CreateRoom(ctx, "room-b", "Room B", "Second test room", 0)
+	_ = db.AddRoomMember(ctx, room1.ID, userID, "RedFox#1")
+	_ = db.AddRoomMember(ctx, room2.ID, userID, "RedFox#1")
 
 	_ = db.RemoveMemberFromAllRooms(ctx, userID)
 
@@ -624,6 +982,54 @@ func TestRemoveMemberFromAllRooms(t *testing.T) {
 	}
 }
 
+func TestRoomModeration(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	owner, member := int64(8101), int64(8102)
+	_, _ = db.CreateUser(ctx, owner)
+	_, _ = db.CreateUser(ctx, member)
+
+	room, _ := db.CreateRoom(ctx, "mod-room", "Mod Room", "Testing moderation", owner)
+	if room.CreatedBy == nil || *room.CreatedBy != owner {
+		t.Error("room should record its creator")
+	}
+
+	_ = db.SetRoomRole(ctx, room.ID, member, models.RoomRoleModerator)
+	_ = db.SetRoomRole(ctx, room.ID, member, models.RoomRoleOwner)
+	role, err := db.GetRoomRole(ctx, room.ID, member)
+	if err != nil || role != models.RoomRoleOwner {
+		t.Errorf("expected role to be replaced with owner, got %q (%v)", role, err)
+	}
+	_ = db.RemoveRoomRole(ctx, room.ID, member)
+	if role, _ := db.GetRoomRole(ctx, room.ID, member); role != models.RoomRoleMember {
+		t.Errorf("expected member role after removal, got %q", role)
+	}
+
+	now := time.Now()
+	_ = db.MuteRoomMember(ctx, room.ID, member, now.Add(time.Hour), owner)
+	if until, _ := db.GetRoomMuteUntil(ctx, room.ID, member, now); until == nil {
+		t.Error("member should be muted")
+	}
+	if until, _ := db.GetRoomMuteUntil(ctx, room.ID, member, now.Add(2*time.Hour)); until != nil {
+		t.Error("mute should have expired")
+	}
+
+	_ = db.AddRoomKick(ctx, room.ID, member, owner)
+	if err := db.AddRoomKick(ctx, room.ID, member, owner); err != nil {
+		t.Errorf("repeated kick should be ignored: %v", err)
+	}
+	if kicked, _ := db.IsKickedFromRoom(ctx, room.ID, member); !kicked {
+		t.Error("member should be kicked")
+	}
+
+	_ = db.AddRoomPin(ctx, room.ID, "first", owner)
+	_ = db.AddRoomPin(ctx, room.ID, "second", owner)
+	pin, err := db.GetLatestRoomPin(ctx, room.ID)
+	if err != nil || pin == nil || pin.Content != "second" {
+		t.Errorf("expected latest pin 'second', got %+v (%v)", pin, err)
+	}
+}
+
 func TestGetOnlineUserCount(t *testing.T) {
 	db := setupTestDB(t)
 	ctx := context.Background()
@@ -748,3 +1154,259 @@ func TestTotalChatSessions(t *testing.T) {
 		t.Errorf("expected 2 chat sessions, got %d", count)
 	}
 }
+
+func TestRoomMessages(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	owner := int64(8201)
+	_, _ = db.CreateUser(ctx, owner)
+	room, _ := db.CreateRoom(ctx, "history-room", "History Room", "Testing history", owner)
+
+	oldID, _ := db.AddRoomMessage(ctx, &models.RoomMessage{RoomID: room.ID, SenderID: owner, Alias: "RedFox#1", Content: "lama"})
+	_, _ = db.Exec("UPDATE room_messages SET created_at = ? WHERE id = ?", time.Now().Add(-48*time.Hour), oldID)
+	newID, _ := db.AddRoomMessage(ctx, &models.RoomMessage{RoomID: room.ID, SenderID: owner, Alias: "RedFox#1", MediaType: "photo", FileID: "file-1"})
+
+	if err := db.PruneRoomMessages(ctx, room.ID, 10, time.Now().Add(-24*time.Hour)); err != nil {
+		t.Fatalf("PruneRoomMessages failed: %v", err)
+	}
+
+	messages, err := db.GetRoomHistory(ctx, room.ID, 0, 10, time.Time{})
+	if err != nil {
+		t.Fatalf("GetRoomHistory failed: %v", err)
+	}
+	if len(messages) != 1 || messages[0].ID != newID || messages[0].FileID != "file-1" {
+		t.Errorf("expected only the recent photo to survive pruning, got %+v", messages)
+	}
+}
+
+func TestBroadcastRecipients(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	for _, id := range []int64{8301, 8302, 8303} {
+		_, _ = db.CreateUser(ctx, id)
+		_ = db.UpdateUserVerified(ctx, id, true)
+	}
+	_ = db.UpdateUserBanned(ctx, 8303, true)
+	_, _ = db.CreateUser(ctx, 8304)
+
+	bc := &models.Broadcast{Kind: models.BroadcastMessage, Content: "halo", CreatedBy: 1}
+	id, err := db.CreateBroadcast(ctx, bc)
+	if err != nil {
+		t.Fatalf("CreateBroadcast failed: %v", err)
+	}
+	if next, _ := db.GetNextRunningBroadcast(ctx); next != nil {
+		t.Fatalf("expected draft broadcast not to run, got %+v", next)
+	}
+
+	bc.ID = id
+	count, activated, err := db.ActivateBroadcast(ctx, bc)
+	if err != nil || !activated {
+		t.Fatalf("ActivateBroadcast failed: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 recipients, got %d", count)
+	}
+
+	next, _ := db.GetNextRunningBroadcast(ctx)
+	if next == nil || next.ID != id {
+		t.Fatalf("expected broadcast %d to be running, got %+v", id, next)
+	}
+
+	_ = db.MarkBroadcastRecipient(ctx, id, 8301, models.RecipientBlocked, "blocked")
+	pending, _ := db.GetPendingRecipients(ctx, id, 10)
+	if len(pending) != 1 || pending[0] != 8302 {
+		t.Errorf("expected only 8302 pending, got %v", pending)
+	}
+
+	progress, err := db.GetBroadcastProgress(ctx, id)
+	if err != nil {
+		t.Fatalf("GetBroadcastProgress failed: %v", err)
+	}
+	if progress.Pending != 1 || progress.Blocked != 1 || progress.Total() != 2 {
+		t.Errorf("unexpected progress: %+v", progress)
+	}
+
+	if ok, _ := db.FinishBroadcast(ctx, id, models.BroadcastDone); !ok {
+		t.Error("expected running broadcast to finish")
+	}
+	if ok, _ := db.FinishBroadcast(ctx, id, models.BroadcastCancelled); ok {
+		t.Error("expected finished broadcast to stay done")
+	}
+	if next, _ := db.GetNextRunningBroadcast(ctx); next != nil {
+		t.Errorf("expected no running broadcast, got %+v", next)
+	}
+}
+
+func TestBroadcastSegments(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	for _, id := range []int64{8401, 8402, 8403} {
+		_, _ = db.CreateUser(ctx, id)
+		_ = db.UpdateUserVerified(ctx, id, true)
+		_ = db.UpdateUserDepartment(ctx, id, string(models.DeptAkuntansi))
+	}
+	_ = db.UpdateUserDepartment(ctx, 8403, string(models.DeptTeknikSipil))
+	_ = db.UpdateUserGender(ctx, 8402, string(models.GenderFemale))
+	_, _ = db.Exec("UPDATE users SET last_active_at = ? WHERE telegram_id = ?", time.Now().AddDate(0, 0, -60), 8401)
+
+	count, err := db.CountBroadcastAudience(ctx, models.BroadcastSegment{Department: models.DeptAkuntansi})
+	if err != nil || count != 2 {
+		t.Errorf("expected 2 Akuntansi users, got %d (%v)", count, err)
+	}
+	count, _ = db.CountBroadcastAudience(ctx, models.BroadcastSegment{Department: models.DeptAkuntansi, Gender: models.GenderFemale})
+	if count != 1 {
+		t.Errorf("expected 1 female Akuntansi user, got %d", count)
+	}
+	count, _ = db.CountBroadcastAudience(ctx, models.BroadcastSegment{ActiveDays: 30})
+	if count != 2 {
+		t.Errorf("expected 2 recently active users, got %d", count)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	dueID, _ := db.CreateBroadcast(ctx, &models.Broadcast{Kind: models.BroadcastMessage, Content: "due", Status: models.BroadcastScheduled, ScheduledAt: &past, CreatedBy: 1})
+	_, _ = db.CreateBroadcast(ctx, &models.Broadcast{Kind: models.BroadcastMessage, Content: "later", Status: models.BroadcastScheduled, ScheduledAt: &future, CreatedBy: 1})
+
+	due, err := db.GetDueBroadcasts(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("GetDueBroadcasts failed: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != dueID {
+		t.Errorf("expected only broadcast %d to be due, got %+v", dueID, due)
+	}
+}
+
+func TestUserReachability(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	for _, id := range []int64{8501, 8502} {
+		_, _ = db.CreateUser(ctx, id)
+		_ = db.UpdateUserVerified(ctx, id, true)
+		_ = db.UpdateUserDepartment(ctx, id, string(models.DeptAkuntansi))
+	}
+
+	changed, err := db.SetUserReachable(ctx, 8501, false)
+	if err != nil || !changed {
+		t.Fatalf("expected user to become unreachable, got %v %v", changed, err)
+	}
+	if changed, _ := db.SetUserReachable(ctx, 8501, false); changed {
+		t.Error("expected marking twice to be a no-op")
+	}
+
+	user, _ := db.GetUser(ctx, 8501)
+	if user.IsReachable {
+		t.Error("expected IsReachable to be false")
+	}
+	if ids, _ := db.GetAllVerifiedUsers(ctx); len(ids) != 1 || ids[0] != 8502 {
+		t.Errorf("expected unreachable user to be skipped, got %v", ids)
+	}
+	if ids, _ := db.GetUsersByDepartment(ctx, string(models.DeptAkuntansi), 0); len(ids) != 1 {
+		t.Errorf("expected unreachable user to be skipped in department fan-out, got %v", ids)
+	}
+	if count, _ := db.CountBroadcastAudience(ctx, models.BroadcastSegment{}); count != 1 {
+		t.Errorf("expected broadcast audience of 1, got %d", count)
+	}
+
+	if changed, _ := db.SetUserReachable(ctx, 8501, true); !changed {
+		t.Error("expected user to become reachable again")
+	}
+	if ids, _ := db.GetAllVerifiedUsers(ctx); len(ids) != 2 {
+		t.Errorf("expected returning user to be included again, got %v", ids)
+	}
+}
+
+func TestStaffRoles(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	if granted, err := db.GrantStaffRole(ctx, 8601, models.StaffAdmin, 1); err != nil || !granted {
+		t.Fatalf("GrantStaffRole failed: %v %v", granted, err)
+	}
+	if granted, _ := db.GrantStaffRole(ctx, 8601, models.StaffAdmin, 1); granted {
+		t.Error("expected duplicate grant to be ignored")
+	}
+	_, _ = db.GrantStaffRole(ctx, 8601, models.StaffCSAgent, 1)
+	_, _ = db.GrantStaffRole(ctx, 8602, models.StaffModerator, 1)
+
+	roles, err := db.GetStaffRoles(ctx, 8601)
+	if err != nil || len(roles) != 2 {
+		t.Fatalf("expected 2 roles, got %v (%v)", roles, err)
+	}
+
+	ids, _ := db.GetStaffWithRoles(ctx, []models.StaffRole{models.StaffAdmin, models.StaffModerator, models.StaffCSAgent})
+	if len(ids) != 2 {
+		t.Errorf("expected distinct staff ids, got %v", ids)
+	}
+
+	if revoked, _ := db.RevokeStaffRole(ctx, 8601, models.StaffAdmin); !revoked {
+		t.Error("expected admin role to be revoked")
+	}
+	if count, _ := db.CountStaffRole(ctx, models.StaffAdmin); count != 0 {
+		t.Errorf("expected no admins left, got %d", count)
+	}
+}
+
+func TestAdminAudit(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	for _, e := range []*models.AuditEntry{
+		{ActorID: 8701, Action: models.AuditRoleGrant, TargetID: 8702, Details: `{"role":"moderator"}`},
+		{ActorID: 8702, Action: models.AuditContentReview, TargetID: 8703, Details: `{"flag_id":1}`},
+		{ActorID: 8701, Action: models.AuditBroadcastCancel, Details: `{"broadcast_id":5}`},
+	} {
+		if err := db.AddAuditEntry(ctx, e); err != nil {
+			t.Fatalf("AddAuditEntry failed: %v", err)
+		}
+	}
+
+	all, err := db.GetAuditEntries(ctx, 0, 0)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("expected 3 entries, got %d (%v)", len(all), err)
+	}
+	if all[0].Action != models.AuditBroadcastCancel {
+		t.Errorf("expected newest entry first, got %s", all[0].Action)
+	}
+
+	involving, _ := db.GetAuditEntries(ctx, 8702, 0)
+	if len(involving) != 2 {
+		t.Errorf("expected entries as actor and target, got %d", len(involving))
+	}
+	if limited, _ := db.GetAuditEntries(ctx, 0, 1); len(limited) != 1 {
+		t.Errorf("expected limit to apply, got %d", len(limited))
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE admin_audit SET action = 'tampered'"); err == nil {
+		t.Error("expected audit entries to reject updates")
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM admin_audit"); err == nil {
+		t.Error("expected audit entries to reject deletes")
+	}
+	if after, _ := db.GetAuditEntries(ctx, 0, 0); len(after) != 3 || after[0].Action != models.AuditBroadcastCancel {
+		t.Error("audit log changed after rejected modification")
+	}
+}
+
+func TestAdjacentPhotoConfession(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	author := int64(8801)
+	_, _ = db.CreateUser(ctx, author)
+
+	first, _ := db.CreatePhotoConfession(ctx, author, "Foto pertama", "Akuntansi", "file-1", models.ConfessionPublished)
+	_, _ = db.CreateConfession(ctx, author, "Confession teks", "Akuntansi")
+	_, _ = db.CreatePhotoConfession(ctx, author, "Foto tertunda", "Akuntansi", "file-2", models.ConfessionPending)
+	third, _ := db.CreatePhotoConfession(ctx, author, "Foto ketiga", "Akuntansi", "file-3", models.ConfessionPublished)
+
+	older, err := db.GetAdjacentPhotoConfession(ctx, third.ID, true)
+	if err != nil || older == nil || older.ID != first.ID {
+		t.Fatalf("expected older photo #%d, got %+v (%v)", first.ID, older, err)
+	}
+	newer, _ := db.GetAdjacentPhotoConfession(ctx, first.ID, false)
+	if newer == nil || newer.ID != third.ID {
+		t.Errorf("expected newer photo #%d, got %+v", third.ID, newer)
+	}
+	if none, _ := db.GetAdjacentPhotoConfession(ctx, third.ID, false); none != nil {
+		t.Errorf("expected no newer photo, got #%d", none.ID)
+	}
+}
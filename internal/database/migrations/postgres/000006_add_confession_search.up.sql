@@ -0,0 +1,15 @@
+ALTER TABLE confessions ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(content, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_confessions_search ON confessions USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS confession_tags (
+    id SERIAL PRIMARY KEY,
+    confession_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(confession_id, tag),
+    FOREIGN KEY (confession_id) REFERENCES confessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_confession_tags_tag ON confession_tags(tag);
@@ -0,0 +1,16 @@
+ALTER TABLE users ADD COLUMN allow_chat_requests BOOLEAN DEFAULT TRUE;
+
+CREATE TABLE IF NOT EXISTS chat_requests (
+    id SERIAL PRIMARY KEY,
+    confession_id BIGINT NOT NULL,
+    requester_id BIGINT NOT NULL,
+    author_id BIGINT NOT NULL,
+    status TEXT DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    responded_at TIMESTAMP,
+    FOREIGN KEY (confession_id) REFERENCES confessions(id),
+    FOREIGN KEY (requester_id) REFERENCES users(telegram_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_requests_requester ON chat_requests(requester_id, status);
+CREATE INDEX IF NOT EXISTS idx_chat_requests_author ON chat_requests(author_id, status);
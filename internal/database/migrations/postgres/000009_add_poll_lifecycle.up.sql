@@ -0,0 +1,5 @@
+ALTER TABLE polls ADD COLUMN expires_at TIMESTAMP;
+ALTER TABLE polls ADD COLUMN is_closed BOOLEAN DEFAULT FALSE;
+ALTER TABLE polls ADD COLUMN closed_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_polls_expiry ON polls(is_closed, expires_at);
@@ -0,0 +1,11 @@
+ALTER TABLE polls ALTER COLUMN author_id DROP NOT NULL;
+ALTER TABLE polls ADD COLUMN scope TEXT NOT NULL DEFAULT 'personal';
+ALTER TABLE polls ADD COLUMN department TEXT DEFAULT '';
+
+UPDATE polls
+SET scope = 'global',
+    author_id = NULL,
+    question = CASE WHEN question LIKE '[GLOBAL] %' THEN SUBSTRING(question FROM 10) ELSE question END
+WHERE author_id = 0 OR question LIKE '[GLOBAL] %';
+
+CREATE INDEX IF NOT EXISTS idx_polls_scope ON polls(scope, department, created_at);
@@ -0,0 +1,8 @@
+ALTER TABLE polls ADD COLUMN poll_type TEXT NOT NULL DEFAULT 'single';
+ALTER TABLE polls ADD COLUMN max_choices INTEGER DEFAULT 1;
+
+ALTER TABLE poll_votes ADD COLUMN rank INTEGER DEFAULT 1;
+ALTER TABLE poll_votes DROP CONSTRAINT IF EXISTS poll_votes_poll_id_telegram_id_key;
+ALTER TABLE poll_votes ADD CONSTRAINT poll_votes_poll_id_telegram_id_option_id_key UNIQUE (poll_id, telegram_id, option_id);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_voter ON poll_votes(poll_id, telegram_id);
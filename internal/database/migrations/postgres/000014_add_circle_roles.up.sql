@@ -0,0 +1,44 @@
+ALTER TABLE rooms ADD COLUMN created_by BIGINT;
+
+CREATE TABLE IF NOT EXISTS room_roles (
+    id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL,
+    telegram_id BIGINT NOT NULL,
+    role TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(room_id, telegram_id),
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    FOREIGN KEY (telegram_id) REFERENCES users(telegram_id)
+);
+
+CREATE TABLE IF NOT EXISTS room_mutes (
+    id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL,
+    telegram_id BIGINT NOT NULL,
+    muted_until TIMESTAMP NOT NULL,
+    muted_by BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(room_id, telegram_id),
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS room_kicks (
+    id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL,
+    telegram_id BIGINT NOT NULL,
+    kicked_by BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(room_id, telegram_id),
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS room_pins (
+    id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    pinned_by BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_pins_room ON room_pins(room_id, created_at);
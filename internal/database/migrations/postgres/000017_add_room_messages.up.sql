@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS room_messages (
+    id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL,
+    sender_id BIGINT NOT NULL,
+    alias TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    media_type TEXT NOT NULL DEFAULT '',
+    file_id TEXT NOT NULL DEFAULT '',
+    is_removed BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_messages_room ON room_messages(room_id, id);
@@ -0,0 +1,8 @@
+ALTER TABLE rooms ADD COLUMN last_message_at TIMESTAMP;
+
+-- Existing circles start from their newest logged message, or from now, so
+-- the first archive sweep does not treat them all as inactive.
+UPDATE rooms SET last_message_at = COALESCE(
+    (SELECT MAX(created_at) FROM room_messages WHERE room_messages.room_id = rooms.id),
+    CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN is_reachable BOOLEAN NOT NULL DEFAULT TRUE;
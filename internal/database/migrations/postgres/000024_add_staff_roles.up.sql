@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS staff_roles (
+    telegram_id BIGINT NOT NULL,
+    role TEXT NOT NULL,
+    granted_by BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (telegram_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_roles_role ON staff_roles(role);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS admin_audit (
+    id SERIAL PRIMARY KEY,
+    actor_id BIGINT NOT NULL,
+    action TEXT NOT NULL,
+    target_id BIGINT NOT NULL DEFAULT 0,
+    details TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_actor ON admin_audit(actor_id, id);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_target ON admin_audit(target_id, id);
+
+CREATE OR REPLACE FUNCTION admin_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER admin_audit_no_modify BEFORE UPDATE OR DELETE ON admin_audit
+    FOR EACH ROW EXECUTE FUNCTION admin_audit_append_only();
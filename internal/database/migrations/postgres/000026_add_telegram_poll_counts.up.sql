@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS telegram_poll_counts (
+    telegram_poll_id TEXT NOT NULL,
+    option_id INTEGER NOT NULL,
+    voter_count INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (telegram_poll_id, option_id),
+    FOREIGN KEY (telegram_poll_id) REFERENCES telegram_polls(telegram_poll_id) ON DELETE CASCADE,
+    FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_telegram_poll_counts_option ON telegram_poll_counts(option_id);
@@ -0,0 +1,3 @@
+ALTER TABLE polls ADD COLUMN is_native BOOLEAN DEFAULT FALSE;
+
+ALTER TABLE telegram_polls ADD COLUMN voter_count INTEGER DEFAULT 0;
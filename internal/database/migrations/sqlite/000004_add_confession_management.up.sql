@@ -0,0 +1,3 @@
+ALTER TABLE confessions ADD COLUMN is_deleted BOOLEAN DEFAULT FALSE;
+ALTER TABLE confessions ADD COLUMN deleted_at DATETIME;
+ALTER TABLE confessions ADD COLUMN edited_at DATETIME;
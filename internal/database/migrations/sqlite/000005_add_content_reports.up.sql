@@ -0,0 +1,30 @@
+ALTER TABLE confessions ADD COLUMN is_hidden BOOLEAN DEFAULT FALSE;
+ALTER TABLE confession_replies ADD COLUMN is_hidden BOOLEAN DEFAULT FALSE;
+ALTER TABLE whispers ADD COLUMN is_hidden BOOLEAN DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS content_reports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    content_type TEXT NOT NULL,
+    content_id INTEGER NOT NULL,
+    reporter_id BIGINT NOT NULL,
+    reason TEXT DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(content_type, content_id, reporter_id),
+    FOREIGN KEY (reporter_id) REFERENCES users(telegram_id)
+);
+
+CREATE TABLE IF NOT EXISTS content_flags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    content_type TEXT NOT NULL,
+    content_id INTEGER NOT NULL,
+    author_id BIGINT NOT NULL,
+    report_count INTEGER DEFAULT 0,
+    status TEXT DEFAULT 'pending',
+    reviewed_by BIGINT,
+    reviewed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(content_type, content_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_reports_reporter ON content_reports(reporter_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_content_flags_status ON content_flags(status);
@@ -0,0 +1,32 @@
+CREATE VIRTUAL TABLE IF NOT EXISTS confessions_fts USING fts5(
+    content,
+    content='confessions',
+    content_rowid='id',
+    tokenize='unicode61 remove_diacritics 2'
+);
+
+INSERT INTO confessions_fts(confessions_fts) VALUES ('rebuild');
+
+CREATE TRIGGER IF NOT EXISTS confessions_fts_insert AFTER INSERT ON confessions BEGIN
+    INSERT INTO confessions_fts(rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS confessions_fts_delete AFTER DELETE ON confessions BEGIN
+    INSERT INTO confessions_fts(confessions_fts, rowid, content) VALUES ('delete', old.id, old.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS confessions_fts_update AFTER UPDATE OF content ON confessions BEGIN
+    INSERT INTO confessions_fts(confessions_fts, rowid, content) VALUES ('delete', old.id, old.content);
+    INSERT INTO confessions_fts(rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TABLE IF NOT EXISTS confession_tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    confession_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(confession_id, tag),
+    FOREIGN KEY (confession_id) REFERENCES confessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_confession_tags_tag ON confession_tags(tag);
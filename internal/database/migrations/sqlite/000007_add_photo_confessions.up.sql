@@ -0,0 +1,4 @@
+ALTER TABLE confessions ADD COLUMN photo_file_id TEXT DEFAULT '';
+ALTER TABLE confessions ADD COLUMN status TEXT DEFAULT 'published';
+
+CREATE INDEX IF NOT EXISTS idx_confessions_status ON confessions(status);
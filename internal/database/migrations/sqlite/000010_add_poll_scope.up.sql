@@ -0,0 +1,57 @@
+-- SQLite cannot drop NOT NULL in place, so the poll tables are rebuilt.
+-- Children are copied first so dropping the old polls table cascades nothing.
+CREATE TABLE polls_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    author_id BIGINT,
+    scope TEXT NOT NULL DEFAULT 'personal',
+    department TEXT DEFAULT '',
+    question TEXT NOT NULL,
+    expires_at DATETIME,
+    is_closed BOOLEAN DEFAULT FALSE,
+    closed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (author_id) REFERENCES users(telegram_id)
+);
+
+INSERT INTO polls_new (id, author_id, scope, department, question, expires_at, is_closed, closed_at, created_at)
+SELECT id,
+    CASE WHEN author_id = 0 OR question LIKE '[GLOBAL] %' THEN NULL ELSE author_id END,
+    CASE WHEN author_id = 0 OR question LIKE '[GLOBAL] %' THEN 'global' ELSE 'personal' END,
+    '',
+    CASE WHEN question LIKE '[GLOBAL] %' THEN SUBSTR(question, 10) ELSE question END,
+    expires_at, is_closed, closed_at, created_at
+FROM polls;
+
+CREATE TABLE poll_options_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    poll_id INTEGER NOT NULL,
+    option_text TEXT NOT NULL,
+    vote_count INTEGER DEFAULT 0,
+    FOREIGN KEY (poll_id) REFERENCES polls_new(id) ON DELETE CASCADE
+);
+INSERT INTO poll_options_new (id, poll_id, option_text, vote_count)
+SELECT id, poll_id, option_text, vote_count FROM poll_options;
+
+CREATE TABLE poll_votes_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    poll_id INTEGER NOT NULL,
+    telegram_id BIGINT NOT NULL,
+    option_id INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(poll_id, telegram_id),
+    FOREIGN KEY (poll_id) REFERENCES polls_new(id) ON DELETE CASCADE,
+    FOREIGN KEY (telegram_id) REFERENCES users(telegram_id)
+);
+INSERT INTO poll_votes_new (id, poll_id, telegram_id, option_id, created_at)
+SELECT id, poll_id, telegram_id, option_id, created_at FROM poll_votes;
+
+DROP TABLE poll_votes;
+DROP TABLE poll_options;
+DROP TABLE polls;
+
+ALTER TABLE polls_new RENAME TO polls;
+ALTER TABLE poll_options_new RENAME TO poll_options;
+ALTER TABLE poll_votes_new RENAME TO poll_votes;
+
+CREATE INDEX IF NOT EXISTS idx_polls_expiry ON polls(is_closed, expires_at);
+CREATE INDEX IF NOT EXISTS idx_polls_scope ON polls(scope, department, created_at);
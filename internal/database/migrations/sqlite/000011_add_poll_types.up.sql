@@ -0,0 +1,23 @@
+ALTER TABLE polls ADD COLUMN poll_type TEXT NOT NULL DEFAULT 'single';
+ALTER TABLE polls ADD COLUMN max_choices INTEGER DEFAULT 1;
+
+-- A ballot may now hold several rows (multi-choice or ranked), so the
+-- one-row-per-voter constraint moves to one row per voter and option.
+CREATE TABLE poll_votes_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    poll_id INTEGER NOT NULL,
+    telegram_id BIGINT NOT NULL,
+    option_id INTEGER NOT NULL,
+    rank INTEGER DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(poll_id, telegram_id, option_id),
+    FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+    FOREIGN KEY (telegram_id) REFERENCES users(telegram_id)
+);
+INSERT INTO poll_votes_new (id, poll_id, telegram_id, option_id, rank, created_at)
+SELECT id, poll_id, telegram_id, option_id, 1, created_at FROM poll_votes;
+
+DROP TABLE poll_votes;
+ALTER TABLE poll_votes_new RENAME TO poll_votes;
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_voter ON poll_votes(poll_id, telegram_id);
@@ -0,0 +1,3 @@
+ALTER TABLE polls ADD COLUMN karma_awarded BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_polls_author_created ON polls(author_id, created_at);
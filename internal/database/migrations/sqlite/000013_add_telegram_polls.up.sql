@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS telegram_polls (
+    telegram_poll_id TEXT PRIMARY KEY,
+    poll_id INTEGER NOT NULL,
+    chat_id BIGINT NOT NULL,
+    message_id INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_telegram_polls_poll ON telegram_polls(poll_id);
@@ -0,0 +1,3 @@
+ALTER TABLE room_members ADD COLUMN alias TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_room_members_alias ON room_members(room_id, alias);
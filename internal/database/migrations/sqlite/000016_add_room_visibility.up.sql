@@ -0,0 +1,3 @@
+ALTER TABLE rooms ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public';
+ALTER TABLE rooms ADD COLUMN department TEXT NOT NULL DEFAULT '';
+ALTER TABLE rooms ADD COLUMN invite_nonce TEXT NOT NULL DEFAULT '';
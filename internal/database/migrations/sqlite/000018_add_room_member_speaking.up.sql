@@ -0,0 +1,4 @@
+ALTER TABLE room_members ADD COLUMN is_speaking BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE room_members ADD COLUMN is_muted BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE room_members SET is_speaking = TRUE;
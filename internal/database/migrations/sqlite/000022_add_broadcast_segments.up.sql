@@ -0,0 +1,6 @@
+ALTER TABLE broadcasts ADD COLUMN department TEXT NOT NULL DEFAULT '';
+ALTER TABLE broadcasts ADD COLUMN year INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE broadcasts ADD COLUMN gender TEXT NOT NULL DEFAULT '';
+ALTER TABLE broadcasts ADD COLUMN min_level INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE broadcasts ADD COLUMN active_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE broadcasts ADD COLUMN scheduled_at DATETIME;
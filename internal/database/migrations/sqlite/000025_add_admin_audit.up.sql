@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS admin_audit (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id BIGINT NOT NULL,
+    action TEXT NOT NULL,
+    target_id BIGINT NOT NULL DEFAULT 0,
+    details TEXT NOT NULL DEFAULT '{}',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_actor ON admin_audit(actor_id, id);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_target ON admin_audit(target_id, id);
+
+CREATE TRIGGER IF NOT EXISTS admin_audit_no_update BEFORE UPDATE ON admin_audit BEGIN
+    SELECT RAISE(ABORT, 'admin_audit is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS admin_audit_no_delete BEFORE DELETE ON admin_audit BEGIN
+    SELECT RAISE(ABORT, 'admin_audit is append-only');
+END;
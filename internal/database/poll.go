@@ -4,13 +4,27 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/pnj-anonymous-bot/internal/models"
 )
 
-func (d *DB) CreatePoll(ctx context.Context, authorID int64, question string, options []string) (int64, error) {
+var pollColumns = []string{"id", "author_id", "scope", "department", "poll_type", "max_choices", "question",
+	"is_native", "expires_at", "is_closed", "closed_at", "created_at"}
+
+func (d *DB) CreatePoll(ctx context.Context, poll *models.Poll, options []string) (int64, error) {
+	if poll.Scope == "" {
+		poll.Scope = models.PollPersonal
+	}
+	if poll.Type == "" {
+		poll.Type = models.PollSingle
+	}
+	if poll.MaxChoices <= 0 {
+		poll.MaxChoices = 1
+	}
+
 	tx, err := d.BeginTxx(ctx, nil)
 	if err != nil {
 		return 0, err
@@ -18,8 +32,9 @@ func (d *DB) CreatePoll(ctx context.Context, authorID int64, question string, op
 	defer func() { _ = tx.Rollback() }()
 
 	builder := d.Builder.Insert("polls").
-		Columns("author_id", "question", "created_at").
-		Values(authorID, question, time.Now())
+		Columns("author_id", "scope", "department", "poll_type", "max_choices", "question", "is_native", "expires_at", "created_at").
+		Values(poll.AuthorID, string(poll.Scope), poll.Department, string(poll.Type), poll.MaxChoices,
+			poll.Question, poll.IsNative, poll.ExpiresAt, time.Now())
 
 	query, args, err := builder.ToSql()
 	if err != nil {
@@ -57,9 +72,18 @@ func (d *DB) CreatePoll(ctx context.Context, authorID int64, question string, op
 	return pollID, tx.Commit()
 }
 
+// pollOptionsQuery selects a poll's options in creation order. vote_count
+// adds the anonymous answers reported for its native Telegram polls to the
+// ballots cast through the bot.
+func (d *DB) pollOptionsQuery(pollID int64) squirrel.SelectBuilder {
+	return d.Builder.Select("o.id", "o.poll_id", "o.option_text",
+		"o.vote_count + COALESCE((SELECT SUM(c.voter_count) FROM telegram_poll_counts c WHERE c.option_id = o.id), 0) AS vote_count").
+		From("poll_options o").Where("o.poll_id = ?", pollID).OrderBy("o.id ASC")
+}
+
 func (d *DB) GetPoll(ctx context.Context, pollID int64) (*models.Poll, error) {
 	poll := &models.Poll{}
-	builder := d.Builder.Select("id", "author_id", "question", "created_at").
+	builder := d.Builder.Select(pollColumns...).
 		From("polls").Where("id = ?", pollID)
 
 	err := d.GetBuilderContext(ctx, poll, builder)
@@ -70,10 +94,7 @@ func (d *DB) GetPoll(ctx context.Context, pollID int64) (*models.Poll, error) {
 		return nil, err
 	}
 
-	optBuilder := d.Builder.Select("id", "poll_id", "option_text", "vote_count").
-		From("poll_options").Where("poll_id = ?", pollID)
-
-	err = d.SelectBuilderContext(ctx, &poll.Options, optBuilder)
+	err = d.SelectBuilderContext(ctx, &poll.Options, d.pollOptionsQuery(pollID))
 	if err != nil {
 		return nil, err
 	}
@@ -81,13 +102,29 @@ func (d *DB) GetPoll(ctx context.Context, pollID int64) (*models.Poll, error) {
 	return poll, nil
 }
 
-func (d *DB) GetLatestPollsContext(ctx context.Context, limit int) ([]*models.Poll, error) {
+// GetLatestPollsContext returns personal polls plus department polls for the
+// given department. Global polls are listed separately by GetGlobalPolls.
+func (d *DB) GetLatestPollsContext(ctx context.Context, department string, limit int) ([]*models.Poll, error) {
+	builder := d.Builder.Select(pollColumns...).From("polls").
+		Where(squirrel.Or{
+			squirrel.Eq{"scope": string(models.PollPersonal)},
+			squirrel.Eq{"scope": string(models.PollDepartment), "department": department},
+		})
+	return d.selectPolls(ctx, builder, limit)
+}
+
+func (d *DB) GetGlobalPolls(ctx context.Context, limit int) ([]*models.Poll, error) {
+	builder := d.Builder.Select(pollColumns...).From("polls").
+		Where(squirrel.Eq{"scope": string(models.PollGlobal)})
+	return d.selectPolls(ctx, builder, limit)
+}
+
+func (d *DB) selectPolls(ctx context.Context, builder squirrel.SelectBuilder, limit int) ([]*models.Poll, error) {
 	var safeLimit uint64
 	if limit > 0 {
 		safeLimit = uint64(limit)
 	}
-	builder := d.Builder.Select("id", "author_id", "question", "created_at").
-		From("polls").OrderBy("created_at DESC").Limit(safeLimit)
+	builder = builder.OrderBy("created_at DESC").Limit(safeLimit)
 
 	var polls []*models.Poll
 	err := d.SelectBuilderContext(ctx, &polls, builder)
@@ -96,21 +133,53 @@ func (d *DB) GetLatestPollsContext(ctx context.Context, limit int) ([]*models.Po
 	}
 
 	for _, p := range polls {
-		optBuilder := d.Builder.Select("id", "poll_id", "option_text", "vote_count").
-			From("poll_options").Where("poll_id = ?", p.ID)
-		_ = d.SelectBuilderContext(ctx, &p.Options, optBuilder)
+		_ = d.SelectBuilderContext(ctx, &p.Options, d.pollOptionsQuery(p.ID))
 	}
 
 	return polls, nil
 }
 
 func (d *DB) VotePoll(ctx context.Context, pollID, telegramID, optionID int64) error {
+	return d.SubmitPollBallot(ctx, pollID, telegramID, []int64{optionID})
+}
+
+// SubmitPollBallot stores a complete ballot. For ranked polls the slice order
+// is the preference order and only first preferences update vote_count; other
+// poll types store every selection with rank 1.
+func (d *DB) SubmitPollBallot(ctx context.Context, pollID, telegramID int64, optionIDs []int64) error {
 	tx, err := d.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	var poll models.Poll
+	pollQuery, pollArgs, _ := d.Builder.Select(pollColumns...).From("polls").Where("id = ?", pollID).ToSql()
+	err = tx.GetContext(ctx, &poll, pollQuery, pollArgs...)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("polling tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+	if !poll.IsOpen(time.Now()) {
+		return fmt.Errorf("polling ini sudah ditutup")
+	}
+	if poll.IsNative {
+		return fmt.Errorf("polling ini hanya bisa dipilih lewat polling Telegram yang dikirim bot")
+	}
+
+	maxChoices := 1
+	switch poll.Type {
+	case models.PollMulti:
+		maxChoices = poll.MaxChoices
+	case models.PollRanked:
+		maxChoices = len(optionIDs)
+	}
+	if len(optionIDs) == 0 || len(optionIDs) > maxChoices {
+		return fmt.Errorf("jumlah pilihan tidak valid untuk polling ini")
+	}
+
 	var exists bool
 	existsQuery, existsArgs, _ := d.Builder.Select("1").Prefix("SELECT EXISTS(").
 		From("poll_votes").Where("poll_id = ? AND telegram_id = ?", pollID, telegramID).
@@ -124,45 +193,199 @@ func (d *DB) VotePoll(ctx context.Context, pollID, telegramID, optionID int64) e
 		return fmt.Errorf("kamu sudah memberikan suara di polling ini")
 	}
 
-	var optExists bool
-	optExistsQuery, optExistsArgs, _ := d.Builder.Select("1").Prefix("SELECT EXISTS(").
-		From("poll_options").Where("id = ? AND poll_id = ?", optionID, pollID).
-		Suffix(")").ToSql()
-
-	err = tx.QueryRowContext(ctx, optExistsQuery, optExistsArgs...).Scan(&optExists)
-	if err != nil {
+	var validCount int
+	countQuery, countArgs, _ := d.Builder.Select("COUNT(*)").From("poll_options").
+		Where(squirrel.Eq{"poll_id": pollID, "id": optionIDs}).ToSql()
+	if err := tx.QueryRowContext(ctx, countQuery, countArgs...).Scan(&validCount); err != nil {
 		return err
 	}
-	if !optExists {
+	if validCount != len(optionIDs) {
 		return fmt.Errorf("opsi tidak valid untuk polling ini")
 	}
 
-	voteBuilder := d.Builder.Insert("poll_votes").
-		Columns("poll_id", "telegram_id", "option_id", "created_at").
-		Values(pollID, telegramID, optionID, time.Now())
-	voteQuery, voteArgs, _ := voteBuilder.ToSql()
+	now := time.Now()
+	for i, optionID := range optionIDs {
+		rank := 1
+		if poll.Type == models.PollRanked {
+			rank = i + 1
+		}
+		voteQuery, voteArgs, _ := d.Builder.Insert("poll_votes").
+			Columns("poll_id", "telegram_id", "option_id", "rank", "created_at").
+			Values(pollID, telegramID, optionID, rank, now).ToSql()
+		if _, err := tx.ExecContext(ctx, voteQuery, voteArgs...); err != nil {
+			return err
+		}
 
-	_, err = tx.ExecContext(ctx, voteQuery, voteArgs...)
-	if err != nil {
-		return err
+		if poll.Type == models.PollRanked && i > 0 {
+			continue
+		}
+		updateQuery, updateArgs, _ := d.Builder.Update("poll_options").
+			Set("vote_count", squirrel.Expr("vote_count + 1")).
+			Where("id = ?", optionID).ToSql()
+		if _, err := tx.ExecContext(ctx, updateQuery, updateArgs...); err != nil {
+			return err
+		}
 	}
 
-	updateBuilder := d.Builder.Update("poll_options").
-		Set("vote_count", squirrel.Expr("vote_count + 1")).
-		Where("id = ?", optionID)
-	updateQuery, updateArgs, _ := updateBuilder.ToSql()
+	return tx.Commit()
+}
+
+func (d *DB) GetPollBallots(ctx context.Context, pollID int64) ([]models.PollVote, error) {
+	builder := d.Builder.Select("poll_id", "telegram_id", "option_id", "rank").From("poll_votes").
+		Where("poll_id = ?", pollID).OrderBy("telegram_id ASC", "rank ASC")
+
+	var votes []models.PollVote
+	if err := d.SelectBuilderContext(ctx, &votes, builder); err != nil {
+		return nil, fmt.Errorf("failed to get poll ballots: %w", err)
+	}
+	return votes, nil
+}
 
-	_, err = tx.ExecContext(ctx, updateQuery, updateArgs...)
+// pollVotersQuery counts the voters of a poll through the bot (with an extra
+// ballot filter) plus those of its native Telegram polls. Both placeholders
+// take the poll ID.
+const pollVotersQuery = "(SELECT COUNT(DISTINCT telegram_id) FROM poll_votes WHERE poll_id = ?%s) + " +
+	"COALESCE((SELECT SUM(voter_count) FROM telegram_polls WHERE poll_id = ?), 0)"
+
+func (d *DB) GetPollVoteCountContext(ctx context.Context, pollID int64) (int, error) {
+	var count int
+	builder := d.Builder.Select().Column(squirrel.Expr(fmt.Sprintf(pollVotersQuery, ""), pollID, pollID))
+	err := d.GetBuilderContext(ctx, &count, builder)
+	return count, err
+}
+
+func (d *DB) ClosePoll(ctx context.Context, pollID int64) error {
+	builder := d.Builder.Update("polls").
+		Set("is_closed", true).
+		Set("closed_at", time.Now()).
+		Where("id = ? AND is_closed = FALSE", pollID)
+
+	res, err := d.ExecBuilderContext(ctx, builder)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to close poll: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
 	}
+	return nil
+}
 
-	return tx.Commit()
+func (d *DB) GetExpiredPolls(ctx context.Context, now time.Time) ([]*models.Poll, error) {
+	builder := d.Builder.Select(pollColumns...).From("polls").
+		Where("is_closed = FALSE AND expires_at IS NOT NULL AND expires_at <= ?", now).
+		OrderBy("expires_at ASC")
+
+	var polls []*models.Poll
+	if err := d.SelectBuilderContext(ctx, &polls, builder); err != nil {
+		return nil, fmt.Errorf("failed to get expired polls: %w", err)
+	}
+	return polls, nil
 }
 
-func (d *DB) GetPollVoteCountContext(ctx context.Context, pollID int64) (int, error) {
+func (d *DB) GetPollVoters(ctx context.Context, pollID int64) ([]int64, error) {
+	builder := d.Builder.Select("DISTINCT telegram_id").From("poll_votes").Where("poll_id = ?", pollID)
+
+	var voters []int64
+	err := d.SelectBuilderContext(ctx, &voters, builder)
+	return voters, err
+}
+
+var pollBreakdownColumns = map[string]string{
+	"department": "COALESCE(u.department, '')",
+	"year":       "CAST(COALESCE(u.year, 0) AS TEXT)",
+	"gender":     "COALESCE(u.gender, '')",
+}
+
+// GetPollBreakdown groups the first-preference votes of a poll by a voter
+// profile column (department, year or gender). It also returns the number of
+// distinct voters per bucket, which differs from the vote sum on multi-choice
+// polls.
+func (d *DB) GetPollBreakdown(ctx context.Context, pollID int64, dimension string) ([]models.PollBreakdownCount, map[string]int, error) {
+	column, ok := pollBreakdownColumns[dimension]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown poll breakdown dimension %q", dimension)
+	}
+
+	builder := d.Builder.Select(column+" AS bucket", "v.option_id", "COUNT(*) AS votes").
+		From("poll_votes v").
+		Join("users u ON u.telegram_id = v.telegram_id").
+		Where("v.poll_id = ? AND v.rank = 1", pollID).
+		GroupBy("bucket", "v.option_id")
+
+	var counts []models.PollBreakdownCount
+	if err := d.SelectBuilderContext(ctx, &counts, builder); err != nil {
+		return nil, nil, fmt.Errorf("failed to get poll breakdown: %w", err)
+	}
+
+	votersBuilder := d.Builder.Select(column+" AS bucket", "COUNT(DISTINCT v.telegram_id) AS votes").
+		From("poll_votes v").
+		Join("users u ON u.telegram_id = v.telegram_id").
+		Where("v.poll_id = ?", pollID).
+		GroupBy("bucket")
+
+	var totals []models.PollBreakdownCount
+	if err := d.SelectBuilderContext(ctx, &totals, votersBuilder); err != nil {
+		return nil, nil, fmt.Errorf("failed to get poll breakdown voters: %w", err)
+	}
+
+	voters := make(map[string]int, len(totals))
+	for _, t := range totals {
+		voters[t.Bucket] = t.Votes
+	}
+	return counts, voters, nil
+}
+
+func (d *DB) GetUserPollCountSince(ctx context.Context, telegramID int64, since time.Time) (int, error) {
 	var count int
-	builder := d.Builder.Select("COUNT(*)").From("poll_votes").Where("poll_id = ?", pollID)
+	builder := d.Builder.Select("COUNT(*)").From("polls").
+		Where("author_id = ? AND created_at > ?", telegramID, since)
+
 	err := d.GetBuilderContext(ctx, &count, builder)
 	return count, err
 }
+
+// FindOpenPollByQuestion returns the ID of an open poll in the same scope and
+// department created since the given time with the same question (ignoring
+// case), or 0 when there is none.
+func (d *DB) FindOpenPollByQuestion(ctx context.Context, scope models.PollScope, department, question string, since time.Time) (int64, error) {
+	if scope == "" {
+		scope = models.PollPersonal
+	}
+
+	var id int64
+	builder := d.Builder.Select("id").From("polls").
+		Where("scope = ? AND COALESCE(department, '') = ?", string(scope), department).
+		Where("LOWER(question) = LOWER(?) AND is_closed = FALSE AND created_at > ?", strings.TrimSpace(question), since).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now()).
+		OrderBy("id DESC").Limit(1)
+
+	err := d.GetBuilderContext(ctx, &id, builder)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// ClaimPollKarma marks a poll as rewarded once it has at least minVoters
+// distinct voters besides its author and returns the author. It reports false when the poll
+// has no author, too few voters, or was already rewarded.
+func (d *DB) ClaimPollKarma(ctx context.Context, pollID int64, minVoters int) (int64, bool, error) {
+	builder := d.Builder.Update("polls").
+		Set("karma_awarded", true).
+		Where("id = ? AND karma_awarded = FALSE AND author_id IS NOT NULL", pollID).
+		Where("("+fmt.Sprintf(pollVotersQuery, " AND telegram_id <> polls.author_id")+") >= ?", pollID, pollID, minVoters)
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to claim poll karma: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return 0, false, nil
+	}
+
+	var authorID int64
+	if err := d.GetBuilderContext(ctx, &authorID, d.Builder.Select("author_id").From("polls").Where("id = ?", pollID)); err != nil {
+		return 0, false, err
+	}
+	return authorID, true, nil
+}
@@ -134,7 +134,7 @@ func (d *DB) GetUserStats(ctx context.Context, telegramID int64) (totalChats int
 	chatsQuery := d.Builder.Select("COUNT(*)").From("chat_sessions").Where("user1_id = ? OR user2_id = ?", telegramID, telegramID)
 	_ = d.GetBuilderContext(ctx, &totalChats, chatsQuery)
 
-	confQuery := d.Builder.Select("COUNT(*)").From("confessions").Where("author_id = ?", telegramID)
+	confQuery := d.Builder.Select("COUNT(*)").From("confessions").Where("author_id = ? AND is_deleted = FALSE", telegramID)
 	_ = d.GetBuilderContext(ctx, &totalConfessions, confQuery)
 
 	reactQuery := d.Builder.Select("COUNT(*)").From("confession_reactions cr").
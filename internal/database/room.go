@@ -6,17 +6,25 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/pnj-anonymous-bot/internal/models"
 )
 
-func (d *DB) GetActiveRooms(ctx context.Context) ([]*models.Room, error) {
+// GetVisibleRooms lists the active circles a user may browse: public ones,
+// those restricted to their department, and any circle they help run.
+func (d *DB) GetVisibleRooms(ctx context.Context, telegramID int64, department string) ([]*models.Room, error) {
 	subQuery := d.Builder.Select("COUNT(*)").From("room_members").Where("room_id = r.id")
 	q, _, _ := subQuery.ToSql()
 
-	builder := d.Builder.Select("r.id", "r.slug", "r.name", "r.description", "r.is_active", "r.created_at",
+	builder := d.Builder.Select("r.id", "r.slug", "r.name", "r.description", "r.is_active", "r.visibility", "r.department", "r.invite_nonce", "r.slow_mode_seconds", "r.created_by", "r.created_at",
 		"("+q+") as member_count").
 		From("rooms r").
 		Where("r.is_active = TRUE").
+		Where(squirrel.Or{
+			squirrel.Eq{"r.visibility": string(models.RoomPublic)},
+			squirrel.Eq{"r.visibility": string(models.RoomDepartmentOnly), "r.department": department},
+			squirrel.Expr("r.id IN (SELECT room_id FROM room_roles WHERE telegram_id = ?)", telegramID),
+		}).
 		OrderBy("member_count DESC")
 
 	var rooms []*models.Room
@@ -26,16 +34,16 @@ func (d *DB) GetActiveRooms(ctx context.Context) ([]*models.Room, error) {
 	}
 	err = d.SelectContext(ctx, &rooms, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active rooms: %w", err)
+		return nil, fmt.Errorf("failed to get visible rooms: %w", err)
 	}
 
 	return rooms, nil
 }
 
-func (d *DB) CreateRoom(ctx context.Context, slug, name, description string) (*models.Room, error) {
+func (d *DB) CreateRoom(ctx context.Context, slug, name, description string, createdBy int64) (*models.Room, error) {
 	builder := d.Builder.Insert("rooms").
-		Columns("slug", "name", "description", "is_active", "created_at").
-		Values(slug, name, description, true, time.Now())
+		Columns("slug", "name", "description", "is_active", "created_by", "created_at").
+		Values(slug, name, description, true, createdBy, time.Now())
 
 	_, err := d.ExecBuilderContext(ctx, builder)
 	if err != nil {
@@ -49,7 +57,7 @@ func (d *DB) GetRoomBySlug(ctx context.Context, slug string) (*models.Room, erro
 	subQuery := d.Builder.Select("COUNT(*)").From("room_members").Where("room_id = rooms.id")
 	q, _, _ := subQuery.ToSql()
 
-	builder := d.Builder.Select("id", "slug", "name", "description", "is_active", "created_at",
+	builder := d.Builder.Select("id", "slug", "name", "description", "is_active", "visibility", "department", "invite_nonce", "slow_mode_seconds", "created_by", "created_at",
 		"("+q+") as member_count").
 		From("rooms").
 		Where("slug = ?", slug)
@@ -74,7 +82,7 @@ func (d *DB) GetRoomByID(ctx context.Context, id int64) (*models.Room, error) {
 	subQuery := d.Builder.Select("COUNT(*)").From("room_members").Where("room_id = rooms.id")
 	q, _, _ := subQuery.ToSql()
 
-	builder := d.Builder.Select("id", "slug", "name", "description", "is_active", "created_at",
+	builder := d.Builder.Select("id", "slug", "name", "description", "is_active", "visibility", "department", "invite_nonce", "slow_mode_seconds", "created_by", "created_at",
 		"("+q+") as member_count").
 		From("rooms").
 		Where("id = ?", id)
@@ -94,15 +102,82 @@ func (d *DB) GetRoomByID(ctx context.Context, id int64) (*models.Room, error) {
 	return r, nil
 }
 
-func (d *DB) AddRoomMember(ctx context.Context, roomID int64, telegramID int64) error {
+func (d *DB) UpdateRoomVisibility(ctx context.Context, roomID int64, visibility models.RoomVisibility, department string) error {
+	builder := d.Builder.Update("rooms").
+		Set("visibility", string(visibility)).
+		Set("department", department).
+		Where("id = ?", roomID)
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to update room visibility: %w", err)
+	}
+	return nil
+}
+
+// SetRoomInviteNonce replaces the nonce embedded in the room's invite codes;
+// an empty nonce revokes every outstanding code.
+func (d *DB) SetRoomInviteNonce(ctx context.Context, roomID int64, nonce string) error {
+	builder := d.Builder.Update("rooms").Set("invite_nonce", nonce).Where("id = ?", roomID)
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to set room invite: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) AddRoomMember(ctx context.Context, roomID int64, telegramID int64, alias string) error {
 	builder := d.Builder.Insert("room_members").
-		Columns("room_id", "telegram_id", "joined_at").
-		Values(roomID, telegramID, time.Now())
+		Columns("room_id", "telegram_id", "alias", "joined_at").
+		Values(roomID, telegramID, alias, time.Now())
 
 	_, err := d.InsertIgnoreContext(ctx, builder, "room_id, telegram_id")
 	return err
 }
 
+// GetRoomMemberAlias returns the member's alias in the room and whether they
+// are a member at all. Members who joined before aliases existed have none.
+func (d *DB) GetRoomMemberAlias(ctx context.Context, roomID, telegramID int64) (string, bool, error) {
+	var alias sql.NullString
+	builder := d.Builder.Select("alias").From("room_members").
+		Where("room_id = ? AND telegram_id = ?", roomID, telegramID)
+
+	err := d.GetBuilderContext(ctx, &alias, builder)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get room member alias: %w", err)
+	}
+	return alias.String, true, nil
+}
+
+func (d *DB) SetRoomMemberAlias(ctx context.Context, roomID, telegramID int64, alias string) error {
+	builder := d.Builder.Update("room_members").Set("alias", alias).
+		Where("room_id = ? AND telegram_id = ?", roomID, telegramID)
+	_, err := d.ExecBuilderContext(ctx, builder)
+	return err
+}
+
+func (d *DB) IsRoomAliasTaken(ctx context.Context, roomID int64, alias string) (bool, error) {
+	var exists bool
+	builder := d.Builder.Select("1").Prefix("SELECT EXISTS(").
+		From("room_members").Where("room_id = ? AND alias = ?", roomID, alias).
+		Suffix(")")
+
+	err := d.GetBuilderContext(ctx, &exists, builder)
+	return exists, err
+}
+
+func (d *DB) IsRoomMember(ctx context.Context, roomID, telegramID int64) (bool, error) {
+	var exists bool
+	builder := d.Builder.Select("1").Prefix("SELECT EXISTS(").
+		From("room_members").Where("room_id = ? AND telegram_id = ?", roomID, telegramID).
+		Suffix(")")
+
+	err := d.GetBuilderContext(ctx, &exists, builder)
+	return exists, err
+}
+
 func (d *DB) RemoveRoomMember(ctx context.Context, roomID int64, telegramID int64) error {
 	builder := d.Builder.Delete("room_members").Where("room_id = ? AND telegram_id = ?", roomID, telegramID)
 	_, err := d.ExecBuilderContext(ctx, builder)
@@ -122,12 +197,69 @@ func (d *DB) GetRoomMembers(ctx context.Context, roomID int64) ([]int64, error)
 	return ids, err
 }
 
+// GetRoomRecipients lists the members who have not muted the room.
+func (d *DB) GetRoomRecipients(ctx context.Context, roomID int64) ([]int64, error) {
+	var ids []int64
+	builder := d.Builder.Select("telegram_id").From("room_members").
+		Where("room_id = ? AND is_muted = ?", roomID, false)
+	err := d.SelectBuilderContext(ctx, &ids, builder)
+	return ids, err
+}
+
+func (d *DB) CountUserRooms(ctx context.Context, telegramID int64) (int, error) {
+	var count int
+	builder := d.Builder.Select("COUNT(*)").From("room_members").Where("telegram_id = ?", telegramID)
+	err := d.GetBuilderContext(ctx, &count, builder)
+	return count, err
+}
+
+// GetUserRooms lists every circle the user belongs to, oldest membership
+// first, with their speaking and mute flags.
+func (d *DB) GetUserRooms(ctx context.Context, telegramID int64) ([]*models.Room, error) {
+	var rooms []*models.Room
+	builder := d.Builder.Select("r.id", "r.slug", "r.name", "r.description", "r.is_active", "r.visibility", "r.department", "r.invite_nonce", "r.slow_mode_seconds", "r.created_by", "r.created_at",
+		"rm.is_speaking AS speaking", "rm.is_muted AS muted").
+		From("rooms r").
+		Join("room_members rm ON r.id = rm.room_id").
+		Where("rm.telegram_id = ?", telegramID).
+		OrderBy("rm.joined_at", "r.id")
+
+	if err := d.SelectBuilderContext(ctx, &rooms, builder); err != nil {
+		return nil, fmt.Errorf("failed to get user rooms: %w", err)
+	}
+	return rooms, nil
+}
+
+// SetSpeakingRoom marks roomID as the circle the user's messages go to and
+// clears the flag on their other memberships.
+func (d *DB) SetSpeakingRoom(ctx context.Context, telegramID, roomID int64) error {
+	builder := d.Builder.Update("room_members").
+		Set("is_speaking", squirrel.Expr("room_id = ?", roomID)).
+		Where("telegram_id = ?", telegramID)
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to set speaking room: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) SetRoomMemberMuted(ctx context.Context, roomID, telegramID int64, muted bool) error {
+	builder := d.Builder.Update("room_members").Set("is_muted", muted).
+		Where("room_id = ? AND telegram_id = ?", roomID, telegramID)
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to mute room: %w", err)
+	}
+	return nil
+}
+
+// GetUserRoom returns the circle the user is currently speaking in.
 func (d *DB) GetUserRoom(ctx context.Context, telegramID int64) (*models.Room, error) {
 	r := &models.Room{}
-	builder := d.Builder.Select("r.id", "r.slug", "r.name", "r.description", "r.is_active", "r.created_at").
+	builder := d.Builder.Select("r.id", "r.slug", "r.name", "r.description", "r.is_active", "r.visibility", "r.department", "r.invite_nonce", "r.slow_mode_seconds", "r.created_by", "r.created_at").
 		From("rooms r").
 		Join("room_members rm ON r.id = rm.room_id").
-		Where("rm.telegram_id = ?", telegramID)
+		Where("rm.telegram_id = ? AND rm.is_speaking = ?", telegramID, true)
 
 	err := d.GetBuilderContext(ctx, r, builder)
 	if err == sql.ErrNoRows {
@@ -138,3 +270,12 @@ func (d *DB) GetUserRoom(ctx context.Context, telegramID int64) (*models.Room, e
 	}
 	return r, nil
 }
+
+func (d *DB) SetRoomSlowMode(ctx context.Context, roomID int64, seconds int) error {
+	builder := d.Builder.Update("rooms").Set("slow_mode_seconds", seconds).Where("id = ?", roomID)
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to set room slow mode: %w", err)
+	}
+	return nil
+}
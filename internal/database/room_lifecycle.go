@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+var roomColumns = []string{"id", "slug", "name", "description", "is_active", "visibility", "department", "invite_nonce", "slow_mode_seconds", "created_by", "created_at"}
+
+func (d *DB) TouchRoom(ctx context.Context, roomID int64, at time.Time) error {
+	builder := d.Builder.Update("rooms").Set("last_message_at", at).Where("id = ?", roomID)
+	_, err := d.ExecBuilderContext(ctx, builder)
+	return err
+}
+
+// CountOwnedRooms counts the active circles created by telegramID.
+func (d *DB) CountOwnedRooms(ctx context.Context, telegramID int64) (int, error) {
+	var count int
+	builder := d.Builder.Select("COUNT(*)").From("rooms").
+		Where("created_by = ? AND is_active = ?", telegramID, true)
+	err := d.GetBuilderContext(ctx, &count, builder)
+	return count, err
+}
+
+// CountRoomsCreatedSince counts circles telegramID created after since,
+// including archived ones.
+func (d *DB) CountRoomsCreatedSince(ctx context.Context, telegramID int64, since time.Time) (int, error) {
+	var count int
+	builder := d.Builder.Select("COUNT(*)").From("rooms").
+		Where("created_by = ? AND created_at > ?", telegramID, since)
+	err := d.GetBuilderContext(ctx, &count, builder)
+	return count, err
+}
+
+// ArchiveInactiveRooms deactivates every active circle whose last message (or
+// creation, if it never had one) is older than cutoff, or that was created
+// before cutoff and has no members left, and returns them.
+func (d *DB) ArchiveInactiveRooms(ctx context.Context, cutoff time.Time) ([]*models.Room, error) {
+	builder := d.Builder.Select(roomColumns...).From("rooms").
+		Where("is_active = ?", true).
+		Where(squirrel.Or{
+			squirrel.Expr("COALESCE(last_message_at, created_at) < ?", cutoff),
+			squirrel.Expr("created_at < ? AND NOT EXISTS (SELECT 1 FROM room_members m WHERE m.room_id = rooms.id)", cutoff),
+		})
+
+	var rooms []*models.Room
+	if err := d.SelectBuilderContext(ctx, &rooms, builder); err != nil {
+		return nil, fmt.Errorf("failed to find inactive rooms: %w", err)
+	}
+	if len(rooms) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, len(rooms))
+	for i, r := range rooms {
+		ids[i] = r.ID
+	}
+	update := d.Builder.Update("rooms").Set("is_active", false).Where(squirrel.Eq{"id": ids})
+	if _, err := d.ExecBuilderContext(ctx, update); err != nil {
+		return nil, fmt.Errorf("failed to archive rooms: %w", err)
+	}
+	return rooms, nil
+}
+
+// RestoreRoom reactivates an archived circle and counts the restore as
+// activity, so it is not archived again by the next sweep.
+func (d *DB) RestoreRoom(ctx context.Context, roomID int64) error {
+	builder := d.Builder.Update("rooms").
+		Set("is_active", true).
+		Set("last_message_at", time.Now()).
+		Where("id = ?", roomID)
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to restore room: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) GetArchivedRooms(ctx context.Context) ([]*models.Room, error) {
+	builder := d.Builder.Select(roomColumns...).From("rooms").
+		Where("is_active = ?", false).
+		OrderBy("id DESC")
+
+	var rooms []*models.Room
+	if err := d.SelectBuilderContext(ctx, &rooms, builder); err != nil {
+		return nil, fmt.Errorf("failed to get archived rooms: %w", err)
+	}
+	return rooms, nil
+}
+
+// DeleteRoom removes a circle along with its members, roles and history.
+func (d *DB) DeleteRoom(ctx context.Context, roomID int64) error {
+	tx, err := d.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, table := range []string{"room_members", "room_roles", "room_mutes", "room_kicks", "room_pins", "room_messages"} {
+		query, args, _ := d.Builder.Delete(table).Where("room_id = ?", roomID).ToSql()
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+
+	query, args, _ := d.Builder.Delete("rooms").Where("id = ?", roomID).ToSql()
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete room: %w", err)
+	}
+	return tx.Commit()
+}
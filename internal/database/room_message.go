@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+var roomMessageColumns = []string{"id", "room_id", "sender_id", "alias", "content", "media_type", "file_id", "is_removed", "created_at"}
+
+func (d *DB) AddRoomMessage(ctx context.Context, m *models.RoomMessage) (int64, error) {
+	builder := d.Builder.Insert("room_messages").
+		Columns("room_id", "sender_id", "alias", "content", "media_type", "file_id", "is_removed", "created_at").
+		Values(m.RoomID, m.SenderID, m.Alias, m.Content, m.MediaType, m.FileID, false, time.Now())
+
+	id, err := d.InsertGetIDContext(ctx, builder, "id")
+	if err != nil {
+		return 0, fmt.Errorf("failed to add room message: %w", err)
+	}
+	return id, nil
+}
+
+// PruneRoomMessages keeps only the newest keep messages of a room (all of
+// them when keep is not positive) and drops anything older than the
+// retention cutoff.
+func (d *DB) PruneRoomMessages(ctx context.Context, roomID int64, keep int, cutoff time.Time) error {
+	builder := d.Builder.Delete("room_messages").Where("room_id = ?", roomID)
+	if keep > 0 {
+		builder = builder.Where("(created_at < ? OR id NOT IN (SELECT id FROM room_messages WHERE room_id = ? ORDER BY id DESC LIMIT ?))",
+			cutoff, roomID, keep)
+	} else {
+		builder = builder.Where("created_at < ?", cutoff)
+	}
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to prune room messages: %w", err)
+	}
+	return nil
+}
+
+// GetRoomHistory returns up to limit visible messages older than beforeID
+// (or the newest ones when beforeID is 0), newest first.
+func (d *DB) GetRoomHistory(ctx context.Context, roomID, beforeID int64, limit int, since time.Time) ([]*models.RoomMessage, error) {
+	builder := d.Builder.Select(roomMessageColumns...).From("room_messages").
+		Where("room_id = ? AND is_removed = ? AND created_at >= ?", roomID, false, since).
+		OrderBy("id DESC").
+		Limit(uint64(limit))
+	if beforeID > 0 {
+		builder = builder.Where("id < ?", beforeID)
+	}
+
+	var messages []*models.RoomMessage
+	if err := d.SelectBuilderContext(ctx, &messages, builder); err != nil {
+		return nil, fmt.Errorf("failed to get room history: %w", err)
+	}
+	return messages, nil
+}
+
+func (d *DB) RemoveRoomMessage(ctx context.Context, roomID, messageID int64) error {
+	builder := d.Builder.Update("room_messages").Set("is_removed", true).
+		Where("id = ? AND room_id = ?", messageID, roomID)
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to remove room message: %w", err)
+	}
+	return nil
+}
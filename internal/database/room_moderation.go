@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+func (d *DB) GetRoomRole(ctx context.Context, roomID, telegramID int64) (models.RoomRole, error) {
+	var role string
+	builder := d.Builder.Select("role").From("room_roles").
+		Where("room_id = ? AND telegram_id = ?", roomID, telegramID)
+
+	err := d.GetBuilderContext(ctx, &role, builder)
+	if err == sql.ErrNoRows {
+		return models.RoomRoleMember, nil
+	}
+	if err != nil {
+		return models.RoomRoleMember, fmt.Errorf("failed to get room role: %w", err)
+	}
+	return models.RoomRole(role), nil
+}
+
+func (d *DB) SetRoomRole(ctx context.Context, roomID, telegramID int64, role models.RoomRole) error {
+	builder := d.Builder.Insert("room_roles").
+		Columns("room_id", "telegram_id", "role", "created_at").
+		Values(roomID, telegramID, string(role), time.Now())
+
+	if _, err := d.InsertReplaceContext(ctx, builder, "room_id, telegram_id", "role"); err != nil {
+		return fmt.Errorf("failed to set room role: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) RemoveRoomRole(ctx context.Context, roomID, telegramID int64) error {
+	builder := d.Builder.Delete("room_roles").Where("room_id = ? AND telegram_id = ?", roomID, telegramID)
+	_, err := d.ExecBuilderContext(ctx, builder)
+	return err
+}
+
+func (d *DB) MuteRoomMember(ctx context.Context, roomID, telegramID int64, until time.Time, mutedBy int64) error {
+	builder := d.Builder.Insert("room_mutes").
+		Columns("room_id", "telegram_id", "muted_until", "muted_by", "created_at").
+		Values(roomID, telegramID, until, mutedBy, time.Now())
+
+	if _, err := d.InsertReplaceContext(ctx, builder, "room_id, telegram_id", "muted_until", "muted_by", "created_at"); err != nil {
+		return fmt.Errorf("failed to mute room member: %w", err)
+	}
+	return nil
+}
+
+// GetRoomMuteUntil returns when the member's mute ends, or nil when they are
+// not muted at the given time.
+func (d *DB) GetRoomMuteUntil(ctx context.Context, roomID, telegramID int64, now time.Time) (*time.Time, error) {
+	var until time.Time
+	builder := d.Builder.Select("muted_until").From("room_mutes").
+		Where("room_id = ? AND telegram_id = ? AND muted_until > ?", roomID, telegramID, now)
+
+	err := d.GetBuilderContext(ctx, &until, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room mute: %w", err)
+	}
+	return &until, nil
+}
+
+func (d *DB) AddRoomKick(ctx context.Context, roomID, telegramID, kickedBy int64) error {
+	builder := d.Builder.Insert("room_kicks").
+		Columns("room_id", "telegram_id", "kicked_by", "created_at").
+		Values(roomID, telegramID, kickedBy, time.Now())
+
+	if _, err := d.InsertIgnoreContext(ctx, builder, "room_id, telegram_id"); err != nil {
+		return fmt.Errorf("failed to record room kick: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) IsKickedFromRoom(ctx context.Context, roomID, telegramID int64) (bool, error) {
+	var exists bool
+	builder := d.Builder.Select("1").Prefix("SELECT EXISTS(").
+		From("room_kicks").Where("room_id = ? AND telegram_id = ?", roomID, telegramID).
+		Suffix(")")
+
+	err := d.GetBuilderContext(ctx, &exists, builder)
+	return exists, err
+}
+
+func (d *DB) AddRoomPin(ctx context.Context, roomID int64, content string, pinnedBy int64) error {
+	builder := d.Builder.Insert("room_pins").
+		Columns("room_id", "content", "pinned_by", "created_at").
+		Values(roomID, content, pinnedBy, time.Now())
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to pin room message: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) GetLatestRoomPin(ctx context.Context, roomID int64) (*models.RoomPin, error) {
+	pin := &models.RoomPin{}
+	builder := d.Builder.Select("id", "room_id", "content", "pinned_by", "created_at").
+		From("room_pins").Where("room_id = ?", roomID).
+		OrderBy("created_at DESC", "id DESC").Limit(1)
+
+	err := d.GetBuilderContext(ctx, pin, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room pin: %w", err)
+	}
+	return pin, nil
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+// GrantStaffRole gives a user a staff role. It reports whether the user did
+// not have the role yet.
+func (d *DB) GrantStaffRole(ctx context.Context, telegramID int64, role models.StaffRole, grantedBy int64) (bool, error) {
+	builder := d.Builder.Insert("staff_roles").
+		Columns("telegram_id", "role", "granted_by", "created_at").
+		Values(telegramID, string(role), grantedBy, time.Now())
+
+	res, err := d.InsertIgnoreContext(ctx, builder, "telegram_id, role")
+	if err != nil {
+		return false, fmt.Errorf("failed to grant staff role: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RevokeStaffRole removes a staff role. It reports whether the user had it.
+func (d *DB) RevokeStaffRole(ctx context.Context, telegramID int64, role models.StaffRole) (bool, error) {
+	builder := d.Builder.Delete("staff_roles").
+		Where("telegram_id = ? AND role = ?", telegramID, string(role))
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke staff role: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (d *DB) GetStaffRoles(ctx context.Context, telegramID int64) ([]models.StaffRole, error) {
+	builder := d.Builder.Select("role").From("staff_roles").
+		Where("telegram_id = ?", telegramID).
+		OrderBy("role")
+
+	var roles []models.StaffRole
+	if err := d.SelectBuilderContext(ctx, &roles, builder); err != nil {
+		return nil, fmt.Errorf("failed to get staff roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (d *DB) GetStaffMembers(ctx context.Context) ([]*models.StaffMember, error) {
+	builder := d.Builder.Select("telegram_id", "role", "granted_by", "created_at").
+		From("staff_roles").
+		OrderBy("role", "created_at")
+
+	var members []*models.StaffMember
+	if err := d.SelectBuilderContext(ctx, &members, builder); err != nil {
+		return nil, fmt.Errorf("failed to get staff members: %w", err)
+	}
+	return members, nil
+}
+
+// GetStaffWithRoles returns the distinct users holding any of roles.
+func (d *DB) GetStaffWithRoles(ctx context.Context, roles []models.StaffRole) ([]int64, error) {
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = string(r)
+	}
+	builder := d.Builder.Select("DISTINCT telegram_id").From("staff_roles").
+		Where(squirrel.Eq{"role": names}).
+		OrderBy("telegram_id")
+
+	var ids []int64
+	if err := d.SelectBuilderContext(ctx, &ids, builder); err != nil {
+		return nil, fmt.Errorf("failed to get staff: %w", err)
+	}
+	return ids, nil
+}
+
+func (d *DB) CountStaffRole(ctx context.Context, role models.StaffRole) (int, error) {
+	var count int
+	builder := d.Builder.Select("COUNT(*)").From("staff_roles").Where("role = ?", string(role))
+	if err := d.GetBuilderContext(ctx, &count, builder); err != nil {
+		return 0, fmt.Errorf("failed to count staff role: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+var telegramPollColumns = []string{"telegram_poll_id", "poll_id", "chat_id", "message_id", "created_at"}
+
+func (d *DB) SaveTelegramPoll(ctx context.Context, tp *models.TelegramPoll) error {
+	builder := d.Builder.Insert("telegram_polls").
+		Columns("telegram_poll_id", "poll_id", "chat_id", "message_id", "created_at").
+		Values(tp.TelegramPollID, tp.PollID, tp.ChatID, tp.MessageID, time.Now())
+
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to save telegram poll: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) GetTelegramPoll(ctx context.Context, telegramPollID string) (*models.TelegramPoll, error) {
+	var tp models.TelegramPoll
+	builder := d.Builder.Select(telegramPollColumns...).From("telegram_polls").
+		Where("telegram_poll_id = ?", telegramPollID)
+
+	err := d.GetBuilderContext(ctx, &tp, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get telegram poll: %w", err)
+	}
+	return &tp, nil
+}
+
+func (d *DB) GetTelegramPolls(ctx context.Context, pollID int64) ([]*models.TelegramPoll, error) {
+	builder := d.Builder.Select(telegramPollColumns...).From("telegram_polls").
+		Where("poll_id = ?", pollID)
+
+	var polls []*models.TelegramPoll
+	if err := d.SelectBuilderContext(ctx, &polls, builder); err != nil {
+		return nil, fmt.Errorf("failed to get telegram polls: %w", err)
+	}
+	return polls, nil
+}
+
+// GetChatTelegramPoll returns the native poll sent to chatID for pollID, or
+// nil when that chat never received one.
+func (d *DB) GetChatTelegramPoll(ctx context.Context, pollID, chatID int64) (*models.TelegramPoll, error) {
+	var tp models.TelegramPoll
+	builder := d.Builder.Select(telegramPollColumns...).From("telegram_polls").
+		Where("poll_id = ? AND chat_id = ?", pollID, chatID).
+		OrderBy("created_at DESC").
+		Limit(1)
+
+	err := d.GetBuilderContext(ctx, &tp, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get telegram poll: %w", err)
+	}
+	return &tp, nil
+}
+
+// SetTelegramPollCounts stores the latest anonymous voter count of one native
+// Telegram poll and of each of its options.
+func (d *DB) SetTelegramPollCounts(ctx context.Context, telegramPollID string, voters int, counts map[int64]int) error {
+	builder := d.Builder.Update("telegram_polls").
+		Set("voter_count", voters).
+		Where("telegram_poll_id = ?", telegramPollID)
+	if _, err := d.ExecBuilderContext(ctx, builder); err != nil {
+		return fmt.Errorf("failed to save telegram poll voters: %w", err)
+	}
+
+	now := time.Now()
+	for optionID, count := range counts {
+		builder := d.Builder.Insert("telegram_poll_counts").
+			Columns("telegram_poll_id", "option_id", "voter_count", "updated_at").
+			Values(telegramPollID, optionID, count, now)
+		if _, err := d.InsertReplaceContext(ctx, builder, "telegram_poll_id, option_id", "voter_count", "updated_at"); err != nil {
+			return fmt.Errorf("failed to save telegram poll counts: %w", err)
+		}
+	}
+	return nil
+}
@@ -26,9 +26,9 @@ func (d *DB) GetUser(ctx context.Context, telegramID int64) (*models.User, error
 	user := &models.User{}
 	builder := d.Builder.Select(
 		"id", "telegram_id", "email", "gender", "department", "year",
-		"display_name", "karma", "is_verified", "is_banned",
+		"display_name", "karma", "is_verified", "is_banned", "is_reachable",
 		"report_count", "total_chats", "level", "points", "exp",
-		"daily_streak", "last_active_at", "created_at", "updated_at",
+		"daily_streak", "allow_chat_requests", "last_active_at", "created_at", "updated_at",
 	).From("users").Where("telegram_id = ?", telegramID)
 
 	err := d.GetBuilderContext(ctx, user, builder)
@@ -121,6 +121,32 @@ func (d *DB) UpdateUserBanned(ctx context.Context, telegramID int64, banned bool
 	return err
 }
 
+// SetUserReachable records whether the bot can still message the user. It
+// reports whether the flag actually changed.
+func (d *DB) SetUserReachable(ctx context.Context, telegramID int64, reachable bool) (bool, error) {
+	builder := d.Builder.Update("users").
+		Set("is_reachable", reachable).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"telegram_id": telegramID, "is_reachable": !reachable})
+
+	res, err := d.ExecBuilderContext(ctx, builder)
+	if err != nil {
+		return false, fmt.Errorf("failed to update user reachability: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (d *DB) UpdateUserAllowChatRequests(ctx context.Context, telegramID int64, allow bool) error {
+	builder := d.Builder.Update("users").
+		Set("allow_chat_requests", allow).
+		Set("updated_at", time.Now()).
+		Where("telegram_id = ?", telegramID)
+
+	_, err := d.ExecBuilderContext(ctx, builder)
+	return err
+}
+
 func (d *DB) IncrementReportCount(ctx context.Context, telegramID int64) (int, error) {
 	builder := d.Builder.Update("users").
 		Set("report_count", squirrel.Expr("report_count + 1")).
@@ -195,7 +221,7 @@ func (d *DB) GetDepartmentUserCount(ctx context.Context, dept string) (int, erro
 func (d *DB) GetUsersByDepartment(ctx context.Context, dept string, excludeTelegramID int64) ([]int64, error) {
 	var ids []int64
 	builder := d.Builder.Select("telegram_id").From("users").
-		Where(squirrel.Eq{"department": dept, "is_verified": true, "is_banned": false}).
+		Where(squirrel.Eq{"department": dept, "is_verified": true, "is_banned": false, "is_reachable": true}).
 		Where(squirrel.NotEq{"telegram_id": excludeTelegramID})
 
 	err := d.SelectBuilderContext(ctx, &ids, builder)
@@ -213,7 +239,7 @@ func (d *DB) IsUserProfileComplete(ctx context.Context, telegramID int64) (bool,
 func (d *DB) GetAllVerifiedUsers(ctx context.Context) ([]int64, error) {
 	var ids []int64
 	builder := d.Builder.Select("telegram_id").From("users").
-		Where(squirrel.Eq{"is_verified": true, "is_banned": false})
+		Where(squirrel.Eq{"is_verified": true, "is_banned": false, "is_reachable": true})
 
 	err := d.SelectBuilderContext(ctx, &ids, builder)
 	return ids, err
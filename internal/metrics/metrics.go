@@ -86,6 +86,16 @@ var (
 		Help: "Total circle joins.",
 	})
 
+	CircleDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pnj_bot_circle_deliveries_total",
+		Help: "Total circle message deliveries by result.",
+	}, []string{"result"})
+
+	CircleFanoutQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "pnj_bot_circle_fanout_queue_depth",
+		Help: "Circle messages waiting for fan-out.",
+	})
+
 	RateLimitHitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "pnj_bot_rate_limit_hits_total",
 		Help: "Total rate limit hits by action.",
@@ -101,6 +111,11 @@ var (
 		Help: "Total media blocked by content moderation.",
 	})
 
+	UsersBlockedBot = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pnj_bot_users_blocked_bot_total",
+		Help: "Total users marked unreachable after blocking the bot.",
+	})
+
 	TelegramAPIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "pnj_bot_telegram_api_errors_total",
 		Help: "Total Telegram API errors.",
@@ -106,25 +106,27 @@ func AvailableEntryYears() []int {
 }
 
 type User struct {
-	ID           int64      `json:"id" db:"id"`
-	TelegramID   int64      `json:"telegram_id" db:"telegram_id"`
-	Email        string     `json:"email" db:"email"`
-	Gender       Gender     `json:"gender" db:"gender"`
-	Department   Department `json:"department" db:"department"`
-	Year         int        `json:"year" db:"year"`
-	DisplayName  string     `json:"display_name" db:"display_name"`
-	Karma        int        `json:"karma" db:"karma"`
-	IsVerified   bool       `json:"is_verified" db:"is_verified"`
-	IsBanned     bool       `json:"is_banned" db:"is_banned"`
-	ReportCount  int        `json:"report_count" db:"report_count"`
-	TotalChats   int        `json:"total_chats" db:"total_chats"`
-	Points       int        `json:"points" db:"points"`
-	Level        int        `json:"level" db:"level"`
-	Exp          int        `json:"exp" db:"exp"`
-	DailyStreak  int        `json:"daily_streak" db:"daily_streak"`
-	LastActiveAt time.Time  `json:"last_active_at" db:"last_active_at"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	ID                int64      `json:"id" db:"id"`
+	TelegramID        int64      `json:"telegram_id" db:"telegram_id"`
+	Email             string     `json:"email" db:"email"`
+	Gender            Gender     `json:"gender" db:"gender"`
+	Department        Department `json:"department" db:"department"`
+	Year              int        `json:"year" db:"year"`
+	DisplayName       string     `json:"display_name" db:"display_name"`
+	Karma             int        `json:"karma" db:"karma"`
+	IsVerified        bool       `json:"is_verified" db:"is_verified"`
+	IsBanned          bool       `json:"is_banned" db:"is_banned"`
+	IsReachable       bool       `json:"is_reachable" db:"is_reachable"`
+	ReportCount       int        `json:"report_count" db:"report_count"`
+	TotalChats        int        `json:"total_chats" db:"total_chats"`
+	Points            int        `json:"points" db:"points"`
+	Level             int        `json:"level" db:"level"`
+	Exp               int        `json:"exp" db:"exp"`
+	DailyStreak       int        `json:"daily_streak" db:"daily_streak"`
+	AllowChatRequests bool       `json:"allow_chat_requests" db:"allow_chat_requests"`
+	LastActiveAt      time.Time  `json:"last_active_at" db:"last_active_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type UserState string
@@ -139,6 +141,7 @@ const (
 	StateSearching           UserState = "searching"
 	StateInChat              UserState = "in_chat"
 	StateAwaitingConfess     UserState = "awaiting_confession"
+	StateAwaitingConfessEdit UserState = "awaiting_confession_edit"
 	StateAwaitingReport      UserState = "awaiting_report"
 	StateAwaitingWhisper     UserState = "awaiting_whisper"
 	StateAwaitingWhisperDept UserState = "awaiting_whisper_dept"
@@ -149,19 +152,72 @@ const (
 )
 
 type Room struct {
-	ID          int64     `json:"id" db:"id"`
-	Slug        string    `json:"slug" db:"slug"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	MemberCount int       `json:"member_count" db:"member_count"`
-	IsActive    bool      `json:"is_active" db:"is_active"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ID          int64          `json:"id" db:"id"`
+	Slug        string         `json:"slug" db:"slug"`
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	MemberCount int            `json:"member_count" db:"member_count"`
+	IsActive    bool           `json:"is_active" db:"is_active"`
+	Visibility  RoomVisibility `json:"visibility" db:"visibility"`
+	Department  string         `json:"department" db:"department"`
+	InviteNonce string         `json:"-" db:"invite_nonce"`
+	SlowMode    int            `json:"slow_mode_seconds" db:"slow_mode_seconds"`
+	CreatedBy   *int64         `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+
+	// Speaking and Muted describe the caller's membership; they are only
+	// filled in by queries that list a user's circles.
+	Speaking bool `json:"speaking" db:"speaking"`
+	Muted    bool `json:"muted" db:"muted"`
+}
+
+type RoomVisibility string
+
+const (
+	RoomPublic         RoomVisibility = "public"
+	RoomDepartmentOnly RoomVisibility = "department"
+	RoomInviteOnly     RoomVisibility = "invite"
+)
+
+type RoomRole string
+
+const (
+	RoomRoleMember    RoomRole = ""
+	RoomRoleModerator RoomRole = "moderator"
+	RoomRoleOwner     RoomRole = "owner"
+)
+
+// Outranks reports whether r may moderate a member holding target.
+func (r RoomRole) Outranks(target RoomRole) bool {
+	rank := map[RoomRole]int{RoomRoleMember: 0, RoomRoleModerator: 1, RoomRoleOwner: 2}
+	return rank[r] > rank[target]
+}
+
+type RoomPin struct {
+	ID        int64     `json:"id" db:"id"`
+	RoomID    int64     `json:"room_id" db:"room_id"`
+	Content   string    `json:"content" db:"content"`
+	PinnedBy  int64     `json:"pinned_by" db:"pinned_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type RoomMessage struct {
+	ID        int64     `json:"id" db:"id"`
+	RoomID    int64     `json:"room_id" db:"room_id"`
+	SenderID  int64     `json:"sender_id" db:"sender_id"`
+	Alias     string    `json:"alias" db:"alias"`
+	Content   string    `json:"content" db:"content"`
+	MediaType string    `json:"media_type" db:"media_type"`
+	FileID    string    `json:"file_id" db:"file_id"`
+	IsRemoved bool      `json:"is_removed" db:"is_removed"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
 type RoomMember struct {
 	ID         int64     `json:"id" db:"id"`
 	RoomID     int64     `json:"room_id" db:"room_id"`
 	TelegramID int64     `json:"telegram_id" db:"telegram_id"`
+	Alias      *string   `json:"alias" db:"alias"`
 	JoinedAt   time.Time `json:"joined_at" db:"joined_at"`
 }
 
@@ -183,13 +239,46 @@ type ChatSession struct {
 	EndedAt   *time.Time `json:"ended_at" db:"ended_at"`
 }
 
+type ChatRequestStatus string
+
+const (
+	ChatRequestPending  ChatRequestStatus = "pending"
+	ChatRequestAccepted ChatRequestStatus = "accepted"
+	ChatRequestDeclined ChatRequestStatus = "declined"
+	ChatRequestExpired  ChatRequestStatus = "expired"
+)
+
+type ChatRequest struct {
+	ID           int64             `json:"id" db:"id"`
+	ConfessionID int64             `json:"confession_id" db:"confession_id"`
+	RequesterID  int64             `json:"requester_id" db:"requester_id"`
+	AuthorID     int64             `json:"author_id" db:"author_id"`
+	Status       ChatRequestStatus `json:"status" db:"status"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	RespondedAt  *time.Time        `json:"responded_at" db:"responded_at"`
+}
+
+type ConfessionStatus string
+
+const (
+	ConfessionPublished ConfessionStatus = "published"
+	ConfessionPending   ConfessionStatus = "pending"
+	ConfessionRejected  ConfessionStatus = "rejected"
+)
+
 type Confession struct {
-	ID         int64     `json:"id" db:"id"`
-	AuthorID   int64     `json:"author_id" db:"author_id"`
-	Content    string    `json:"content" db:"content"`
-	LikeCount  int       `json:"like_count" db:"like_count"`
-	Department string    `json:"department" db:"department"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ID          int64            `json:"id" db:"id"`
+	AuthorID    int64            `json:"author_id" db:"author_id"`
+	Content     string           `json:"content" db:"content"`
+	LikeCount   int              `json:"like_count" db:"like_count"`
+	Department  string           `json:"department" db:"department"`
+	PhotoFileID string           `json:"photo_file_id" db:"photo_file_id"`
+	Status      ConfessionStatus `json:"status" db:"status"`
+	IsDeleted   bool             `json:"is_deleted" db:"is_deleted"`
+	DeletedAt   *time.Time       `json:"deleted_at" db:"deleted_at"`
+	EditedAt    *time.Time       `json:"edited_at" db:"edited_at"`
+	IsHidden    bool             `json:"is_hidden" db:"is_hidden"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
 }
 
 type ConfessionReaction struct {
@@ -210,6 +299,67 @@ type Report struct {
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 }
 
+type ContentType string
+
+const (
+	ContentConfession ContentType = "confession"
+	ContentReply      ContentType = "reply"
+	ContentWhisper    ContentType = "whisper"
+	ContentCircleMsg  ContentType = "circle_message"
+)
+
+func IsValidContentType(t string) bool {
+	switch ContentType(t) {
+	case ContentConfession, ContentReply, ContentWhisper, ContentCircleMsg:
+		return true
+	}
+	return false
+}
+
+func ContentTypeLabel(t ContentType) string {
+	switch t {
+	case ContentConfession:
+		return "Confession"
+	case ContentReply:
+		return "Balasan"
+	case ContentWhisper:
+		return "Whisper"
+	case ContentCircleMsg:
+		return "Pesan Circle"
+	default:
+		return string(t)
+	}
+}
+
+type FlagStatus string
+
+const (
+	FlagPending  FlagStatus = "pending"
+	FlagRestored FlagStatus = "restored"
+	FlagRemoved  FlagStatus = "removed"
+)
+
+type ContentReport struct {
+	ID          int64       `json:"id" db:"id"`
+	ContentType ContentType `json:"content_type" db:"content_type"`
+	ContentID   int64       `json:"content_id" db:"content_id"`
+	ReporterID  int64       `json:"reporter_id" db:"reporter_id"`
+	Reason      string      `json:"reason" db:"reason"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+}
+
+type ContentFlag struct {
+	ID          int64       `json:"id" db:"id"`
+	ContentType ContentType `json:"content_type" db:"content_type"`
+	ContentID   int64       `json:"content_id" db:"content_id"`
+	AuthorID    int64       `json:"author_id" db:"author_id"`
+	ReportCount int         `json:"report_count" db:"report_count"`
+	Status      FlagStatus  `json:"status" db:"status"`
+	ReviewedBy  *int64      `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt  *time.Time  `json:"reviewed_at" db:"reviewed_at"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+}
+
 type BlockedUser struct {
 	ID        int64     `json:"id" db:"id"`
 	UserID    int64     `json:"user_id" db:"user_id"`
@@ -235,12 +385,261 @@ type ConfessionReply struct {
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
 
+type TagCount struct {
+	Tag   string `json:"tag" db:"tag"`
+	Total int    `json:"total" db:"total"`
+}
+
+type PollScope string
+
+const (
+	PollPersonal   PollScope = "personal"
+	PollDepartment PollScope = "department"
+	PollGlobal     PollScope = "global"
+)
+
+type PollType string
+
+const (
+	PollSingle PollType = "single"
+	PollMulti  PollType = "multi"
+	PollRanked PollType = "ranked"
+)
+
 type Poll struct {
-	ID        int64         `json:"id" db:"id"`
-	AuthorID  int64         `json:"author_id" db:"author_id"`
-	Question  string        `json:"question" db:"question"`
-	Options   []*PollOption `json:"options" db:"-"`
-	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	ID         int64         `json:"id" db:"id"`
+	AuthorID   *int64        `json:"author_id" db:"author_id"`
+	Scope      PollScope     `json:"scope" db:"scope"`
+	Department string        `json:"department" db:"department"`
+	Type       PollType      `json:"poll_type" db:"poll_type"`
+	MaxChoices int           `json:"max_choices" db:"max_choices"`
+	Question   string        `json:"question" db:"question"`
+	IsNative   bool          `json:"is_native" db:"is_native"`
+	Options    []*PollOption `json:"options" db:"-"`
+	ExpiresAt  *time.Time    `json:"expires_at" db:"expires_at"`
+	IsClosed   bool          `json:"is_closed" db:"is_closed"`
+	ClosedAt   *time.Time    `json:"closed_at" db:"closed_at"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+}
+
+func (p *Poll) IsAuthor(telegramID int64) bool {
+	return p.AuthorID != nil && *p.AuthorID == telegramID
+}
+
+func (p *Poll) IsOpen(now time.Time) bool {
+	return !p.IsClosed && (p.ExpiresAt == nil || now.Before(*p.ExpiresAt))
+}
+
+type PollVote struct {
+	PollID     int64 `json:"poll_id" db:"poll_id"`
+	TelegramID int64 `json:"telegram_id" db:"telegram_id"`
+	OptionID   int64 `json:"option_id" db:"option_id"`
+	Rank       int   `json:"rank" db:"rank"`
+}
+
+// TelegramPoll maps a native Telegram poll sent to one chat back to the
+// internal poll it mirrors.
+type TelegramPoll struct {
+	TelegramPollID string    `json:"telegram_poll_id" db:"telegram_poll_id"`
+	PollID         int64     `json:"poll_id" db:"poll_id"`
+	ChatID         int64     `json:"chat_id" db:"chat_id"`
+	MessageID      int       `json:"message_id" db:"message_id"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+type BroadcastKind string
+
+const (
+	BroadcastMessage    BroadcastKind = "message"
+	BroadcastPoll       BroadcastKind = "poll"
+	BroadcastNativePoll BroadcastKind = "native_poll"
+	// BroadcastPollResults announces a closed global poll to its voters and
+	// stops the native polls that were sent for it.
+	BroadcastPollResults BroadcastKind = "poll_results"
+)
+
+type BroadcastStatus string
+
+const (
+	BroadcastDraft     BroadcastStatus = "draft"
+	BroadcastScheduled BroadcastStatus = "scheduled"
+	BroadcastRunning   BroadcastStatus = "running"
+	BroadcastDone      BroadcastStatus = "done"
+	BroadcastCancelled BroadcastStatus = "cancelled"
+)
+
+// Delivery states of a single broadcast recipient.
+const (
+	RecipientPending = "pending"
+	RecipientSent    = "sent"
+	RecipientFailed  = "failed"
+	RecipientBlocked = "blocked"
+)
+
+// BroadcastSegment narrows a broadcast's audience. Zero values match every
+// verified user.
+type BroadcastSegment struct {
+	Department Department `json:"department" db:"department"`
+	Year       int        `json:"year" db:"year"`
+	Gender     Gender     `json:"gender" db:"gender"`
+	MinLevel   int        `json:"min_level" db:"min_level"`
+	ActiveDays int        `json:"active_days" db:"active_days"`
+}
+
+func (s BroadcastSegment) IsEmpty() bool {
+	return s == BroadcastSegment{}
+}
+
+// Broadcast is a persisted admin announcement or global poll delivered to a
+// snapshot of recipients by the broadcast worker.
+type Broadcast struct {
+	ID      int64           `json:"id" db:"id"`
+	Kind    BroadcastKind   `json:"kind" db:"kind"`
+	Content string          `json:"content" db:"content"`
+	PollID  *int64          `json:"poll_id" db:"poll_id"`
+	Status  BroadcastStatus `json:"status" db:"status"`
+	BroadcastSegment
+	ScheduledAt *time.Time `json:"scheduled_at" db:"scheduled_at"`
+	CreatedBy   int64      `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at" db:"finished_at"`
+}
+
+// BroadcastProgress counts a broadcast's recipients by delivery state.
+type BroadcastProgress struct {
+	Pending int
+	Sent    int
+	Failed  int
+	Blocked int
+}
+
+func (p BroadcastProgress) Total() int {
+	return p.Pending + p.Sent + p.Failed + p.Blocked
+}
+
+// StaffRole is a bot-wide role granted to a Telegram account.
+type StaffRole string
+
+const (
+	StaffOwner     StaffRole = "owner"
+	StaffAdmin     StaffRole = "admin"
+	StaffModerator StaffRole = "moderator"
+	StaffCSAgent   StaffRole = "cs_agent"
+)
+
+type Permission string
+
+const (
+	PermManageRoles     Permission = "manage_roles"
+	PermBroadcast       Permission = "broadcast"
+	PermGlobalPoll      Permission = "global_poll"
+	PermManageCircles   Permission = "manage_circles"
+	PermModerateCircles Permission = "moderate_circles"
+	PermReviewContent   Permission = "review_content"
+	PermCustomerService Permission = "customer_service"
+	PermViewAudit       Permission = "view_audit"
+)
+
+var rolePermissions = map[StaffRole][]Permission{
+	StaffOwner: {
+		PermManageRoles, PermBroadcast, PermGlobalPoll, PermManageCircles,
+		PermModerateCircles, PermReviewContent, PermCustomerService, PermViewAudit,
+	},
+	StaffAdmin: {
+		PermBroadcast, PermGlobalPoll, PermManageCircles, PermModerateCircles, PermReviewContent, PermViewAudit,
+	},
+	StaffModerator: {PermModerateCircles, PermReviewContent},
+	StaffCSAgent:   {PermCustomerService},
+}
+
+func AllStaffRoles() []StaffRole {
+	return []StaffRole{StaffOwner, StaffAdmin, StaffModerator, StaffCSAgent}
+}
+
+func IsValidStaffRole(role string) bool {
+	_, ok := rolePermissions[StaffRole(role)]
+	return ok
+}
+
+func (r StaffRole) Can(p Permission) bool {
+	for _, perm := range rolePermissions[r] {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
+
+// RolesWithPermission lists every staff role that grants p.
+func RolesWithPermission(p Permission) []StaffRole {
+	var roles []StaffRole
+	for _, r := range AllStaffRoles() {
+		if r.Can(p) {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+func StaffRoleLabel(r StaffRole) string {
+	switch r {
+	case StaffOwner:
+		return "👑 Owner"
+	case StaffAdmin:
+		return "🛠️ Admin"
+	case StaffModerator:
+		return "🛡️ Moderator"
+	case StaffCSAgent:
+		return "🎧 Agen CS"
+	default:
+		return string(r)
+	}
+}
+
+type StaffMember struct {
+	TelegramID int64     `json:"telegram_id" db:"telegram_id"`
+	Role       StaffRole `json:"role" db:"role"`
+	GrantedBy  int64     `json:"granted_by" db:"granted_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type AuditAction string
+
+const (
+	AuditBroadcastConfirm AuditAction = "broadcast_confirm"
+	AuditBroadcastCancel  AuditAction = "broadcast_cancel"
+	AuditGlobalPoll       AuditAction = "global_poll_create"
+	AuditPollClose        AuditAction = "poll_close"
+	AuditContentReview    AuditAction = "content_review"
+	AuditConfessionReview AuditAction = "confession_review"
+	AuditCircleRestore    AuditAction = "circle_restore"
+	AuditCircleDelete     AuditAction = "circle_delete"
+	AuditCircleKick       AuditAction = "circle_kick"
+	AuditCircleMute       AuditAction = "circle_mute"
+	AuditCircleRemove     AuditAction = "circle_message_remove"
+	AuditCirclePin        AuditAction = "circle_pin"
+	AuditRoleGrant        AuditAction = "role_grant"
+	AuditRoleRevoke       AuditAction = "role_revoke"
+	AuditExport           AuditAction = "audit_export"
+)
+
+// AuditEntry is one row of the append-only admin audit log. TargetID is the
+// affected user, or 0 when the action has no single target.
+type AuditEntry struct {
+	ID        int64       `json:"id" db:"id"`
+	ActorID   int64       `json:"actor_id" db:"actor_id"`
+	Action    AuditAction `json:"action" db:"action"`
+	TargetID  int64       `json:"target_id" db:"target_id"`
+	Details   string      `json:"details" db:"details"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// PollBreakdownCount is the number of first-preference votes an option got
+// from voters in one demographic bucket.
+type PollBreakdownCount struct {
+	Bucket   string `json:"bucket" db:"bucket"`
+	OptionID int64  `json:"option_id" db:"option_id"`
+	Votes    int    `json:"votes" db:"votes"`
 }
 
 type PollOption struct {
@@ -280,7 +679,7 @@ func GetAchievements() map[string]Achievement {
 		"POLL_MAKER": {
 			Key:         "POLL_MAKER",
 			Name:        "Pembuat Aspirasi",
-			Description: "Membuat lebih dari 3 polling.",
+			Description: "Membuat 3 polling yang ramai dipilih.",
 			Icon:        "🗳️",
 		},
 	}
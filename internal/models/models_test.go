@@ -43,3 +43,26 @@ func TestAvailableEntryYearsDescending(t *testing.T) {
 		}
 	}
 }
+
+func TestStaffRolePermissions(t *testing.T) {
+	if !StaffOwner.Can(PermManageRoles) || StaffAdmin.Can(PermManageRoles) {
+		t.Error("only owners should manage roles")
+	}
+	if !StaffModerator.Can(PermReviewContent) || StaffModerator.Can(PermBroadcast) {
+		t.Error("moderators should review content but not broadcast")
+	}
+	if !StaffCSAgent.Can(PermCustomerService) || StaffCSAgent.Can(PermReviewContent) {
+		t.Error("CS agents should only handle customer service")
+	}
+	if !StaffAdmin.Can(PermViewAudit) || StaffModerator.Can(PermViewAudit) {
+		t.Error("only owners and admins should view the audit log")
+	}
+
+	roles := RolesWithPermission(PermCustomerService)
+	if len(roles) != 2 || roles[0] != StaffOwner || roles[1] != StaffCSAgent {
+		t.Errorf("unexpected roles for customer service: %v", roles)
+	}
+	if IsValidStaffRole("superuser") {
+		t.Error("unknown role should be invalid")
+	}
+}
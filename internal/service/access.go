@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pnj-anonymous-bot/internal/config"
+	"github.com/pnj-anonymous-bot/internal/database"
+	"github.com/pnj-anonymous-bot/internal/logger"
+	"github.com/pnj-anonymous-bot/internal/models"
+	"go.uber.org/zap"
+)
+
+// AccessService resolves staff roles and permissions. The MAINTENANCE_ID
+// account is always treated as an owner so access can be bootstrapped.
+type AccessService struct {
+	db      *database.DB
+	ownerID int64
+}
+
+func NewAccessService(db *database.DB, cfg *config.Config) *AccessService {
+	return &AccessService{db: db, ownerID: cfg.MaintenanceAccountID}
+}
+
+// Bootstrap stores the owner role for the MAINTENANCE_ID account.
+func (s *AccessService) Bootstrap(ctx context.Context) error {
+	if s.ownerID == 0 {
+		return nil
+	}
+	_, err := s.db.GrantStaffRole(ctx, s.ownerID, models.StaffOwner, 0)
+	return err
+}
+
+func (s *AccessService) Roles(ctx context.Context, telegramID int64) ([]models.StaffRole, error) {
+	roles, err := s.db.GetStaffRoles(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if telegramID != 0 && telegramID == s.ownerID && !slices.Contains(roles, models.StaffOwner) {
+		roles = append(roles, models.StaffOwner)
+	}
+	return roles, nil
+}
+
+// Can reports whether the user holds a role granting p. Lookup errors deny
+// access.
+func (s *AccessService) Can(ctx context.Context, telegramID int64, p models.Permission) bool {
+	if telegramID != 0 && telegramID == s.ownerID {
+		return true
+	}
+
+	roles, err := s.db.GetStaffRoles(ctx, telegramID)
+	if err != nil {
+		logger.Warn("Failed to check staff permission", zap.Int64("user_id", telegramID), zap.Error(err))
+		return false
+	}
+	for _, r := range roles {
+		if r.Can(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// StaffWith returns every user holding a role that grants p.
+func (s *AccessService) StaffWith(ctx context.Context, p models.Permission) ([]int64, error) {
+	ids, err := s.db.GetStaffWithRoles(ctx, models.RolesWithPermission(p))
+	if err != nil {
+		return nil, err
+	}
+	if s.ownerID != 0 && !slices.Contains(ids, s.ownerID) {
+		ids = append([]int64{s.ownerID}, ids...)
+	}
+	return ids, nil
+}
+
+func (s *AccessService) Staff(ctx context.Context) ([]*models.StaffMember, error) {
+	return s.db.GetStaffMembers(ctx)
+}
+
+func (s *AccessService) Grant(ctx context.Context, actorID, targetID int64, role models.StaffRole) error {
+	if !s.Can(ctx, actorID, models.PermManageRoles) {
+		return fmt.Errorf("hanya owner yang bisa mengatur role")
+	}
+	if !models.IsValidStaffRole(string(role)) {
+		return fmt.Errorf("role tidak dikenal")
+	}
+	if targetID <= 0 {
+		return fmt.Errorf("ID Telegram tidak valid")
+	}
+
+	granted, err := s.db.GrantStaffRole(ctx, targetID, role, actorID)
+	if err != nil {
+		return err
+	}
+	if !granted {
+		return fmt.Errorf("pengguna %d sudah memiliki role %s", targetID, role)
+	}
+	return nil
+}
+
+func (s *AccessService) Revoke(ctx context.Context, actorID, targetID int64, role models.StaffRole) error {
+	if !s.Can(ctx, actorID, models.PermManageRoles) {
+		return fmt.Errorf("hanya owner yang bisa mengatur role")
+	}
+	if !models.IsValidStaffRole(string(role)) {
+		return fmt.Errorf("role tidak dikenal")
+	}
+	if role == models.StaffOwner {
+		if targetID == s.ownerID {
+			return fmt.Errorf("owner utama (MAINTENANCE_ID) tidak bisa dicabut")
+		}
+		owners, err := s.db.CountStaffRole(ctx, models.StaffOwner)
+		if err != nil {
+			return err
+		}
+		if owners <= 1 {
+			return fmt.Errorf("tidak bisa mencabut owner terakhir")
+		}
+	}
+
+	revoked, err := s.db.RevokeStaffRole(ctx, targetID, role)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return fmt.Errorf("pengguna %d tidak memiliki role %s", targetID, role)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnj-anonymous-bot/internal/config"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+func TestAccessService(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewAccessService(db, &config.Config{MaintenanceAccountID: 1000})
+	ctx := context.Background()
+
+	if err := svc.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	if !svc.Can(ctx, 1000, models.PermManageRoles) {
+		t.Error("bootstrap owner should manage roles")
+	}
+	if svc.Can(ctx, 2000, models.PermReviewContent) {
+		t.Error("regular user should have no staff permissions")
+	}
+
+	if err := svc.Grant(ctx, 2000, 3000, models.StaffAdmin); err == nil {
+		t.Error("non-owner should not grant roles")
+	}
+	if err := svc.Grant(ctx, 1000, 2000, models.StaffModerator); err != nil {
+		t.Fatalf("Grant failed: %v", err)
+	}
+	if err := svc.Grant(ctx, 1000, 2000, models.StaffModerator); err == nil {
+		t.Error("granting the same role twice should fail")
+	}
+	if !svc.Can(ctx, 2000, models.PermReviewContent) || svc.Can(ctx, 2000, models.PermBroadcast) {
+		t.Error("moderator should review content but not broadcast")
+	}
+
+	_ = svc.Grant(ctx, 1000, 4000, models.StaffCSAgent)
+	agents, err := svc.StaffWith(ctx, models.PermCustomerService)
+	if err != nil {
+		t.Fatalf("StaffWith failed: %v", err)
+	}
+	if len(agents) != 2 || agents[0] != 1000 || agents[1] != 4000 {
+		t.Errorf("expected owner and CS agent, got %v", agents)
+	}
+
+	if err := svc.Revoke(ctx, 1000, 1000, models.StaffOwner); err == nil {
+		t.Error("bootstrap owner should not be revocable")
+	}
+	_ = svc.Grant(ctx, 1000, 5000, models.StaffOwner)
+	if err := svc.Revoke(ctx, 5000, 5000, models.StaffOwner); err != nil {
+		t.Errorf("second owner should be able to step down: %v", err)
+	}
+	if err := svc.Revoke(ctx, 1000, 2000, models.StaffModerator); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if svc.Can(ctx, 2000, models.PermReviewContent) {
+		t.Error("revoked moderator should lose permissions")
+	}
+	if err := svc.Revoke(ctx, 1000, 2000, models.StaffModerator); err == nil {
+		t.Error("revoking a missing role should fail")
+	}
+}
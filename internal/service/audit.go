@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/database"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+type AuditService struct {
+	db *database.DB
+}
+
+func NewAuditService(db *database.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record appends a privileged action to the audit log. details holds the
+// action's parameters and is stored as JSON.
+func (s *AuditService) Record(ctx context.Context, actorID int64, action models.AuditAction, targetID int64, details map[string]any) error {
+	encoded := []byte("{}")
+	if len(details) > 0 {
+		var err error
+		if encoded, err = json.Marshal(details); err != nil {
+			return fmt.Errorf("failed to encode audit details: %w", err)
+		}
+	}
+
+	return s.db.AddAuditEntry(ctx, &models.AuditEntry{
+		ActorID:  actorID,
+		Action:   action,
+		TargetID: targetID,
+		Details:  string(encoded),
+	})
+}
+
+// Recent returns the newest entries, optionally only those involving userID.
+func (s *AuditService) Recent(ctx context.Context, userID int64, limit int) ([]*models.AuditEntry, error) {
+	return s.db.GetAuditEntries(ctx, userID, limit)
+}
+
+// ExportCSV writes every entry, optionally only those involving userID, as
+// CSV and returns how many rows were written.
+func (s *AuditService) ExportCSV(ctx context.Context, w io.Writer, userID int64) (int, error) {
+	entries, err := s.db.GetAuditEntries(ctx, userID, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "waktu", "aktor_id", "aksi", "target_id", "detail"}); err != nil {
+		return 0, err
+	}
+	for _, e := range entries {
+		record := []string{
+			strconv.FormatInt(e.ID, 10),
+			e.CreatedAt.Format(time.RFC3339),
+			strconv.FormatInt(e.ActorID, 10),
+			string(e.Action),
+			strconv.FormatInt(e.TargetID, 10),
+			e.Details,
+		}
+		if err := cw.Write(record); err != nil {
+			return 0, err
+		}
+	}
+	cw.Flush()
+	return len(entries), cw.Error()
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+func TestAuditService(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewAuditService(db)
+	ctx := context.Background()
+
+	if err := svc.Record(ctx, 1000, models.AuditRoleGrant, 2000, map[string]any{"role": models.StaffModerator}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := svc.Record(ctx, 2000, models.AuditBroadcastConfirm, 0, map[string]any{"content": "=HYPERLINK(\"x\")"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := svc.Record(ctx, 3000, models.AuditCircleRestore, 0, nil); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	entries, err := svc.Recent(ctx, 2000, 10)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 entries involving 2000, got %d (%v)", len(entries), err)
+	}
+	if entries[1].Details != `{"role":"moderator"}` {
+		t.Errorf("unexpected details: %s", entries[1].Details)
+	}
+
+	var buf bytes.Buffer
+	count, err := svc.ExportCSV(ctx, &buf, 0)
+	if err != nil || count != 3 {
+		t.Fatalf("ExportCSV wrote %d rows (%v)", count, err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(rows) != 4 {
+		t.Fatalf("expected header and 3 rows, got %d (%v)", len(rows), err)
+	}
+	if rows[0][0] != "id" || rows[1][3] != string(models.AuditCircleRestore) || rows[1][5] != "{}" {
+		t.Errorf("unexpected CSV rows: %v", rows[:2])
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/logger"
+	"github.com/pnj-anonymous-bot/internal/models"
+	"go.uber.org/zap"
+)
+
+const ChatRequestTTL = 15 * time.Minute
+
+var errAuthorUnavailable = fmt.Errorf("penulis confession ini tidak menerima ajakan chat")
+
+func (s *ChatService) RequestAuthorChat(ctx context.Context, requesterID, confessionID int64) (*models.ChatRequest, error) {
+	requester, err := s.db.GetUser(ctx, requesterID)
+	if err != nil || requester == nil || !requester.IsVerified || requester.IsBanned {
+		return nil, fmt.Errorf("hanya pengguna terverifikasi yang bisa mengajak chat penulis")
+	}
+
+	confession, err := s.db.GetConfession(ctx, confessionID)
+	if err != nil {
+		return nil, err
+	}
+	if confession == nil {
+		return nil, fmt.Errorf("confession tidak ditemukan")
+	}
+	if confession.AuthorID == requesterID {
+		return nil, fmt.Errorf("ini confession milikmu sendiri")
+	}
+
+	author, err := s.db.GetUser(ctx, confession.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	if author == nil || author.IsBanned || !author.AllowChatRequests {
+		return nil, errAuthorUnavailable
+	}
+	blocked, err := s.db.IsBlocked(ctx, requesterID, confession.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errAuthorUnavailable
+	}
+
+	session, err := s.db.GetActiveSession(ctx, requesterID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memeriksa sesi: %w", err)
+	}
+	if session != nil {
+		return nil, fmt.Errorf("kamu masih dalam sesi chat. Gunakan /stop untuk menghentikan chat saat ini")
+	}
+
+	pending, err := s.db.HasPendingChatRequest(ctx, confessionID, requesterID, time.Now().Add(-ChatRequestTTL))
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, fmt.Errorf("kamu sudah mengirim ajakan chat untuk confession ini. Tunggu jawaban penulis")
+	}
+
+	// Shares the /search budget so requests can't be used to bypass the limit.
+	allowed, retryAfter, rateLimitErr := s.redis.AllowPerMinute(ctx, "search", requesterID, s.maxSearchPerMinute)
+	if rateLimitErr != nil {
+		logger.Warn("Search rate limiter unavailable", zap.Int64("user_id", requesterID), zap.Error(rateLimitErr))
+	} else if !allowed {
+		return nil, fmt.Errorf("terlalu sering mengajak chat. Coba lagi dalam %d detik", retryAfter)
+	}
+
+	return s.db.CreateChatRequest(ctx, confessionID, requesterID, confession.AuthorID)
+}
+
+func (s *ChatService) getPendingChatRequest(ctx context.Context, authorID, requestID int64) (*models.ChatRequest, error) {
+	req, err := s.db.GetChatRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil || req.AuthorID != authorID {
+		return nil, fmt.Errorf("ajakan chat tidak ditemukan")
+	}
+	if req.Status != models.ChatRequestPending {
+		return nil, fmt.Errorf("ajakan chat ini sudah tidak berlaku")
+	}
+	if time.Since(req.CreatedAt) > ChatRequestTTL {
+		_ = s.db.UpdateChatRequestStatus(ctx, requestID, models.ChatRequestExpired)
+		return nil, fmt.Errorf("ajakan chat ini sudah kedaluwarsa")
+	}
+	return req, nil
+}
+
+func (s *ChatService) respondChatRequest(ctx context.Context, requestID int64, status models.ChatRequestStatus) error {
+	if err := s.db.UpdateChatRequestStatus(ctx, requestID, status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("ajakan chat ini sudah tidak berlaku")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *ChatService) AcceptChatRequest(ctx context.Context, authorID, requestID int64) (*models.ChatRequest, error) {
+	req, err := s.getPendingChatRequest(ctx, authorID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := s.db.IsBlocked(ctx, req.RequesterID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		_ = s.respondChatRequest(ctx, requestID, models.ChatRequestDeclined)
+		return nil, fmt.Errorf("ajakan chat ini sudah tidak berlaku")
+	}
+
+	for _, id := range []int64{authorID, req.RequesterID} {
+		session, err := s.db.GetActiveSession(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("gagal memeriksa sesi: %w", err)
+		}
+		if session == nil {
+			continue
+		}
+		if id == authorID {
+			return nil, fmt.Errorf("kamu masih dalam sesi chat. Gunakan /stop untuk menghentikan chat saat ini")
+		}
+		return nil, fmt.Errorf("pengirim ajakan sedang dalam sesi chat lain. Coba lagi nanti")
+	}
+
+	if err := s.respondChatRequest(ctx, requestID, models.ChatRequestAccepted); err != nil {
+		return nil, err
+	}
+
+	for _, id := range []int64{authorID, req.RequesterID} {
+		if err := s.redis.RemoveFromQueue(ctx, id); err != nil {
+			logger.Warn("Failed to remove user from queue", zap.Int64("user_id", id), zap.Error(err))
+		}
+	}
+
+	if _, err := s.db.CreateChatSession(ctx, req.RequesterID, authorID); err != nil {
+		return nil, err
+	}
+	for _, id := range []int64{authorID, req.RequesterID} {
+		if err := s.db.SetUserState(ctx, id, models.StateInChat, ""); err != nil {
+			logger.Warn("Failed to set user state to chat", zap.Int64("user_id", id), zap.Error(err))
+		}
+	}
+
+	req.Status = models.ChatRequestAccepted
+	return req, nil
+}
+
+func (s *ChatService) DeclineChatRequest(ctx context.Context, authorID, requestID int64) (*models.ChatRequest, error) {
+	req, err := s.getPendingChatRequest(ctx, authorID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.respondChatRequest(ctx, requestID, models.ChatRequestDeclined); err != nil {
+		return nil, err
+	}
+
+	req.Status = models.ChatRequestDeclined
+	return req, nil
+}
+
+func (s *ChatService) SetAllowChatRequests(ctx context.Context, telegramID int64, allow bool) error {
+	return s.db.UpdateUserAllowChatRequests(ctx, telegramID, allow)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+func TestChatServiceAuthorChatRequest(t *testing.T) {
+	db := setupTestDB(t)
+	setupTestRedis(t)
+	redisSvc := NewRedisService(os.Getenv("REDIS_URL"))
+	chatSvc := NewChatService(db, redisSvc, 5)
+	ctx := context.Background()
+
+	author, reader, blocked := int64(3101), int64(3102), int64(3103)
+	createUserForTest(t, db, author, "", "Akuntansi", 0)
+	createUserForTest(t, db, reader, "", "", 0)
+	createUserForTest(t, db, blocked, "", "", 0)
+
+	confession, err := db.CreateConfession(ctx, author, "butuh teman cerita", "Akuntansi")
+	if err != nil {
+		t.Fatalf("CreateConfession failed: %v", err)
+	}
+
+	if _, err := chatSvc.RequestAuthorChat(ctx, author, confession.ID); err == nil {
+		t.Error("author should not be able to request a chat with themselves")
+	}
+
+	_ = db.BlockUser(ctx, author, blocked)
+	if _, err := chatSvc.RequestAuthorChat(ctx, blocked, confession.ID); err == nil {
+		t.Error("blocked user should not be able to request a chat")
+	}
+
+	room, _ := db.CreateRoom(ctx, "author-room", "Author Room", "Testing author chat", author)
+	_ = db.AddRoomMember(ctx, room.ID, author, "RedFox#1")
+	_ = db.SetUserState(ctx, author, models.StateInCircle, "")
+
+	req, err := chatSvc.RequestAuthorChat(ctx, reader, confession.ID)
+	if err != nil {
+		t.Fatalf("RequestAuthorChat failed: %v", err)
+	}
+	if req.AuthorID != author || req.RequesterID != reader {
+		t.Errorf("unexpected request participants: %+v", req)
+	}
+	if _, err := chatSvc.RequestAuthorChat(ctx, reader, confession.ID); err == nil {
+		t.Error("duplicate pending request should be rejected")
+	}
+
+	if _, err := chatSvc.AcceptChatRequest(ctx, reader, req.ID); err == nil {
+		t.Error("only the author should be able to accept the request")
+	}
+	if _, err := chatSvc.AcceptChatRequest(ctx, author, req.ID); err != nil {
+		t.Fatalf("AcceptChatRequest failed: %v", err)
+	}
+
+	partner, _ := db.GetChatPartner(ctx, reader)
+	if partner != author {
+		t.Errorf("expected active session with author, got partner %d", partner)
+	}
+	state, _, _ := db.GetUserState(ctx, author)
+	if state != models.StateInChat {
+		t.Errorf("expected author state in_chat, got %q", state)
+	}
+	if rooms, _ := db.GetUserRooms(ctx, author); len(rooms) != 1 {
+		t.Errorf("author should keep circle membership, got %d circles", len(rooms))
+	}
+
+	if _, err := chatSvc.DeclineChatRequest(ctx, author, req.ID); err == nil {
+		t.Error("answered request should no longer be pending")
+	}
+}
+
+func TestChatServiceAuthorChatOptOut(t *testing.T) {
+	db := setupTestDB(t)
+	setupTestRedis(t)
+	redisSvc := NewRedisService(os.Getenv("REDIS_URL"))
+	chatSvc := NewChatService(db, redisSvc, 5)
+	ctx := context.Background()
+
+	author, reader := int64(3111), int64(3112)
+	createUserForTest(t, db, author, "", "", 0)
+	createUserForTest(t, db, reader, "", "", 0)
+
+	confession, err := db.CreateConfession(ctx, author, "jangan ajak aku chat", "")
+	if err != nil {
+		t.Fatalf("CreateConfession failed: %v", err)
+	}
+
+	if err := chatSvc.SetAllowChatRequests(ctx, author, false); err != nil {
+		t.Fatalf("SetAllowChatRequests failed: %v", err)
+	}
+	if _, err := chatSvc.RequestAuthorChat(ctx, reader, confession.ID); err == nil {
+		t.Error("request should fail when the author turned requests off")
+	}
+
+	_ = chatSvc.SetAllowChatRequests(ctx, author, true)
+	req, err := chatSvc.RequestAuthorChat(ctx, reader, confession.ID)
+	if err != nil {
+		t.Fatalf("RequestAuthorChat failed after opting in: %v", err)
+	}
+
+	declined, err := chatSvc.DeclineChatRequest(ctx, author, req.ID)
+	if err != nil {
+		t.Fatalf("DeclineChatRequest failed: %v", err)
+	}
+	if declined.Status != models.ChatRequestDeclined {
+		t.Errorf("expected declined status, got %q", declined.Status)
+	}
+	if session, _ := db.GetActiveSession(ctx, reader); session != nil {
+		t.Error("declined request should not open a chat session")
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/database"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+type BroadcastService struct {
+	db *database.DB
+}
+
+func NewBroadcastService(db *database.DB) *BroadcastService {
+	return &BroadcastService{db: db}
+}
+
+func validateBroadcast(bc *models.Broadcast) error {
+	switch bc.Kind {
+	case models.BroadcastMessage:
+		if bc.Content == "" {
+			return fmt.Errorf("pesan broadcast tidak boleh kosong")
+		}
+	case models.BroadcastPoll, models.BroadcastNativePoll, models.BroadcastPollResults:
+		if bc.PollID == nil {
+			return fmt.Errorf("polling broadcast tidak ditemukan")
+		}
+	default:
+		return fmt.Errorf("jenis broadcast tidak dikenal")
+	}
+	return nil
+}
+
+// StartBroadcast persists a broadcast job for the broadcast worker right
+// away and returns its ID and the number of recipients it will be delivered
+// to.
+func (s *BroadcastService) StartBroadcast(ctx context.Context, bc *models.Broadcast) (int64, int, error) {
+	if err := validateBroadcast(bc); err != nil {
+		return 0, 0, err
+	}
+
+	bc.ScheduledAt = nil
+	id, err := s.db.CreateBroadcast(ctx, bc)
+	if err != nil {
+		return 0, 0, err
+	}
+	bc.ID = id
+	count, _, err := s.db.ActivateBroadcast(ctx, bc)
+	if err != nil {
+		_, _ = s.db.FinishBroadcast(ctx, id, models.BroadcastCancelled)
+		return 0, 0, err
+	}
+	return id, count, nil
+}
+
+// AnnouncePollResults queues the results of a closed global poll for its
+// voters and returns the job ID with the number of recipients.
+func (s *BroadcastService) AnnouncePollResults(ctx context.Context, pollID int64, results string, createdBy int64) (int64, int, error) {
+	return s.StartBroadcast(ctx, &models.Broadcast{
+		Kind:      models.BroadcastPollResults,
+		Content:   results,
+		PollID:    &pollID,
+		CreatedBy: createdBy,
+	})
+}
+
+// DraftBroadcast stores a broadcast that waits for the admin's confirmation
+// and returns its ID with the current size of its audience.
+func (s *BroadcastService) DraftBroadcast(ctx context.Context, bc *models.Broadcast) (int64, int, error) {
+	if err := validateBroadcast(bc); err != nil {
+		return 0, 0, err
+	}
+	if bc.ScheduledAt != nil && !bc.ScheduledAt.After(time.Now()) {
+		return 0, 0, fmt.Errorf("waktu jadwal harus di masa depan")
+	}
+
+	count, err := s.db.CountBroadcastAudience(ctx, bc.BroadcastSegment)
+	if err != nil {
+		return 0, 0, err
+	}
+	if count == 0 {
+		return 0, 0, fmt.Errorf("tidak ada pengguna yang cocok dengan filter")
+	}
+
+	bc.Status = models.BroadcastDraft
+	id, err := s.db.CreateBroadcast(ctx, bc)
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, count, nil
+}
+
+// ConfirmBroadcast releases a draft: scheduled drafts wait for their time,
+// the rest start immediately. The returned count is the number of recipients,
+// or the current audience size for scheduled broadcasts.
+func (s *BroadcastService) ConfirmBroadcast(ctx context.Context, id int64) (*models.Broadcast, int, error) {
+	bc, err := s.db.GetBroadcast(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if bc == nil {
+		return nil, 0, fmt.Errorf("broadcast #%d tidak ditemukan", id)
+	}
+	if bc.Status != models.BroadcastDraft {
+		return nil, 0, fmt.Errorf("broadcast #%d sudah diproses", id)
+	}
+
+	if bc.ScheduledAt != nil && bc.ScheduledAt.After(time.Now()) {
+		scheduled, err := s.db.ScheduleBroadcast(ctx, id)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !scheduled {
+			return nil, 0, fmt.Errorf("broadcast #%d sudah diproses", id)
+		}
+		bc.Status = models.BroadcastScheduled
+		count, err := s.db.CountBroadcastAudience(ctx, bc.BroadcastSegment)
+		return bc, count, err
+	}
+
+	count, activated, err := s.db.ActivateBroadcast(ctx, bc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !activated {
+		return nil, 0, fmt.Errorf("broadcast #%d sudah diproses", id)
+	}
+	bc.Status = models.BroadcastRunning
+	return bc, count, nil
+}
+
+// ActivateDueBroadcasts starts every scheduled broadcast whose time has come
+// and returns how many were started.
+func (s *BroadcastService) ActivateDueBroadcasts(ctx context.Context) (int, error) {
+	due, err := s.db.GetDueBroadcasts(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	started := 0
+	for _, bc := range due {
+		_, activated, err := s.db.ActivateBroadcast(ctx, bc)
+		if err != nil {
+			return started, err
+		}
+		if activated {
+			started++
+		}
+	}
+	return started, nil
+}
+
+// Status returns a broadcast with its delivery progress, or nil when it does
+// not exist.
+func (s *BroadcastService) Status(ctx context.Context, id int64) (*models.Broadcast, models.BroadcastProgress, error) {
+	bc, err := s.db.GetBroadcast(ctx, id)
+	if err != nil || bc == nil {
+		return nil, models.BroadcastProgress{}, err
+	}
+	progress, err := s.db.GetBroadcastProgress(ctx, id)
+	return bc, progress, err
+}
+
+func (s *BroadcastService) Recent(ctx context.Context, limit int) ([]*models.Broadcast, error) {
+	return s.db.GetRecentBroadcasts(ctx, limit)
+}
+
+// Cancel stops a draft, scheduled or running broadcast; recipients that were
+// not reached yet stay pending.
+func (s *BroadcastService) Cancel(ctx context.Context, id int64) error {
+	bc, err := s.db.GetBroadcast(ctx, id)
+	if err != nil {
+		return err
+	}
+	if bc == nil {
+		return fmt.Errorf("broadcast #%d tidak ditemukan", id)
+	}
+
+	cancelled, err := s.db.FinishBroadcast(ctx, id, models.BroadcastCancelled)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return fmt.Errorf("broadcast #%d sudah tidak berjalan", id)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+func TestBroadcastService(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewBroadcastService(db)
+	ctx := context.Background()
+
+	createUserForTest(t, db, 9101, "male", "Teknik Informatika & Komputer", 2023)
+	createUserForTest(t, db, 9102, "female", "Akuntansi", 2022)
+
+	if _, _, err := svc.StartBroadcast(ctx, &models.Broadcast{Kind: models.BroadcastMessage, CreatedBy: 1}); err == nil {
+		t.Error("expected empty message broadcast to be rejected")
+	}
+	if _, _, err := svc.StartBroadcast(ctx, &models.Broadcast{Kind: models.BroadcastPoll, CreatedBy: 1}); err == nil {
+		t.Error("expected poll broadcast without poll to be rejected")
+	}
+
+	id, count, err := svc.StartBroadcast(ctx, &models.Broadcast{Kind: models.BroadcastMessage, Content: "Halo semua", CreatedBy: 1})
+	if err != nil {
+		t.Fatalf("StartBroadcast failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 recipients, got %d", count)
+	}
+
+	bc, progress, err := svc.Status(ctx, id)
+	if err != nil || bc == nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if bc.Status != models.BroadcastRunning || progress.Pending != 2 {
+		t.Errorf("expected running broadcast with 2 pending, got %s %+v", bc.Status, progress)
+	}
+
+	if err := svc.Cancel(ctx, id); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if err := svc.Cancel(ctx, id); err == nil {
+		t.Error("expected cancelling twice to fail")
+	}
+	if err := svc.Cancel(ctx, 999); err == nil {
+		t.Error("expected cancelling unknown broadcast to fail")
+	}
+
+	bc, _, _ = svc.Status(ctx, id)
+	if bc.Status != models.BroadcastCancelled || bc.FinishedAt == nil {
+		t.Errorf("expected cancelled broadcast with finish time, got %+v", bc)
+	}
+}
+
+func TestBroadcastServiceDrafts(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewBroadcastService(db)
+	ctx := context.Background()
+
+	createUserForTest(t, db, 9201, "male", "Teknik Informatika & Komputer", 2023)
+	createUserForTest(t, db, 9202, "female", "Akuntansi", 2022)
+
+	segment := models.BroadcastSegment{Department: models.DeptAkuntansi}
+	id, count, err := svc.DraftBroadcast(ctx, &models.Broadcast{Kind: models.BroadcastMessage, Content: "Info jurusan", BroadcastSegment: segment, CreatedBy: 1})
+	if err != nil {
+		t.Fatalf("DraftBroadcast failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected preview of 1 recipient, got %d", count)
+	}
+
+	bc, progress, _ := svc.Status(ctx, id)
+	if bc.Status != models.BroadcastDraft || progress.Total() != 0 {
+		t.Errorf("expected draft without recipients, got %s %+v", bc.Status, progress)
+	}
+
+	bc, count, err = svc.ConfirmBroadcast(ctx, id)
+	if err != nil {
+		t.Fatalf("ConfirmBroadcast failed: %v", err)
+	}
+	if bc.Status != models.BroadcastRunning || count != 1 {
+		t.Errorf("expected running broadcast to 1 recipient, got %s %d", bc.Status, count)
+	}
+	if _, _, err := svc.ConfirmBroadcast(ctx, id); err == nil {
+		t.Error("expected confirming twice to fail")
+	}
+
+	past := time.Now().Add(-time.Minute)
+	if _, _, err := svc.DraftBroadcast(ctx, &models.Broadcast{Kind: models.BroadcastMessage, Content: "Telat", ScheduledAt: &past, CreatedBy: 1}); err == nil {
+		t.Error("expected schedule in the past to be rejected")
+	}
+	if _, _, err := svc.DraftBroadcast(ctx, &models.Broadcast{Kind: models.BroadcastMessage, Content: "Kosong", BroadcastSegment: models.BroadcastSegment{Year: 2019}, CreatedBy: 1}); err == nil {
+		t.Error("expected empty audience to be rejected")
+	}
+
+	at := time.Now().Add(time.Hour)
+	scheduledID, _, err := svc.DraftBroadcast(ctx, &models.Broadcast{Kind: models.BroadcastMessage, Content: "Nanti", ScheduledAt: &at, CreatedBy: 1})
+	if err != nil {
+		t.Fatalf("DraftBroadcast failed: %v", err)
+	}
+	bc, count, err = svc.ConfirmBroadcast(ctx, scheduledID)
+	if err != nil || bc.Status != models.BroadcastScheduled || count != 2 {
+		t.Fatalf("expected scheduled broadcast for 2 users, got %+v %d %v", bc, count, err)
+	}
+
+	if started, _ := svc.ActivateDueBroadcasts(ctx); started != 0 {
+		t.Errorf("expected no broadcast to be due yet, started %d", started)
+	}
+	_, _ = db.Exec("UPDATE broadcasts SET scheduled_at = ? WHERE id = ?", time.Now().Add(-time.Second), scheduledID)
+	if started, _ := svc.ActivateDueBroadcasts(ctx); started != 1 {
+		t.Errorf("expected the scheduled broadcast to start, started %d", started)
+	}
+	bc, progress, _ = svc.Status(ctx, scheduledID)
+	if bc.Status != models.BroadcastRunning || progress.Pending != 2 {
+		t.Errorf("expected running broadcast with 2 pending, got %s %+v", bc.Status, progress)
+	}
+}
+
+func TestBroadcastServicePollResults(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewBroadcastService(db)
+	ctx := context.Background()
+
+	for _, id := range []int64{9301, 9302, 9303, 9304} {
+		createUserForTest(t, db, id, "male", "Akuntansi", 2023)
+	}
+	pollID, _ := db.CreatePoll(ctx, &models.Poll{Scope: models.PollGlobal, Question: "Kantin baru?"}, []string{"Ya", "Tidak"})
+	p, _ := db.GetPoll(ctx, pollID)
+	_ = db.VotePoll(ctx, pollID, 9301, p.Options[0].ID)
+	_ = db.VotePoll(ctx, pollID, 9302, p.Options[1].ID)
+	_ = db.SaveTelegramPoll(ctx, &models.TelegramPoll{TelegramPollID: "tg-9303", PollID: pollID, ChatID: 9303, MessageID: 1})
+	_, _ = db.SetUserReachable(ctx, 9302, false)
+
+	id, count, err := svc.AnnouncePollResults(ctx, pollID, "Hasil", 0)
+	if err != nil {
+		t.Fatalf("AnnouncePollResults failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected reachable voter and native poll chat, got %d recipients", count)
+	}
+
+	recipients, _ := db.GetPendingRecipients(ctx, id, 10)
+	for _, r := range recipients {
+		if r == 9302 || r == 9304 {
+			t.Errorf("unexpected poll results recipient %d", r)
+		}
+	}
+}
@@ -3,13 +3,53 @@ package service
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/pnj-anonymous-bot/internal/config"
 	"github.com/pnj-anonymous-bot/internal/database"
+	"github.com/pnj-anonymous-bot/internal/logger"
 	"github.com/pnj-anonymous-bot/internal/models"
+	"go.uber.org/zap"
 )
 
+const (
+	ConfessionEditWindow = 15 * time.Minute
+	MaxConfessionTags    = 5
+	MaxTagLength         = 32
+)
+
+var (
+	hashtagPattern = regexp.MustCompile(`#([\p{L}\p{N}_]+)`)
+	tagPattern     = regexp.MustCompile(`^[\p{L}\p{N}_]+$`)
+)
+
+func ExtractHashtags(content string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, m := range hashtagPattern.FindAllStringSubmatch(content, -1) {
+		tag := NormalizeTag(m[1])
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == MaxConfessionTags {
+			break
+		}
+	}
+	return tags
+}
+
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if tag == "" || len([]rune(tag)) > MaxTagLength || !tagPattern.MatchString(tag) {
+		return ""
+	}
+	return tag
+}
+
 type ConfessionService struct {
 	db  *database.DB
 	cfg *config.Config
@@ -20,6 +60,22 @@ func NewConfessionService(db *database.DB, cfg *config.Config) *ConfessionServic
 }
 
 func (s *ConfessionService) CreateConfession(ctx context.Context, telegramID int64, content string) (*models.Confession, error) {
+	return s.createConfession(ctx, telegramID, content, "", models.ConfessionPublished)
+}
+
+func (s *ConfessionService) CreatePhotoConfession(ctx context.Context, telegramID int64, caption, photoFileID string, needsApproval bool) (*models.Confession, error) {
+	if photoFileID == "" {
+		return nil, fmt.Errorf("foto tidak valid")
+	}
+
+	status := models.ConfessionPublished
+	if needsApproval {
+		status = models.ConfessionPending
+	}
+	return s.createConfession(ctx, telegramID, caption, photoFileID, status)
+}
+
+func (s *ConfessionService) createConfession(ctx context.Context, telegramID int64, content, photoFileID string, status models.ConfessionStatus) (*models.Confession, error) {
 	count, err := s.db.GetUserConfessionCount(ctx, telegramID, time.Now().Add(-1*time.Hour))
 	if err != nil {
 		return nil, err
@@ -34,19 +90,77 @@ func (s *ConfessionService) CreateConfession(ctx context.Context, telegramID int
 	}
 
 	dept := string(user.Department)
-	confession, err := s.db.CreateConfession(ctx, telegramID, content, dept)
+	confession, err := s.db.CreatePhotoConfession(ctx, telegramID, content, dept, photoFileID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	s.saveTags(ctx, confession.ID, content)
+	return confession, nil
+}
+
+func (s *ConfessionService) GetPendingConfessions(ctx context.Context, limit int) ([]*models.Confession, error) {
+	return s.db.GetPendingConfessions(ctx, limit)
+}
+
+func (s *ConfessionService) ReviewConfession(ctx context.Context, confessionID int64, approve bool) (*models.Confession, error) {
+	confession, err := s.db.GetPendingConfession(ctx, confessionID)
 	if err != nil {
 		return nil, err
 	}
+	if confession == nil {
+		return nil, fmt.Errorf("confession tidak ditemukan atau sudah ditinjau")
+	}
 
+	status := models.ConfessionRejected
+	if approve {
+		status = models.ConfessionPublished
+	}
+	if err := s.db.ReviewConfession(ctx, confessionID, status); err != nil {
+		return nil, fmt.Errorf("confession tidak ditemukan atau sudah ditinjau")
+	}
+
+	confession.Status = status
 	return confession, nil
 }
 
+func (s *ConfessionService) saveTags(ctx context.Context, confessionID int64, content string) {
+	if err := s.db.SetConfessionTags(ctx, confessionID, ExtractHashtags(content)); err != nil {
+		logger.Warn("Failed to save confession tags",
+			zap.Int64("confession_id", confessionID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *ConfessionService) SearchConfessions(ctx context.Context, query string, limit int) ([]*models.Confession, error) {
+	return s.db.SearchConfessions(ctx, query, limit)
+}
+
+func (s *ConfessionService) GetConfessionsByTag(ctx context.Context, tag string, limit int) ([]*models.Confession, error) {
+	normalized := NormalizeTag(tag)
+	if normalized == "" {
+		return nil, fmt.Errorf("tag tidak valid")
+	}
+	return s.db.GetConfessionsByTag(ctx, normalized, limit)
+}
+
+func (s *ConfessionService) GetPopularTags(ctx context.Context, limit int) ([]models.TagCount, error) {
+	return s.db.GetPopularTags(ctx, time.Now().AddDate(0, 0, -30), limit)
+}
+
 func (s *ConfessionService) GetLatestConfessions(ctx context.Context, limit int) ([]*models.Confession, error) {
 	return s.db.GetLatestConfessions(ctx, limit)
 }
 
 func (s *ConfessionService) ReactToConfession(ctx context.Context, confessionID, telegramID int64, reaction string) error {
+	confession, err := s.db.GetConfession(ctx, confessionID)
+	if err != nil {
+		return err
+	}
+	if confession == nil {
+		return fmt.Errorf("confession tidak ditemukan")
+	}
 	return s.db.AddConfessionReaction(ctx, confessionID, telegramID, reaction)
 }
 
@@ -57,3 +171,59 @@ func (s *ConfessionService) GetReactionCounts(ctx context.Context, confessionID
 func (s *ConfessionService) GetConfession(ctx context.Context, id int64) (*models.Confession, error) {
 	return s.db.GetConfession(ctx, id)
 }
+
+func (s *ConfessionService) GetAdjacentPhotoConfession(ctx context.Context, id int64, older bool) (*models.Confession, error) {
+	return s.db.GetAdjacentPhotoConfession(ctx, id, older)
+}
+
+func (s *ConfessionService) GetUserConfessions(ctx context.Context, telegramID int64, limit int) ([]*models.Confession, error) {
+	return s.db.GetConfessionsByAuthor(ctx, telegramID, limit)
+}
+
+func (s *ConfessionService) CanEdit(confession *models.Confession) bool {
+	return confession != nil && !confession.IsDeleted && !confession.IsHidden &&
+		confession.Status == models.ConfessionPublished && time.Since(confession.CreatedAt) <= ConfessionEditWindow
+}
+
+func (s *ConfessionService) getOwnConfession(ctx context.Context, confessionID, telegramID int64) (*models.Confession, error) {
+	confession, err := s.db.GetAuthorConfession(ctx, confessionID, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if confession == nil {
+		return nil, fmt.Errorf("confession tidak ditemukan atau bukan milik kamu")
+	}
+	return confession, nil
+}
+
+func (s *ConfessionService) EditConfession(ctx context.Context, confessionID, telegramID int64, content string) (*models.Confession, error) {
+	confession, err := s.getOwnConfession(ctx, confessionID, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.CanEdit(confession) {
+		return nil, fmt.Errorf("confession hanya bisa diedit dalam %d menit setelah dikirim", int(ConfessionEditWindow.Minutes()))
+	}
+	if reports, err := s.db.CountContentReports(ctx, models.ContentConfession, confessionID); err != nil {
+		return nil, err
+	} else if reports > 0 {
+		return nil, fmt.Errorf("confession yang sudah dilaporkan tidak bisa diedit")
+	}
+
+	if err := s.db.UpdateConfessionContent(ctx, confessionID, telegramID, content); err != nil {
+		return nil, err
+	}
+	s.saveTags(ctx, confessionID, content)
+
+	now := time.Now()
+	confession.Content = content
+	confession.EditedAt = &now
+	return confession, nil
+}
+
+func (s *ConfessionService) DeleteConfession(ctx context.Context, confessionID, telegramID int64) error {
+	if _, err := s.getOwnConfession(ctx, confessionID, telegramID); err != nil {
+		return err
+	}
+	return s.db.SoftDeleteConfession(ctx, confessionID, telegramID)
+}
@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pnj-anonymous-bot/internal/config"
+	"github.com/pnj-anonymous-bot/internal/models"
 )
 
 func TestConfessionServiceCreateAndGet(t *testing.T) {
@@ -156,3 +160,202 @@ func TestConfessionServiceUserNotFound(t *testing.T) {
 		t.Error("Expected error for non-existent user")
 	}
 }
+
+func TestConfessionServiceEditAndDelete(t *testing.T) {
+	db := setupTestDB(t)
+	cfg := &config.Config{MaxConfessionsPerHour: 10}
+	confessionSvc := NewConfessionService(db, cfg)
+	ctx := context.Background()
+
+	authorID := int64(10008)
+	otherID := int64(10009)
+	createUserForTest(t, db, authorID, "Laki-laki", "Akuntansi", 2022)
+	createUserForTest(t, db, otherID, "Perempuan", "Akuntansi", 2022)
+
+	confession, _ := confessionSvc.CreateConfession(ctx, authorID, "Confession awal sebelum diedit.")
+	if confession == nil {
+		t.Fatal("Expected non-nil confession")
+	}
+
+	if _, err := confessionSvc.EditConfession(ctx, confession.ID, otherID, "Bukan milik saya tapi coba edit."); err == nil {
+		t.Error("Expected error when editing someone else's confession")
+	}
+
+	edited, err := confessionSvc.EditConfession(ctx, confession.ID, authorID, "Confession yang sudah diedit.")
+	if err != nil {
+		t.Fatalf("EditConfession failed: %v", err)
+	}
+	if edited.EditedAt == nil {
+		t.Error("Expected EditedAt to be set")
+	}
+
+	fetched, _ := confessionSvc.GetConfession(ctx, confession.ID)
+	if fetched == nil || fetched.Content != "Confession yang sudah diedit." || fetched.EditedAt == nil {
+		t.Error("Edited content was not persisted")
+	}
+
+	if _, err := db.CreateContentReport(ctx, models.ContentConfession, confession.ID, otherID, "spam"); err != nil {
+		t.Fatalf("CreateContentReport failed: %v", err)
+	}
+	if _, err := confessionSvc.EditConfession(ctx, confession.ID, authorID, "Menghapus bukti laporan."); err == nil {
+		t.Error("Expected error when editing a reported confession")
+	}
+	if err := db.UpdateConfessionContent(ctx, confession.ID, authorID, "Menghapus bukti laporan."); err == nil {
+		t.Error("Expected reported confession content to stay unchanged")
+	}
+
+	if err := confessionSvc.DeleteConfession(ctx, confession.ID, otherID); err == nil {
+		t.Error("Expected error when deleting someone else's confession")
+	}
+	if err := confessionSvc.DeleteConfession(ctx, confession.ID, authorID); err != nil {
+		t.Fatalf("DeleteConfession failed: %v", err)
+	}
+
+	fetched, _ = confessionSvc.GetConfession(ctx, confession.ID)
+	if fetched != nil {
+		t.Error("Deleted confession should not be returned")
+	}
+
+	latest, _ := confessionSvc.GetLatestConfessions(ctx, 10)
+	if len(latest) != 0 {
+		t.Errorf("Expected deleted confession to be hidden from feed, got %d", len(latest))
+	}
+
+	if err := confessionSvc.ReactToConfession(ctx, confession.ID, otherID, "❤️"); err == nil {
+		t.Error("Expected error when reacting to a deleted confession")
+	}
+}
+
+func TestConfessionServiceEditWindow(t *testing.T) {
+	db := setupTestDB(t)
+	cfg := &config.Config{MaxConfessionsPerHour: 10}
+	confessionSvc := NewConfessionService(db, cfg)
+	ctx := context.Background()
+
+	authorID := int64(10010)
+	createUserForTest(t, db, authorID, "Perempuan", "Teknik Grafika & Penerbitan", 2023)
+
+	confession, _ := confessionSvc.CreateConfession(ctx, authorID, "Confession lama yang tidak bisa diedit.")
+	if confession == nil {
+		t.Fatal("Expected non-nil confession")
+	}
+
+	old := time.Now().Add(-ConfessionEditWindow - time.Minute)
+	if _, err := db.ExecContext(ctx, "UPDATE confessions SET created_at = ? WHERE id = ?", old, confession.ID); err != nil {
+		t.Fatalf("failed to backdate confession: %v", err)
+	}
+
+	if _, err := confessionSvc.EditConfession(ctx, confession.ID, authorID, "Coba edit setelah lewat batas waktu."); err == nil {
+		t.Error("Expected error when editing outside the edit window")
+	}
+
+	if err := confessionSvc.DeleteConfession(ctx, confession.ID, authorID); err != nil {
+		t.Errorf("Delete should still be allowed outside the edit window: %v", err)
+	}
+}
+
+func TestExtractHashtags(t *testing.T) {
+	tags := ExtractHashtags("Capek #Ujian #ujian #kampus_pnj #a#b #123 #" + strings.Repeat("x", 40))
+	want := []string{"ujian", "kampus_pnj", "a", "b", "123"}
+	if !slices.Equal(tags, want) {
+		t.Errorf("ExtractHashtags = %v, want %v", tags, want)
+	}
+
+	if tags := ExtractHashtags("#1 #2 #3 #4 #5 #6"); len(tags) != MaxConfessionTags {
+		t.Errorf("expected at most %d tags, got %d", MaxConfessionTags, len(tags))
+	}
+}
+
+func TestConfessionServiceTagsAndSearch(t *testing.T) {
+	db := setupTestDB(t)
+	confessionSvc := NewConfessionService(db, &config.Config{MaxConfessionsPerHour: 5})
+	ctx := context.Background()
+	userID := int64(10020)
+
+	createUserForTest(t, db, userID, "Perempuan", "Akuntansi", 2023)
+
+	confession, err := confessionSvc.CreateConfession(ctx, userID, "Besok presentasi tugas akhir #skripsi #deg2an")
+	if err != nil {
+		t.Fatalf("CreateConfession failed: %v", err)
+	}
+
+	byTag, err := confessionSvc.GetConfessionsByTag(ctx, "#Skripsi", 10)
+	if err != nil || len(byTag) != 1 || byTag[0].ID != confession.ID {
+		t.Fatalf("expected confession under #skripsi, got %d (err=%v)", len(byTag), err)
+	}
+
+	if _, err := confessionSvc.GetConfessionsByTag(ctx, "bukan tag!", 10); err == nil {
+		t.Error("Expected error for invalid tag")
+	}
+
+	if _, err := confessionSvc.EditConfession(ctx, confession.ID, userID, "Besok presentasi tugas akhir #sidang"); err != nil {
+		t.Fatalf("EditConfession failed: %v", err)
+	}
+	if byTag, _ = confessionSvc.GetConfessionsByTag(ctx, "skripsi", 10); len(byTag) != 0 {
+		t.Error("Old tag should be removed after edit")
+	}
+
+	results, err := confessionSvc.SearchConfessions(ctx, "presentasi", 10)
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected 1 search result, got %d (err=%v)", len(results), err)
+	}
+}
+
+func TestConfessionServicePhotoApproval(t *testing.T) {
+	db := setupTestDB(t)
+	confessionSvc := NewConfessionService(db, &config.Config{MaxConfessionsPerHour: 5})
+	ctx := context.Background()
+	userID := int64(10030)
+
+	createUserForTest(t, db, userID, "Laki-laki", "Teknik Grafika & Penerbitan", 2021)
+
+	if _, err := confessionSvc.CreatePhotoConfession(ctx, userID, "Caption tanpa foto", "", false); err == nil {
+		t.Error("Expected error for missing photo")
+	}
+
+	published, err := confessionSvc.CreatePhotoConfession(ctx, userID, "Foto senja di kampus #senja", "file-1", false)
+	if err != nil {
+		t.Fatalf("CreatePhotoConfession failed: %v", err)
+	}
+	if published.Status != models.ConfessionPublished || published.PhotoFileID != "file-1" {
+		t.Errorf("Unexpected published confession: %+v", published)
+	}
+
+	pending, err := confessionSvc.CreatePhotoConfession(ctx, userID, "Foto yang perlu ditinjau", "file-2", true)
+	if err != nil {
+		t.Fatalf("CreatePhotoConfession failed: %v", err)
+	}
+
+	latest, _ := confessionSvc.GetLatestConfessions(ctx, 10)
+	if len(latest) != 1 || latest[0].ID != published.ID {
+		t.Fatalf("Only the published photo should be in the feed, got %d", len(latest))
+	}
+	if fetched, _ := confessionSvc.GetConfession(ctx, pending.ID); fetched != nil {
+		t.Error("Pending confession should not be visible")
+	}
+
+	queue, _ := confessionSvc.GetPendingConfessions(ctx, 10)
+	if len(queue) != 1 || queue[0].ID != pending.ID {
+		t.Fatalf("Expected pending confession in queue, got %d", len(queue))
+	}
+
+	draft, _ := confessionSvc.CreatePhotoConfession(ctx, userID, "Foto yang ditarik penulis", "file-3", true)
+	if err := confessionSvc.DeleteConfession(ctx, draft.ID, userID); err != nil {
+		t.Errorf("Author should be able to delete a pending confession: %v", err)
+	}
+
+	approved, err := confessionSvc.ReviewConfession(ctx, pending.ID, true)
+	if err != nil {
+		t.Fatalf("ReviewConfession failed: %v", err)
+	}
+	if approved.Status != models.ConfessionPublished || approved.AuthorID != userID {
+		t.Errorf("Unexpected reviewed confession: %+v", approved)
+	}
+	if _, err := confessionSvc.ReviewConfession(ctx, pending.ID, false); err == nil {
+		t.Error("Expected error when reviewing twice")
+	}
+
+	if latest, _ = confessionSvc.GetLatestConfessions(ctx, 10); len(latest) != 2 {
+		t.Errorf("Approved photo should appear in feed, got %d", len(latest))
+	}
+}
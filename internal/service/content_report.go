@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/config"
+	"github.com/pnj-anonymous-bot/internal/database"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+type ContentReportService struct {
+	db  *database.DB
+	cfg *config.Config
+}
+
+func NewContentReportService(db *database.DB, cfg *config.Config) *ContentReportService {
+	return &ContentReportService{db: db, cfg: cfg}
+}
+
+func (s *ContentReportService) ReportContent(ctx context.Context, reporterID int64, contentType models.ContentType, contentID int64, reason string) (bool, error) {
+	reporter, err := s.db.GetUser(ctx, reporterID)
+	if err != nil || reporter == nil || !reporter.IsVerified || reporter.IsBanned {
+		return false, fmt.Errorf("hanya pengguna terverifikasi yang bisa melaporkan konten")
+	}
+
+	authorID, err := s.db.GetContentAuthor(ctx, contentType, contentID)
+	if err != nil {
+		return false, err
+	}
+	if authorID == 0 {
+		return false, fmt.Errorf("konten tidak ditemukan")
+	}
+	if authorID == reporterID {
+		return false, fmt.Errorf("kamu tidak bisa melaporkan konten milikmu sendiri")
+	}
+
+	count, err := s.db.GetUserContentReportCount(ctx, reporterID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return false, err
+	}
+	if count >= s.cfg.MaxReportsPerDay {
+		return false, fmt.Errorf("kamu sudah mencapai batas laporan per hari")
+	}
+
+	created, err := s.db.CreateContentReport(ctx, contentType, contentID, reporterID, reason)
+	if err != nil {
+		return false, err
+	}
+	if !created {
+		return false, fmt.Errorf("kamu sudah melaporkan konten ini")
+	}
+
+	reports, err := s.db.CountContentReports(ctx, contentType, contentID)
+	if err != nil {
+		return false, err
+	}
+	if reports < s.cfg.ContentReportHideThreshold {
+		return false, nil
+	}
+
+	flagged, err := s.db.CreateContentFlag(ctx, contentType, contentID, authorID, reports)
+	if err != nil {
+		return false, err
+	}
+	if !flagged {
+		// Already reviewed once; keep the admin decision and only track the new count.
+		return false, s.db.UpdateContentFlagCount(ctx, contentType, contentID, reports)
+	}
+
+	if err := s.db.SetContentHidden(ctx, contentType, contentID, true); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *ContentReportService) GetReviewQueue(ctx context.Context, limit int) ([]*models.ContentFlag, error) {
+	return s.db.GetPendingContentFlags(ctx, limit)
+}
+
+func (s *ContentReportService) GetContentText(ctx context.Context, contentType models.ContentType, contentID int64) (string, error) {
+	return s.db.GetContentText(ctx, contentType, contentID)
+}
+
+func (s *ContentReportService) ResolveFlag(ctx context.Context, flagID, reviewerID int64, restore bool) (*models.ContentFlag, error) {
+	flag, err := s.db.GetContentFlag(ctx, flagID)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil {
+		return nil, fmt.Errorf("laporan tidak ditemukan")
+	}
+
+	status := models.FlagRemoved
+	if restore {
+		status = models.FlagRestored
+	}
+
+	if err := s.db.ResolveContentFlag(ctx, flagID, status, reviewerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("laporan ini sudah ditinjau")
+		}
+		return nil, err
+	}
+
+	if restore {
+		if err := s.db.SetContentHidden(ctx, flag.ContentType, flag.ContentID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	flag.Status = status
+	return flag, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/config"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+func TestContentReportServiceAutoHide(t *testing.T) {
+	db := setupTestDB(t)
+	cfg := &config.Config{MaxReportsPerDay: 5, ContentReportHideThreshold: 2}
+	reportSvc := NewContentReportService(db, cfg)
+	ctx := context.Background()
+
+	authorID := int64(13001)
+	reporter1 := int64(13002)
+	reporter2 := int64(13003)
+	createUserForTest(t, db, authorID, "Laki-laki", "Teknik Mesin", 2022)
+	createUserForTest(t, db, reporter1, "Perempuan", "Teknik Mesin", 2022)
+	createUserForTest(t, db, reporter2, "Perempuan", "Akuntansi", 2022)
+
+	confession, _ := db.CreateConfession(ctx, authorID, "Confession yang akan dilaporkan.", "Teknik Mesin")
+
+	if _, err := reportSvc.ReportContent(ctx, authorID, models.ContentConfession, confession.ID, "Spam"); err == nil {
+		t.Error("Expected error when reporting own content")
+	}
+
+	hidden, err := reportSvc.ReportContent(ctx, reporter1, models.ContentConfession, confession.ID, "Spam")
+	if err != nil {
+		t.Fatalf("ReportContent failed: %v", err)
+	}
+	if hidden {
+		t.Error("Content should not be hidden before threshold")
+	}
+
+	if _, err := reportSvc.ReportContent(ctx, reporter1, models.ContentConfession, confession.ID, "Spam"); err == nil {
+		t.Error("Expected error for duplicate report")
+	}
+
+	hidden, err = reportSvc.ReportContent(ctx, reporter2, models.ContentConfession, confession.ID, "Spam")
+	if err != nil {
+		t.Fatalf("ReportContent failed: %v", err)
+	}
+	if !hidden {
+		t.Fatal("Content should be hidden once threshold is reached")
+	}
+
+	if c, _ := db.GetConfession(ctx, confession.ID); c != nil {
+		t.Error("Hidden confession should not be visible")
+	}
+
+	queue, err := reportSvc.GetReviewQueue(ctx, 10)
+	if err != nil || len(queue) != 1 {
+		t.Fatalf("Expected 1 flag in review queue, got %d (err=%v)", len(queue), err)
+	}
+	if queue[0].AuthorID != authorID || queue[0].ReportCount != 2 {
+		t.Errorf("Unexpected flag: %+v", queue[0])
+	}
+
+	flag, err := reportSvc.ResolveFlag(ctx, queue[0].ID, 1, true)
+	if err != nil {
+		t.Fatalf("ResolveFlag failed: %v", err)
+	}
+	if flag.Status != models.FlagRestored {
+		t.Errorf("Expected restored status, got %s", flag.Status)
+	}
+	if c, _ := db.GetConfession(ctx, confession.ID); c == nil {
+		t.Error("Restored confession should be visible again")
+	}
+
+	if _, err := reportSvc.ResolveFlag(ctx, queue[0].ID, 1, false); err == nil {
+		t.Error("Expected error when resolving an already reviewed flag")
+	}
+}
+
+func TestContentReportServiceRequiresVerifiedReporter(t *testing.T) {
+	db := setupTestDB(t)
+	cfg := &config.Config{MaxReportsPerDay: 5, ContentReportHideThreshold: 1}
+	reportSvc := NewContentReportService(db, cfg)
+	ctx := context.Background()
+
+	authorID := int64(13010)
+	reporterID := int64(13011)
+	createUserForTest(t, db, authorID, "Laki-laki", "Teknik Sipil", 2022)
+	createUserForTest(t, db, reporterID, "Perempuan", "Teknik Sipil", 2022)
+	_ = db.UpdateUserVerified(ctx, reporterID, false)
+
+	whisperID, _ := db.CreateWhisper(ctx, authorID, "Teknik Sipil", "Whisper test", "Teknik Sipil", "Laki-laki")
+
+	if _, err := reportSvc.ReportContent(ctx, reporterID, models.ContentWhisper, whisperID, "Spam"); err == nil {
+		t.Error("Expected error for unverified reporter")
+	}
+
+	_ = db.UpdateUserVerified(ctx, reporterID, true)
+	hidden, err := reportSvc.ReportContent(ctx, reporterID, models.ContentWhisper, whisperID, "Spam")
+	if err != nil {
+		t.Fatalf("ReportContent failed: %v", err)
+	}
+	if !hidden {
+		t.Error("Whisper should be hidden with threshold 1")
+	}
+
+	if _, err := reportSvc.ReportContent(ctx, reporterID, models.ContentReply, 9999, "Spam"); err == nil {
+		t.Error("Expected error for missing content")
+	}
+}
+
+func TestContentReportServiceCircleMessage(t *testing.T) {
+	db := setupTestDB(t)
+	cfg := &config.Config{MaxReportsPerDay: 5, ContentReportHideThreshold: 1}
+	reportSvc := NewContentReportService(db, cfg)
+	ctx := context.Background()
+
+	authorID := int64(13020)
+	reporterID := int64(13021)
+	createUserForTest(t, db, authorID, "Laki-laki", "Teknik Elektro", 2022)
+	createUserForTest(t, db, reporterID, "Perempuan", "Teknik Elektro", 2022)
+
+	room, _ := db.CreateRoom(ctx, "report-room", "Report Room", "Testing circle reports", authorID)
+	msgID, _ := db.AddRoomMessage(ctx, &models.RoomMessage{RoomID: room.ID, SenderID: authorID, Alias: "RedFox#1", Content: "pesan kasar"})
+
+	hidden, err := reportSvc.ReportContent(ctx, reporterID, models.ContentCircleMsg, msgID, "Spam")
+	if err != nil {
+		t.Fatalf("ReportContent failed: %v", err)
+	}
+	if !hidden {
+		t.Fatal("Circle message should be removed once threshold is reached")
+	}
+	if history, _ := db.GetRoomHistory(ctx, room.ID, 0, 10, time.Time{}); len(history) != 0 {
+		t.Errorf("Removed circle message should not be in history, got %d", len(history))
+	}
+	if text, _ := reportSvc.GetContentText(ctx, models.ContentCircleMsg, msgID); text != "pesan kasar" {
+		t.Errorf("Unexpected content text: %q", text)
+	}
+
+	queue, _ := reportSvc.GetReviewQueue(ctx, 10)
+	if len(queue) != 1 {
+		t.Fatalf("Expected 1 flag in review queue, got %d", len(queue))
+	}
+	if _, err := reportSvc.ResolveFlag(ctx, queue[0].ID, 1, true); err != nil {
+		t.Fatalf("ResolveFlag failed: %v", err)
+	}
+	if history, _ := db.GetRoomHistory(ctx, room.ID, 0, 10, time.Time{}); len(history) != 1 {
+		t.Error("Restored circle message should be back in history")
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/pnj-anonymous-bot/internal/models"
 )
@@ -24,14 +25,28 @@ type ChatManager interface {
 	GetQueueCount(ctx context.Context) (int, error)
 	CancelSearch(ctx context.Context, telegramID int64) error
 	ProcessQueueTimeout(ctx context.Context, timeoutSeconds int) ([]int64, error)
+	RequestAuthorChat(ctx context.Context, requesterID, confessionID int64) (*models.ChatRequest, error)
+	AcceptChatRequest(ctx context.Context, authorID, requestID int64) (*models.ChatRequest, error)
+	DeclineChatRequest(ctx context.Context, authorID, requestID int64) (*models.ChatRequest, error)
+	SetAllowChatRequests(ctx context.Context, telegramID int64, allow bool) error
 }
 
 type ConfessionManager interface {
 	CreateConfession(ctx context.Context, telegramID int64, content string) (*models.Confession, error)
+	CreatePhotoConfession(ctx context.Context, telegramID int64, caption, photoFileID string, needsApproval bool) (*models.Confession, error)
+	GetPendingConfessions(ctx context.Context, limit int) ([]*models.Confession, error)
+	ReviewConfession(ctx context.Context, confessionID int64, approve bool) (*models.Confession, error)
 	GetLatestConfessions(ctx context.Context, limit int) ([]*models.Confession, error)
 	ReactToConfession(ctx context.Context, confessionID, telegramID int64, reaction string) error
 	GetReactionCounts(ctx context.Context, confessionID int64) (map[string]int, error)
 	GetConfession(ctx context.Context, id int64) (*models.Confession, error)
+	GetUserConfessions(ctx context.Context, telegramID int64, limit int) ([]*models.Confession, error)
+	CanEdit(confession *models.Confession) bool
+	EditConfession(ctx context.Context, confessionID, telegramID int64, content string) (*models.Confession, error)
+	DeleteConfession(ctx context.Context, confessionID, telegramID int64) error
+	SearchConfessions(ctx context.Context, query string, limit int) ([]*models.Confession, error)
+	GetConfessionsByTag(ctx context.Context, tag string, limit int) ([]*models.Confession, error)
+	GetPopularTags(ctx context.Context, limit int) ([]models.TagCount, error)
 }
 
 type ProfileManager interface {
@@ -45,16 +60,50 @@ type ProfileManager interface {
 	UpdateDepartment(ctx context.Context, telegramID int64, dept string) error
 	ReportUser(ctx context.Context, reporterID, reportedID int64, reason, evidence string, chatSessionID int64) (int, error)
 	BlockUser(ctx context.Context, userID, blockedID int64) error
-	SendWhisper(ctx context.Context, senderID int64, targetDept, content string) ([]int64, error)
+	SendWhisper(ctx context.Context, senderID int64, targetDept, content string) (int64, []int64, error)
+}
+
+type ContentReporter interface {
+	ReportContent(ctx context.Context, reporterID int64, contentType models.ContentType, contentID int64, reason string) (bool, error)
+	GetReviewQueue(ctx context.Context, limit int) ([]*models.ContentFlag, error)
+	GetContentText(ctx context.Context, contentType models.ContentType, contentID int64) (string, error)
+	ResolveFlag(ctx context.Context, flagID, reviewerID int64, restore bool) (*models.ContentFlag, error)
 }
 
 type RoomManager interface {
-	GetActiveRooms(ctx context.Context) ([]*models.Room, error)
-	CreateRoom(ctx context.Context, name, description string) (*models.Room, error)
+	GetVisibleRooms(ctx context.Context, telegramID int64) ([]*models.Room, error)
+	CanCreateRoom(ctx context.Context, telegramID int64) error
+	CreateRoom(ctx context.Context, ownerID int64, name, description string) (*models.Room, error)
 	JoinRoom(ctx context.Context, telegramID int64, slug string) (*models.Room, error)
-	LeaveRoom(ctx context.Context, telegramID int64) error
+	JoinRoomByInvite(ctx context.Context, telegramID int64, code string) (*models.Room, error)
+	LeaveRoom(ctx context.Context, telegramID, roomID int64) error
+	LeaveAllRooms(ctx context.Context, telegramID int64) error
+	SwitchRoom(ctx context.Context, telegramID, roomID int64) (*models.Room, error)
+	GetUserRooms(ctx context.Context, telegramID int64) ([]*models.Room, error)
+	SetRoomMuted(ctx context.Context, telegramID, roomID int64, muted bool) error
 	GetRoomMembers(ctx context.Context, telegramID int64) ([]int64, string, error)
 	GetUserRoom(ctx context.Context, telegramID int64) (*models.Room, error)
+	MemberAlias(ctx context.Context, roomID, telegramID int64) (string, error)
+	GetRole(ctx context.Context, roomID, telegramID int64) (models.RoomRole, error)
+	KickMember(ctx context.Context, roomID, telegramID, kickedBy int64) error
+	MuteMember(ctx context.Context, roomID, telegramID int64, duration time.Duration, mutedBy int64) (time.Time, error)
+	MutedUntil(ctx context.Context, roomID, telegramID int64) (*time.Time, error)
+	PinMessage(ctx context.Context, roomID int64, content string, pinnedBy int64) error
+	GetPin(ctx context.Context, roomID int64) (*models.RoomPin, error)
+	ToggleModerator(ctx context.Context, roomID, telegramID int64) (models.RoomRole, error)
+	SetVisibility(ctx context.Context, roomID int64, visibility models.RoomVisibility, department string) error
+	InviteCode(ctx context.Context, roomID int64) (string, error)
+	RotateInvite(ctx context.Context, roomID int64) (string, error)
+	RevokeInvite(ctx context.Context, roomID int64) error
+	LogMessage(ctx context.Context, m *models.RoomMessage) (int64, error)
+	History(ctx context.Context, roomID, beforeID int64, limit int) ([]*models.RoomMessage, error)
+	RemoveMessage(ctx context.Context, roomID, historyID int64) error
+	SetSlowMode(ctx context.Context, roomID int64, seconds int) error
+	CheckFlood(ctx context.Context, room *models.Room, telegramID int64, fingerprint string, exempt bool) error
+	ArchiveInactiveRooms(ctx context.Context) ([]*models.Room, error)
+	GetArchivedRooms(ctx context.Context) ([]*models.Room, error)
+	RestoreRoom(ctx context.Context, slug string) (*models.Room, error)
+	DeleteRoom(ctx context.Context, slug string) (*models.Room, []int64, error)
 }
 
 type ContentModerator interface {
@@ -79,6 +128,11 @@ type Gamifier interface {
 	GetLeaderboard(ctx context.Context) ([]models.User, error)
 }
 
+type AccessManager interface {
+	Can(ctx context.Context, telegramID int64, p models.Permission) bool
+	StaffWith(ctx context.Context, p models.Permission) ([]int64, error)
+}
+
 type CSSessionManager interface {
 	GetTimedOutSessions(ctx context.Context, timeoutMinutes int) ([]int64, error)
 	GetActiveSessionByAdmin(ctx context.Context, adminID int64) (int64, error)
@@ -95,7 +95,7 @@ func (s *ModerationService) IsSafe(ctx context.Context, imageURL string) (bool,
 			zap.String("message", data.Error.Message),
 			zap.String("request_id", data.Request.ID),
 		)
-		return true, "", nil
+		return true, "", fmt.Errorf("sightengine check failed: %s", data.Error.Message)
 	}
 
 	if data.Nudity.SexualActivity > 0.5 || data.Nudity.SexualDisplay > 0.5 || data.Nudity.Erotica > 0.8 {
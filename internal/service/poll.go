@@ -0,0 +1,320 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/config"
+	"github.com/pnj-anonymous-bot/internal/database"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+const (
+	pollBallotTTL       = time.Hour
+	pollDuplicateWindow = 7 * 24 * time.Hour
+	pollCreationKarma   = 3
+	// PollBreakdownMinVoters is the k-anonymity threshold: demographic groups
+	// with fewer voters are never shown on their own.
+	PollBreakdownMinVoters = 5
+)
+
+type PollService struct {
+	db    *database.DB
+	redis *RedisService
+	cfg   *config.Config
+}
+
+func NewPollService(db *database.DB, redis *RedisService, cfg *config.Config) *PollService {
+	return &PollService{db: db, redis: redis, cfg: cfg}
+}
+
+// CreatePoll applies the daily limit for user polls and rejects questions
+// that are already being asked in another open poll.
+func (s *PollService) CreatePoll(ctx context.Context, poll *models.Poll, options []string) (int64, error) {
+	if poll.AuthorID != nil && s.cfg.MaxPollsPerDay > 0 {
+		count, err := s.db.GetUserPollCountSince(ctx, *poll.AuthorID, time.Now().Add(-24*time.Hour))
+		if err != nil {
+			return 0, err
+		}
+		if count >= s.cfg.MaxPollsPerDay {
+			return 0, fmt.Errorf("kamu sudah mencapai batas %d polling per hari. Coba lagi besok", s.cfg.MaxPollsPerDay)
+		}
+	}
+
+	existingID, err := s.db.FindOpenPollByQuestion(ctx, poll.Scope, poll.Department, poll.Question, time.Now().Add(-pollDuplicateWindow))
+	if err != nil {
+		return 0, err
+	}
+	if existingID != 0 {
+		return 0, fmt.Errorf("polling dengan pertanyaan yang sama masih berjalan (#%d). Ikut memilih lewat /vote_poll %d", existingID, existingID)
+	}
+
+	return s.db.CreatePoll(ctx, poll, options)
+}
+
+// RewardAuthor gives the poll author karma the first time the poll reaches
+// PollKarmaMinVotes voters other than the author, returning the author when karma was granted.
+func (s *PollService) RewardAuthor(ctx context.Context, pollID int64) (int64, bool) {
+	authorID, ok, err := s.db.ClaimPollKarma(ctx, pollID, s.cfg.PollKarmaMinVotes)
+	if err != nil || !ok {
+		return 0, false
+	}
+	if err := s.db.IncrementUserKarma(ctx, authorID, pollCreationKarma); err != nil {
+		return 0, false
+	}
+	return authorID, true
+}
+
+func pollBallotKey(pollID, telegramID int64) string {
+	return fmt.Sprintf("poll_ballot:%d:%d", pollID, telegramID)
+}
+
+// GetBallot returns the options a voter has selected but not yet submitted,
+// in selection order.
+func (s *PollService) GetBallot(ctx context.Context, pollID, telegramID int64) []int64 {
+	raw, err := s.redis.GetClient().Get(ctx, pollBallotKey(pollID, telegramID)).Result()
+	if err != nil {
+		return nil
+	}
+	var selected []int64
+	if err := json.Unmarshal([]byte(raw), &selected); err != nil {
+		return nil
+	}
+	return selected
+}
+
+// ToggleOption adds or removes an option from the voter's draft ballot.
+func (s *PollService) ToggleOption(ctx context.Context, poll *models.Poll, telegramID, optionID int64) ([]int64, error) {
+	if poll.IsNative {
+		return nil, fmt.Errorf("polling ini hanya bisa dipilih lewat polling Telegram yang dikirim bot")
+	}
+	if poll.Type == models.PollSingle {
+		return nil, fmt.Errorf("polling ini hanya menerima satu pilihan")
+	}
+	if !slices.ContainsFunc(poll.Options, func(o *models.PollOption) bool { return o.ID == optionID }) {
+		return nil, fmt.Errorf("opsi tidak valid untuk polling ini")
+	}
+
+	selected := s.GetBallot(ctx, poll.ID, telegramID)
+	if i := slices.Index(selected, optionID); i >= 0 {
+		selected = slices.Delete(selected, i, i+1)
+	} else {
+		if poll.Type == models.PollMulti && len(selected) >= poll.MaxChoices {
+			return selected, fmt.Errorf("maksimal %d pilihan", poll.MaxChoices)
+		}
+		selected = append(selected, optionID)
+	}
+
+	data, _ := json.Marshal(selected)
+	if err := s.redis.GetClient().Set(ctx, pollBallotKey(poll.ID, telegramID), data, pollBallotTTL).Err(); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pilihan: %w", err)
+	}
+	return selected, nil
+}
+
+func (s *PollService) SubmitBallot(ctx context.Context, poll *models.Poll, telegramID int64) error {
+	selected := s.GetBallot(ctx, poll.ID, telegramID)
+	if len(selected) == 0 {
+		return fmt.Errorf("pilih minimal satu opsi terlebih dahulu")
+	}
+	if err := s.db.SubmitPollBallot(ctx, poll.ID, telegramID, selected); err != nil {
+		return err
+	}
+	_ = s.redis.GetClient().Del(ctx, pollBallotKey(poll.ID, telegramID)).Err()
+	return nil
+}
+
+func (s *PollService) Vote(ctx context.Context, pollID, telegramID, optionID int64) error {
+	return s.db.VotePoll(ctx, pollID, telegramID, optionID)
+}
+
+// SupportsNativePoll reports whether a poll can be mirrored as a native
+// Telegram poll, which has no ranking and no "up to K" limit.
+func SupportsNativePoll(p *models.Poll, optionCount int) bool {
+	return p.Type == models.PollSingle || (p.Type == models.PollMulti && p.MaxChoices >= optionCount)
+}
+
+// RecordNativeCounts stores the anonymous results Telegram reports for one
+// native poll: its total voters and voterCounts, indexed like the poll's
+// options. Updates for
+// closed polls are ignored so the final tally stays fixed.
+func (s *PollService) RecordNativeCounts(ctx context.Context, telegramPollID string, voters int, voterCounts []int) error {
+	tp, err := s.db.GetTelegramPoll(ctx, telegramPollID)
+	if err != nil || tp == nil {
+		return err
+	}
+	poll, err := s.db.GetPoll(ctx, tp.PollID)
+	if err != nil || poll == nil || !poll.IsOpen(time.Now()) {
+		return err
+	}
+	if len(voterCounts) != len(poll.Options) {
+		return fmt.Errorf("native poll %s has %d options, expected %d", telegramPollID, len(voterCounts), len(poll.Options))
+	}
+
+	counts := make(map[int64]int, len(voterCounts))
+	for i, count := range voterCounts {
+		counts[poll.Options[i].ID] = count
+	}
+	return s.db.SetTelegramPollCounts(ctx, telegramPollID, voters, counts)
+}
+
+func (s *PollService) RankedResult(ctx context.Context, poll *models.Poll) (*RunoffResult, error) {
+	votes, err := s.db.GetPollBallots(ctx, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ballots [][]int64
+	var current int64
+	for _, v := range votes {
+		if len(ballots) == 0 || v.TelegramID != current {
+			ballots = append(ballots, nil)
+			current = v.TelegramID
+		}
+		ballots[len(ballots)-1] = append(ballots[len(ballots)-1], v.OptionID)
+	}
+
+	optionIDs := make([]int64, len(poll.Options))
+	for i, o := range poll.Options {
+		optionIDs[i] = o.ID
+	}
+	result := TallyInstantRunoff(optionIDs, ballots)
+	return &result, nil
+}
+
+type RunoffResult struct {
+	// Winner is 0 when there are no ballots or the final candidates tie.
+	Winner int64
+	// Rounds holds the vote count per remaining option for each round.
+	Rounds []map[int64]int
+}
+
+// TallyInstantRunoff counts ranked ballots round by round, eliminating the
+// option with the fewest votes until one has a majority of the ballots still
+// in play. Ties for last place eliminate the option listed last.
+func TallyInstantRunoff(optionIDs []int64, ballots [][]int64) RunoffResult {
+	var result RunoffResult
+	remaining := slices.Clone(optionIDs)
+
+	for len(remaining) > 0 {
+		counts := make(map[int64]int, len(remaining))
+		for _, id := range remaining {
+			counts[id] = 0
+		}
+
+		active := 0
+		for _, ballot := range ballots {
+			for _, choice := range ballot {
+				if _, ok := counts[choice]; ok {
+					counts[choice]++
+					active++
+					break
+				}
+			}
+		}
+		result.Rounds = append(result.Rounds, counts)
+
+		if active == 0 {
+			return result
+		}
+
+		lowest := remaining[0]
+		for _, id := range remaining {
+			if counts[id]*2 > active {
+				result.Winner = id
+				return result
+			}
+			if counts[id] <= counts[lowest] {
+				lowest = id
+			}
+		}
+
+		if allEqual(counts, remaining) {
+			return result
+		}
+		remaining = slices.DeleteFunc(remaining, func(id int64) bool { return id == lowest })
+	}
+	return result
+}
+
+func allEqual(counts map[int64]int, ids []int64) bool {
+	for _, id := range ids[1:] {
+		if counts[id] != counts[ids[0]] {
+			return false
+		}
+	}
+	return true
+}
+
+type PollBreakdownBucket struct {
+	Label  string
+	Voters int
+	Votes  map[int64]int
+	// Merged marks the combined group of buckets too small to show alone.
+	Merged bool
+}
+
+// Breakdown aggregates a poll's votes by voter department, year or gender
+// with small groups suppressed.
+func (s *PollService) Breakdown(ctx context.Context, poll *models.Poll, dimension string) ([]PollBreakdownBucket, error) {
+	counts, voters, err := s.db.GetPollBreakdown(ctx, poll.ID, dimension)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]PollBreakdownBucket, 0, len(voters))
+	index := make(map[string]int, len(voters))
+	for label, n := range voters {
+		index[label] = len(buckets)
+		buckets = append(buckets, PollBreakdownBucket{Label: label, Voters: n, Votes: make(map[int64]int)})
+	}
+	for _, c := range counts {
+		if i, ok := index[c.Bucket]; ok {
+			buckets[i].Votes[c.OptionID] += c.Votes
+		}
+	}
+	return SuppressSmallBuckets(buckets, PollBreakdownMinVoters), nil
+}
+
+// SuppressSmallBuckets hides every bucket with fewer than k voters. Hidden
+// buckets are merged into one group when it reaches k voters; otherwise the
+// next smallest buckets are hidden too, so the hidden votes can't be worked
+// out by subtracting the visible buckets from the overall result.
+func SuppressSmallBuckets(buckets []PollBreakdownBucket, k int) []PollBreakdownBucket {
+	sorted := slices.Clone(buckets)
+	slices.SortStableFunc(sorted, func(a, b PollBreakdownBucket) int {
+		if a.Voters != b.Voters {
+			return b.Voters - a.Voters
+		}
+		return cmp.Compare(a.Label, b.Label)
+	})
+
+	cut := len(sorted)
+	for cut > 0 && sorted[cut-1].Voters < k {
+		cut--
+	}
+	hidden := 0
+	for _, b := range sorted[cut:] {
+		hidden += b.Voters
+	}
+	for hidden > 0 && hidden < k && cut > 0 {
+		cut--
+		hidden += sorted[cut].Voters
+	}
+
+	visible := sorted[:cut]
+	if hidden >= k {
+		merged := PollBreakdownBucket{Voters: hidden, Votes: make(map[int64]int), Merged: true}
+		for _, b := range sorted[cut:] {
+			for optionID, n := range b.Votes {
+				merged.Votes[optionID] += n
+			}
+		}
+		visible = append(visible, merged)
+	}
+	return visible
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pnj-anonymous-bot/internal/logger"
+	"github.com/pnj-anonymous-bot/internal/models"
+	"go.uber.org/zap"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	pollChartWidth    = 800
+	pollChartPadding  = 32
+	pollChartBarH     = 26
+	pollChartRowGap   = 18
+	pollChartCacheTTL = 7 * 24 * time.Hour
+)
+
+var (
+	chartBackground = color.RGBA{0xF7, 0xF8, 0xFC, 0xFF}
+	chartText       = color.RGBA{0x1F, 0x23, 0x33, 0xFF}
+	chartMuted      = color.RGBA{0x6B, 0x72, 0x80, 0xFF}
+	chartTrack      = color.RGBA{0xE3, 0xE6, 0xEE, 0xFF}
+	chartBar        = color.RGBA{0x4F, 0x7C, 0xFF, 0xFF}
+	chartLeader     = color.RGBA{0x22, 0xB5, 0x73, 0xFF}
+)
+
+type PollChartService struct {
+	redis *RedisService
+}
+
+func NewPollChartService(redis *RedisService) *PollChartService {
+	return &PollChartService{redis: redis}
+}
+
+// pollChartKey identifies a chart by the vote count of every option, so a
+// retracted and re-cast vote with the same total still renders a new chart.
+func pollChartKey(p *models.Poll) string {
+	h := fnv.New64a()
+	for _, opt := range p.Options {
+		fmt.Fprintf(h, "%d=%d;", opt.ID, opt.VoteCount)
+	}
+	return fmt.Sprintf("poll_chart:%d:%x", p.ID, h.Sum64())
+}
+
+// CachedFileID returns the Telegram file_id of a chart already uploaded for
+// this exact vote distribution, or "" when it has to be rendered again.
+func (s *PollChartService) CachedFileID(ctx context.Context, p *models.Poll) string {
+	fileID, err := s.redis.GetClient().Get(ctx, pollChartKey(p)).Result()
+	if err != nil {
+		return ""
+	}
+	return fileID
+}
+
+func (s *PollChartService) StoreFileID(ctx context.Context, p *models.Poll, fileID string) {
+	if err := s.redis.GetClient().Set(ctx, pollChartKey(p), fileID, pollChartCacheTTL).Err(); err != nil {
+		logger.Warn("Failed to cache poll chart", zap.Int64("poll_id", p.ID), zap.Error(err))
+	}
+}
+
+func PollTotalVotes(p *models.Poll) int {
+	total := 0
+	for _, opt := range p.Options {
+		total += opt.VoteCount
+	}
+	return total
+}
+
+func loadFace(ttf []byte, size float64) (font.Face, error) {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// RenderPollChart draws the poll results as a horizontal bar chart PNG.
+func RenderPollChart(p *models.Poll) ([]byte, error) {
+	titleFace, err := loadFace(gobold.TTF, 26)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart font: %w", err)
+	}
+	defer func() { _ = titleFace.Close() }()
+	textFace, err := loadFace(goregular.TTF, 18)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart font: %w", err)
+	}
+	defer func() { _ = textFace.Close() }()
+
+	contentW := pollChartWidth - 2*pollChartPadding
+	titleLines := wrapText(titleFace, p.Question, contentW, 3)
+	titleLineH := titleFace.Metrics().Height.Ceil()
+	textLineH := textFace.Metrics().Height.Ceil()
+	rowH := textLineH + 6 + pollChartBarH + pollChartRowGap
+
+	height := pollChartPadding + len(titleLines)*titleLineH + pollChartRowGap +
+		len(p.Options)*rowH + textLineH + pollChartPadding
+
+	img := image.NewRGBA(image.Rect(0, 0, pollChartWidth, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+
+	y := pollChartPadding
+	for _, line := range titleLines {
+		drawText(img, titleFace, chartText, pollChartPadding, y+titleFace.Metrics().Ascent.Ceil(), line)
+		y += titleLineH
+	}
+	y += pollChartRowGap
+
+	total := PollTotalVotes(p)
+	leader := 0
+	for _, opt := range p.Options {
+		if opt.VoteCount > leader {
+			leader = opt.VoteCount
+		}
+	}
+
+	for _, opt := range p.Options {
+		percent := 0
+		if total > 0 {
+			percent = opt.VoteCount * 100 / total
+		}
+		stat := fmt.Sprintf("%d%% (%d)", percent, opt.VoteCount)
+		statW := font.MeasureString(textFace, stat).Ceil()
+
+		label := truncateToWidth(textFace, opt.OptionText, contentW-statW-16)
+		baseline := y + textFace.Metrics().Ascent.Ceil()
+		drawText(img, textFace, chartText, pollChartPadding, baseline, label)
+		drawText(img, textFace, chartMuted, pollChartWidth-pollChartPadding-statW, baseline, stat)
+		y += textLineH + 6
+
+		track := image.Rect(pollChartPadding, y, pollChartWidth-pollChartPadding, y+pollChartBarH)
+		draw.Draw(img, track, &image.Uniform{chartTrack}, image.Point{}, draw.Src)
+		if total > 0 && opt.VoteCount > 0 {
+			barColor := chartBar
+			if opt.VoteCount == leader {
+				barColor = chartLeader
+			}
+			fill := track
+			fill.Max.X = track.Min.X + track.Dx()*opt.VoteCount/total
+			draw.Draw(img, fill, &image.Uniform{barColor}, image.Point{}, draw.Src)
+		}
+		y += pollChartBarH + pollChartRowGap
+	}
+
+	drawText(img, textFace, chartMuted, pollChartPadding, y+textFace.Metrics().Ascent.Ceil(),
+		fmt.Sprintf("Polling #%d · Total %d suara", p.ID, total))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func drawText(dst draw.Image, face font.Face, c color.Color, x, y int, text string) {
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+func truncateToWidth(face font.Face, text string, maxW int) string {
+	if font.MeasureString(face, text).Ceil() <= maxW {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "…"
+		if font.MeasureString(face, candidate).Ceil() <= maxW {
+			return candidate
+		}
+	}
+	return "…"
+}
+
+func wrapText(face font.Face, text string, maxW, maxLines int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if font.MeasureString(face, candidate).Ceil() <= maxW || current == "" {
+			current = candidate
+			continue
+		}
+		lines = append(lines, current)
+		current = word
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+
+	for i, line := range lines {
+		lines[i] = truncateToWidth(face, line, maxW)
+	}
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = truncateToWidth(face, lines[maxLines-1]+" …", maxW)
+	}
+	return lines
+}
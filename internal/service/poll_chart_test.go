@@ -0,0 +1,68 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+func TestRenderPollChart(t *testing.T) {
+	poll := &models.Poll{
+		ID:       7,
+		Question: strings.Repeat("Apakah kantin kampus sebaiknya buka sampai malam hari? ", 4),
+		Options: []*models.PollOption{
+			{ID: 1, OptionText: "Setuju", VoteCount: 12},
+			{ID: 2, OptionText: "Tidak setuju", VoteCount: 3},
+			{ID: 3, OptionText: strings.Repeat("Opsi yang sangat panjang ", 10), VoteCount: 0},
+		},
+	}
+
+	data, err := RenderPollChart(poll)
+	if err != nil {
+		t.Fatalf("RenderPollChart failed: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("chart is not a valid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != pollChartWidth {
+		t.Errorf("expected width %d, got %d", pollChartWidth, img.Bounds().Dx())
+	}
+
+	empty := &models.Poll{ID: 8, Question: "Belum ada suara?", Options: []*models.PollOption{{ID: 1, OptionText: "A"}, {ID: 2, OptionText: "B"}}}
+	if _, err := RenderPollChart(empty); err != nil {
+		t.Errorf("RenderPollChart should handle polls without votes: %v", err)
+	}
+}
+
+func TestPollChartCache(t *testing.T) {
+	setupTestRedis(t)
+	chartSvc := NewPollChartService(NewRedisService(os.Getenv("REDIS_URL")))
+	ctx := context.Background()
+
+	p := &models.Poll{ID: 1, Options: []*models.PollOption{{ID: 1, VoteCount: 3}, {ID: 2, VoteCount: 2}}}
+	if fileID := chartSvc.CachedFileID(ctx, p); fileID != "" {
+		t.Errorf("expected empty cache, got %q", fileID)
+	}
+
+	chartSvc.StoreFileID(ctx, p, "file-abc")
+	if fileID := chartSvc.CachedFileID(ctx, p); fileID != "file-abc" {
+		t.Errorf("expected cached file id, got %q", fileID)
+	}
+
+	p.Options[0].VoteCount++
+	if fileID := chartSvc.CachedFileID(ctx, p); fileID != "" {
+		t.Errorf("a new vote count should miss the cache, got %q", fileID)
+	}
+
+	p.Options[0].VoteCount, p.Options[1].VoteCount = 2, 3
+	if fileID := chartSvc.CachedFileID(ctx, p); fileID != "" {
+		t.Errorf("a moved vote with the same total should miss the cache, got %q", fileID)
+	}
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/pnj-anonymous-bot/internal/config"
+	"github.com/pnj-anonymous-bot/internal/models"
+)
+
+func TestTallyInstantRunoff(t *testing.T) {
+	options := []int64{1, 2, 3}
+
+	tests := []struct {
+		name    string
+		ballots [][]int64
+		winner  int64
+		rounds  int
+	}{
+		{"no ballots", nil, 0, 1},
+		{"first round majority", [][]int64{{1, 2}, {1}, {2, 1}}, 1, 1},
+		{
+			"eliminated votes transfer",
+			[][]int64{{1, 3}, {1}, {2, 1}, {2}, {3, 2}, {3, 2}, {2}},
+			2, 2,
+		},
+		{
+			"exhausted ballots drop out of the majority",
+			[][]int64{{1}, {1}, {1}, {2}, {2}, {3}, {3}},
+			// 3 is eliminated and its ballots rank nothing else, so 1 wins
+			// with 3 of the 5 ballots still in play.
+			1, 2,
+		},
+		{"final tie has no winner", [][]int64{{1}, {2}, {3}}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TallyInstantRunoff(options, tt.ballots)
+			if result.Winner != tt.winner {
+				t.Errorf("expected winner %d, got %d", tt.winner, result.Winner)
+			}
+			if len(result.Rounds) != tt.rounds {
+				t.Errorf("expected %d rounds, got %d", tt.rounds, len(result.Rounds))
+			}
+		})
+	}
+}
+
+func TestPollServiceBallots(t *testing.T) {
+	db := setupTestDB(t)
+	setupTestRedis(t)
+	pollSvc := NewPollService(db, NewRedisService(os.Getenv("REDIS_URL")), &config.Config{})
+	ctx := context.Background()
+
+	author, voter := int64(7001), int64(7002)
+	createUserForTest(t, db, author, "", "", 0)
+	createUserForTest(t, db, voter, "", "", 0)
+
+	pollID, err := db.CreatePoll(ctx, &models.Poll{AuthorID: &author, Question: "Menu?", Type: models.PollMulti, MaxChoices: 2},
+		[]string{"A", "B", "C"})
+	if err != nil {
+		t.Fatalf("CreatePoll failed: %v", err)
+	}
+	poll, _ := db.GetPoll(ctx, pollID)
+	a, b, c := poll.Options[0].ID, poll.Options[1].ID, poll.Options[2].ID
+
+	if err := pollSvc.SubmitBallot(ctx, poll, voter); err == nil {
+		t.Error("submitting an empty ballot should fail")
+	}
+
+	_, _ = pollSvc.ToggleOption(ctx, poll, voter, a)
+	_, _ = pollSvc.ToggleOption(ctx, poll, voter, b)
+	if _, err := pollSvc.ToggleOption(ctx, poll, voter, c); err == nil {
+		t.Error("selecting more than the limit should fail")
+	}
+	selected, _ := pollSvc.ToggleOption(ctx, poll, voter, a)
+	if !slices.Equal(selected, []int64{b}) {
+		t.Errorf("toggling again should deselect, got %v", selected)
+	}
+	if _, err := pollSvc.ToggleOption(ctx, poll, voter, 9999); err == nil {
+		t.Error("toggling an unknown option should fail")
+	}
+
+	_, _ = pollSvc.ToggleOption(ctx, poll, voter, c)
+	if err := pollSvc.SubmitBallot(ctx, poll, voter); err != nil {
+		t.Fatalf("SubmitBallot failed: %v", err)
+	}
+	if draft := pollSvc.GetBallot(ctx, pollID, voter); draft != nil {
+		t.Errorf("draft should be cleared after submit, got %v", draft)
+	}
+
+	single := &models.Poll{ID: pollID, Type: models.PollSingle, Options: poll.Options}
+	if _, err := pollSvc.ToggleOption(ctx, single, voter, a); err == nil {
+		t.Error("single-choice polls should not use draft ballots")
+	}
+}
+
+func TestPollServiceRankedResult(t *testing.T) {
+	db := setupTestDB(t)
+	setupTestRedis(t)
+	pollSvc := NewPollService(db, NewRedisService(os.Getenv("REDIS_URL")), &config.Config{})
+	ctx := context.Background()
+
+	author := int64(7011)
+	createUserForTest(t, db, author, "", "", 0)
+	pollID, _ := db.CreatePoll(ctx, &models.Poll{AuthorID: &author, Question: "Ketua?", Type: models.PollRanked},
+		[]string{"A", "B", "C"})
+	poll, _ := db.GetPoll(ctx, pollID)
+	a, b, c := poll.Options[0].ID, poll.Options[1].ID, poll.Options[2].ID
+
+	ballots := [][]int64{{a, b}, {a}, {b, a}, {c, b}, {c, b}}
+	for i, ballot := range ballots {
+		voter := int64(7100 + i)
+		createUserForTest(t, db, voter, "", "", 0)
+		if err := db.SubmitPollBallot(ctx, pollID, voter, ballot); err != nil {
+			t.Fatalf("SubmitPollBallot failed: %v", err)
+		}
+	}
+
+	result, err := pollSvc.RankedResult(ctx, poll)
+	if err != nil {
+		t.Fatalf("RankedResult failed: %v", err)
+	}
+	// Round 1: A=2, B=1, C=2 → B out; its ballot moves to A (3 of 5).
+	if result.Winner != a || len(result.Rounds) != 2 {
+		t.Errorf("expected A to win in 2 rounds, got %d after %d rounds", result.Winner, len(result.Rounds))
+	}
+}
+
+func TestSuppressSmallBuckets(t *testing.T) {
+	bucket := func(label string, voters int) PollBreakdownBucket {
+		return PollBreakdownBucket{Label: label, Voters: voters, Votes: map[int64]int{1: voters}}
+	}
+	labels := func(buckets []PollBreakdownBucket) []string {
+		var out []string
+		for _, b := range buckets {
+			if b.Merged {
+				out = append(out, "*")
+				continue
+			}
+			out = append(out, b.Label)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		buckets []PollBreakdownBucket
+		want    []string
+	}{
+		{"all large", []PollBreakdownBucket{bucket("A", 5), bucket("B", 9)}, []string{"B", "A"}},
+		{"small ones merged", []PollBreakdownBucket{bucket("A", 9), bucket("B", 3), bucket("C", 2)}, []string{"A", "*"}},
+		{"single small one hidden with the next", []PollBreakdownBucket{bucket("A", 9), bucket("B", 6), bucket("C", 1)}, []string{"A", "*"}},
+		{"too few voters overall", []PollBreakdownBucket{bucket("A", 3), bucket("B", 1)}, nil},
+		{"lone small bucket with nothing to merge", []PollBreakdownBucket{bucket("A", 4)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labels(SuppressSmallBuckets(tt.buckets, 5)); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	merged := SuppressSmallBuckets([]PollBreakdownBucket{bucket("A", 9), bucket("B", 6), bucket("C", 1)}, 5)
+	if last := merged[len(merged)-1]; last.Voters != 7 || last.Votes[1] != 7 {
+		t.Errorf("merged bucket should combine B and C, got %+v", last)
+	}
+}
+
+func TestPollServiceBreakdown(t *testing.T) {
+	db := setupTestDB(t)
+	setupTestRedis(t)
+	pollSvc := NewPollService(db, NewRedisService(os.Getenv("REDIS_URL")), &config.Config{})
+	ctx := context.Background()
+
+	author := int64(7201)
+	createUserForTest(t, db, author, "", "", 0)
+	pollID, _ := db.CreatePoll(ctx, &models.Poll{AuthorID: &author, Question: "Parkir naik?"}, []string{"Setuju", "Tidak"})
+	poll, _ := db.GetPoll(ctx, pollID)
+	yes, no := poll.Options[0].ID, poll.Options[1].ID
+
+	voter := int64(7300)
+	vote := func(department string, optionID int64) {
+		voter++
+		createUserForTest(t, db, voter, "Perempuan", department, 2023)
+		if err := db.VotePoll(ctx, pollID, voter, optionID); err != nil {
+			t.Fatalf("VotePoll failed: %v", err)
+		}
+	}
+	for i := 0; i < 6; i++ {
+		vote("Teknik Sipil", yes)
+	}
+	vote("Teknik Sipil", no)
+	for i := 0; i < 5; i++ {
+		vote("Akuntansi", no)
+	}
+	vote("Teknik Mesin", yes)
+
+	buckets, err := pollSvc.Breakdown(ctx, poll, "department")
+	if err != nil {
+		t.Fatalf("Breakdown failed: %v", err)
+	}
+	// Teknik Mesin alone is below the threshold, so Akuntansi is merged with
+	// it rather than shown and used to infer the single Mesin vote.
+	if len(buckets) != 2 || buckets[0].Label != "Teknik Sipil" || buckets[0].Votes[yes] != 6 || buckets[0].Votes[no] != 1 {
+		t.Fatalf("expected Teknik Sipil and a merged bucket, got %+v", buckets)
+	}
+	if other := buckets[1]; !other.Merged || other.Voters != 6 || other.Votes[yes] != 1 || other.Votes[no] != 5 {
+		t.Errorf("unexpected merged bucket %+v", other)
+	}
+
+	buckets, _ = pollSvc.Breakdown(ctx, poll, "year")
+	if len(buckets) != 1 || buckets[0].Label != "2023" || buckets[0].Voters != 13 {
+		t.Errorf("expected one 2023 bucket with 13 voters, got %+v", buckets)
+	}
+
+	if _, err := pollSvc.Breakdown(ctx, poll, "telegram_id"); err == nil {
+		t.Error("unknown dimensions should be rejected")
+	}
+}
+
+func TestPollServiceCreateLimits(t *testing.T) {
+	db := setupTestDB(t)
+	setupTestRedis(t)
+	cfg := &config.Config{MaxPollsPerDay: 2, PollKarmaMinVotes: 2}
+	pollSvc := NewPollService(db, NewRedisService(os.Getenv("REDIS_URL")), cfg)
+	ctx := context.Background()
+
+	author := int64(7401)
+	createUserForTest(t, db, author, "", "", 0)
+	newPoll := func(question string) *models.Poll {
+		return &models.Poll{AuthorID: &author, Question: question}
+	}
+
+	firstID, err := pollSvc.CreatePoll(ctx, newPoll("Parkir naik?"), []string{"Ya", "Tidak"})
+	if err != nil {
+		t.Fatalf("CreatePoll failed: %v", err)
+	}
+	if _, err := pollSvc.CreatePoll(ctx, newPoll("parkir NAIK?"), []string{"Ya", "Tidak"}); err == nil {
+		t.Error("duplicate open question should be rejected")
+	}
+	if _, err := pollSvc.CreatePoll(ctx, newPoll("Kantin buka malam?"), []string{"Ya", "Tidak"}); err != nil {
+		t.Fatalf("second poll should be allowed: %v", err)
+	}
+	if _, err := pollSvc.CreatePoll(ctx, newPoll("Wifi lambat?"), []string{"Ya", "Tidak"}); err == nil {
+		t.Error("third poll in a day should hit the limit")
+	}
+	if _, err := pollSvc.CreatePoll(ctx, &models.Poll{Scope: models.PollGlobal, Question: "Libur tambahan?"}, []string{"Ya", "Tidak"}); err != nil {
+		t.Errorf("global polls are not rate limited: %v", err)
+	}
+	deptPoll := func(dept string) *models.Poll {
+		return &models.Poll{Scope: models.PollDepartment, Department: dept, Question: "Parkir naik?"}
+	}
+	if _, err := pollSvc.CreatePoll(ctx, deptPoll("Akuntansi"), []string{"Ya", "Tidak"}); err != nil {
+		t.Errorf("same question in another scope should be allowed: %v", err)
+	}
+	if _, err := pollSvc.CreatePoll(ctx, deptPoll("Teknik Sipil"), []string{"Ya", "Tidak"}); err != nil {
+		t.Errorf("same question in another department should be allowed: %v", err)
+	}
+	if _, err := pollSvc.CreatePoll(ctx, deptPoll("Akuntansi"), []string{"Ya", "Tidak"}); err == nil {
+		t.Error("duplicate question in the same department should be rejected")
+	}
+
+	user, _ := db.GetUser(ctx, author)
+	karma := user.Karma
+
+	poll, _ := db.GetPoll(ctx, firstID)
+	if err := pollSvc.Vote(ctx, firstID, author, poll.Options[0].ID); err != nil {
+		t.Fatalf("Vote failed: %v", err)
+	}
+	for i, voter := range []int64{7402, 7403, 7404} {
+		createUserForTest(t, db, voter, "", "", 0)
+		if err := pollSvc.Vote(ctx, firstID, voter, poll.Options[0].ID); err != nil {
+			t.Fatalf("Vote failed: %v", err)
+		}
+		_, rewarded := pollSvc.RewardAuthor(ctx, firstID)
+		if rewarded != (i == 1) {
+			t.Errorf("vote %d: rewarded = %v", i+1, rewarded)
+		}
+	}
+
+	user, _ = db.GetUser(ctx, author)
+	if user.Karma != karma+pollCreationKarma {
+		t.Errorf("expected karma %d, got %d", karma+pollCreationKarma, user.Karma)
+	}
+	if count, _ := db.GetUserPollCountContext(ctx, author); count != 1 {
+		t.Errorf("only the rewarded poll should count toward POLL_MAKER, got %d", count)
+	}
+}
+
+func TestPollServiceNativeCounts(t *testing.T) {
+	db := setupTestDB(t)
+	setupTestRedis(t)
+	pollSvc := NewPollService(db, NewRedisService(os.Getenv("REDIS_URL")), &config.Config{})
+	ctx := context.Background()
+
+	voter := int64(7511)
+	createUserForTest(t, db, voter, "", "", 0)
+	pollID, _ := db.CreatePoll(ctx, &models.Poll{Scope: models.PollGlobal, Question: "Kantin baru?"}, []string{"Ya", "Tidak"})
+	_ = db.SaveTelegramPoll(ctx, &models.TelegramPoll{TelegramPollID: "tg-a", PollID: pollID, ChatID: 1, MessageID: 10})
+	_ = db.SaveTelegramPoll(ctx, &models.TelegramPoll{TelegramPollID: "tg-b", PollID: pollID, ChatID: 2, MessageID: 11})
+	poll, _ := db.GetPoll(ctx, pollID)
+	_ = db.VotePoll(ctx, pollID, voter, poll.Options[0].ID)
+
+	if err := pollSvc.RecordNativeCounts(ctx, "tg-a", 1, []int{1, 0}); err != nil {
+		t.Fatalf("RecordNativeCounts failed: %v", err)
+	}
+	_ = pollSvc.RecordNativeCounts(ctx, "tg-b", 1, []int{0, 1})
+	// A later update replaces the earlier tally of the same native poll.
+	_ = pollSvc.RecordNativeCounts(ctx, "tg-a", 1, []int{0, 1})
+	if err := pollSvc.RecordNativeCounts(ctx, "tg-a", 1, []int{1}); err == nil {
+		t.Error("a tally with the wrong number of options should fail")
+	}
+	if err := pollSvc.RecordNativeCounts(ctx, "unknown", 1, []int{1, 1}); err != nil {
+		t.Errorf("unknown telegram polls should be ignored, got %v", err)
+	}
+
+	poll, _ = db.GetPoll(ctx, pollID)
+	if poll.Options[0].VoteCount != 1 || poll.Options[1].VoteCount != 2 {
+		t.Fatalf("expected button and native votes combined, got %d %d", poll.Options[0].VoteCount, poll.Options[1].VoteCount)
+	}
+	if count, _ := db.GetPollVoteCountContext(ctx, pollID); count != 3 {
+		t.Errorf("expected 1 button voter and 2 native voters, got %d", count)
+	}
+
+	_ = db.ClosePoll(ctx, pollID)
+	_ = pollSvc.RecordNativeCounts(ctx, "tg-b", 1, []int{5, 5})
+	poll, _ = db.GetPoll(ctx, pollID)
+	if poll.Options[0].VoteCount != 1 {
+		t.Errorf("counts should not change after the poll closes, got %d", poll.Options[0].VoteCount)
+	}
+
+	natives, _ := db.GetTelegramPolls(ctx, pollID)
+	if len(natives) != 2 {
+		t.Errorf("expected both saved native polls, got %+v", natives)
+	}
+
+	nativeID, _ := db.CreatePoll(ctx, &models.Poll{Scope: models.PollGlobal, Question: "Wifi baru?", IsNative: true}, []string{"Ya", "Tidak"})
+	native, _ := db.GetPoll(ctx, nativeID)
+	if !native.IsNative {
+		t.Fatal("expected the poll to be stored as native")
+	}
+	if err := pollSvc.Vote(ctx, nativeID, voter, native.Options[0].ID); err == nil {
+		t.Error("native polls should reject inline votes")
+	}
+	if _, err := pollSvc.ToggleOption(ctx, native, voter, native.Options[0].ID); err == nil {
+		t.Error("native polls should reject inline ballots")
+	}
+
+	if !SupportsNativePoll(poll, 2) || SupportsNativePoll(&models.Poll{Type: models.PollRanked}, 3) ||
+		SupportsNativePoll(&models.Poll{Type: models.PollMulti, MaxChoices: 2}, 3) {
+		t.Error("only single and unlimited multi-choice polls can be native")
+	}
+}
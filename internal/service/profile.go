@@ -127,30 +127,30 @@ func (s *ProfileService) BlockUser(ctx context.Context, userID, blockedID int64)
 	return s.db.BlockUser(ctx, userID, blockedID)
 }
 
-func (s *ProfileService) SendWhisper(ctx context.Context, senderID int64, targetDept, content string) ([]int64, error) {
+func (s *ProfileService) SendWhisper(ctx context.Context, senderID int64, targetDept, content string) (int64, []int64, error) {
 	user, err := s.db.GetUser(ctx, senderID)
 	if err != nil || user == nil {
-		return nil, fmt.Errorf("user not found")
+		return 0, nil, fmt.Errorf("user not found")
 	}
 
 	if s.cfg.MaxWhispersPerHour > 0 {
 		count, err := s.db.GetUserWhisperCount(ctx, senderID, time.Now().Add(-1*time.Hour))
 		if err == nil && count >= s.cfg.MaxWhispersPerHour {
-			return nil, fmt.Errorf("kamu sudah mencapai batas %d whisper per jam. Coba lagi nanti", s.cfg.MaxWhispersPerHour)
+			return 0, nil, fmt.Errorf("kamu sudah mencapai batas %d whisper per jam. Coba lagi nanti", s.cfg.MaxWhispersPerHour)
 		}
 	}
 
-	_, err = s.db.CreateWhisper(ctx, senderID, targetDept, content, string(user.Department), string(user.Gender))
+	whisperID, err := s.db.CreateWhisper(ctx, senderID, targetDept, content, string(user.Department), string(user.Gender))
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 
 	targets, err := s.db.GetUsersByDepartment(ctx, targetDept, senderID)
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 
-	return targets, nil
+	return whisperID, targets, nil
 }
 
 var (
@@ -270,10 +270,13 @@ func TestProfileServiceSendWhisper(t *testing.T) {
 	_ = db.UpdateUserVerified(ctx, targetID1, true)
 	_ = db.UpdateUserVerified(ctx, targetID2, true)
 
-	targets, err := profileSvc.SendWhisper(ctx, senderID, "Teknik Mesin", "Hello dari TIK ke Mesin!")
+	whisperID, targets, err := profileSvc.SendWhisper(ctx, senderID, "Teknik Mesin", "Hello dari TIK ke Mesin!")
 	if err != nil {
 		t.Fatalf("SendWhisper failed: %v", err)
 	}
+	if whisperID == 0 {
+		t.Error("Expected whisper ID to be returned")
+	}
 	if len(targets) != 2 {
 		t.Errorf("Expected 2 targets, got %d", len(targets))
 	}
@@ -284,7 +287,7 @@ func TestProfileServiceSendWhisperUserNotFound(t *testing.T) {
 	profileSvc := NewProfileService(db, &config.Config{})
 	ctx := context.Background()
 
-	_, err := profileSvc.SendWhisper(ctx, 99999, "Teknik Mesin", "User not found test message.")
+	_, _, err := profileSvc.SendWhisper(ctx, 99999, "Teknik Mesin", "User not found test message.")
 	if err == nil {
 		t.Error("Expected error for non-existent sender")
 	}
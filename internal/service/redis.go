@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -136,6 +137,13 @@ func (r *RedisService) removeFromQueueInternal(ctx context.Context, telegramID i
 }
 
 func (r *RedisService) AllowPerMinute(ctx context.Context, action string, telegramID int64, limit int) (bool, int, error) {
+	return r.AllowPerWindow(ctx, action, strconv.FormatInt(telegramID, 10), limit, time.Minute)
+}
+
+// AllowPerWindow allows up to limit calls per window for the given action and
+// subject. The action is also the metrics label, so keep it low-cardinality
+// and put IDs in subject.
+func (r *RedisService) AllowPerWindow(ctx context.Context, action, subject string, limit int, window time.Duration) (bool, int, error) {
 	if limit <= 0 {
 		return true, 0, nil
 	}
@@ -144,7 +152,7 @@ func (r *RedisService) AllowPerMinute(ctx context.Context, action string, telegr
 	var retryAfter int
 
 	err := r.cb.Execute(func() error {
-		key := fmt.Sprintf("rate_limit:%s:%d", action, telegramID)
+		key := fmt.Sprintf("rate_limit:%s:%s", action, subject)
 
 		count, err := r.client.Incr(ctx, key).Result()
 		if err != nil {
@@ -153,7 +161,7 @@ func (r *RedisService) AllowPerMinute(ctx context.Context, action string, telegr
 		}
 
 		if count == 1 {
-			if err := r.client.Expire(ctx, key, time.Minute).Err(); err != nil {
+			if err := r.client.Expire(ctx, key, window).Err(); err != nil {
 				metrics.RedisErrors.WithLabelValues("rate_limit_expire").Inc()
 				return err
 			}
@@ -193,3 +201,47 @@ func (r *RedisService) AllowPerMinute(ctx context.Context, action string, telegr
 
 	return allowed, retryAfter, nil
 }
+
+// AllowOncePer allows a single call per interval for the given action and
+// subject, reporting the seconds left otherwise. Like AllowPerWindow it fails
+// open when Redis is unavailable.
+func (r *RedisService) AllowOncePer(ctx context.Context, action, subject string, interval time.Duration) (bool, int, error) {
+	if interval <= 0 {
+		return true, 0, nil
+	}
+
+	var allowed bool
+	var retryAfter int
+
+	err := r.cb.Execute(func() error {
+		key := fmt.Sprintf("rate_once:%s:%s", action, subject)
+
+		ok, err := r.client.SetNX(ctx, key, 1, interval).Result()
+		if err != nil {
+			metrics.RedisErrors.WithLabelValues("rate_once_setnx").Inc()
+			return err
+		}
+		if ok {
+			allowed = true
+			return nil
+		}
+
+		metrics.RateLimitHitsTotal.WithLabelValues(action).Inc()
+
+		retryAfter = 1
+		if ttl, err := r.client.TTL(ctx, key).Result(); err == nil && ttl > time.Second {
+			retryAfter = int(ttl.Seconds())
+		}
+		return nil
+	})
+
+	if err != nil {
+		logger.Warn("Rate limiter unavailable, failing open",
+			zap.String("action", action),
+			zap.Error(err),
+		)
+		return true, 0, err
+	}
+
+	return allowed, retryAfter, nil
+}
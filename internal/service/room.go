@@ -2,29 +2,70 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/pnj-anonymous-bot/internal/config"
 	"github.com/pnj-anonymous-bot/internal/database"
 	"github.com/pnj-anonymous-bot/internal/logger"
 	"github.com/pnj-anonymous-bot/internal/models"
 	"go.uber.org/zap"
 )
 
+const (
+	circleAliasAttempts = 10
+	maxCirclesPerUser   = 10
+	maxOwnedCircles     = 5
+	maxSlowModeSeconds  = 3600
+	inviteNonceBytes    = 4
+	inviteSigLength     = 16
+)
+
+var aliasColors = []string{
+	"Blue", "Red", "Green", "Golden", "Silver",
+	"Purple", "Orange", "Crimson", "Amber", "Teal",
+	"Indigo", "Coral", "Ivory", "Jade", "Scarlet",
+	"Azure", "Violet", "Copper", "Olive", "Pink",
+}
+
+// circleDeliveryTTL bounds how long after delivery a circle message can still
+// be moderated by replying to it.
+const circleDeliveryTTL = 48 * time.Hour
+
 type RoomService struct {
-	db *database.DB
+	db    *database.DB
+	redis *RedisService
+	cfg   *config.Config
 }
 
-func NewRoomService(db *database.DB) *RoomService {
-	return &RoomService{db: db}
+func NewRoomService(db *database.DB, redis *RedisService, cfg *config.Config) *RoomService {
+	return &RoomService{db: db, redis: redis, cfg: cfg}
 }
 
-func (s *RoomService) GetActiveRooms(ctx context.Context) ([]*models.Room, error) {
-	return s.db.GetActiveRooms(ctx)
+// GetVisibleRooms lists the circles telegramID may browse and join from the
+// circle menu. Invite-only circles only show up for their owner and moderators.
+func (s *RoomService) GetVisibleRooms(ctx context.Context, telegramID int64) ([]*models.Room, error) {
+	user, err := s.db.GetUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	department := ""
+	if user != nil {
+		department = string(user.Department)
+	}
+	return s.db.GetVisibleRooms(ctx, telegramID, department)
 }
 
-func (s *RoomService) CreateRoom(ctx context.Context, name, description string) (*models.Room, error) {
+// CreateRoom creates a circle owned by ownerID.
+func (s *RoomService) CreateRoom(ctx context.Context, ownerID int64, name, description string) (*models.Room, error) {
 	slug := s.createSlug(name)
 	if slug == "" {
 		return nil, fmt.Errorf("nama circle tidak valid")
@@ -35,7 +76,50 @@ func (s *RoomService) CreateRoom(ctx context.Context, name, description string)
 		return nil, fmt.Errorf("circle dengan nama serupa sudah ada")
 	}
 
-	return s.db.CreateRoom(ctx, slug, name, description)
+	room, err := s.db.CreateRoom(ctx, slug, name, description, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.SetRoomRole(ctx, room.ID, ownerID, models.RoomRoleOwner); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// circleCreationLimit is how many active circles a user of the given level
+// may own: one to start with, plus one more every five levels.
+func circleCreationLimit(level int) int {
+	return min(1+level/5, maxOwnedCircles)
+}
+
+// CanCreateRoom reports why telegramID may not create another circle yet, or
+// nil. Users may create one circle a day, up to their level's limit.
+func (s *RoomService) CanCreateRoom(ctx context.Context, telegramID int64) error {
+	user, err := s.db.GetUser(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("pengguna tidak ditemukan")
+	}
+
+	owned, err := s.db.CountOwnedRooms(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+	if limit := circleCreationLimit(user.Level); owned >= limit {
+		return fmt.Errorf("di Level %d kamu hanya bisa memiliki %d circle aktif, naikkan level untuk membuat lebih banyak", user.Level, limit)
+	}
+
+	recent, err := s.db.CountRoomsCreatedSince(ctx, telegramID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if recent > 0 {
+		return fmt.Errorf("kamu baru saja membuat circle, coba lagi besok")
+	}
+	return nil
 }
 
 func (s *RoomService) createSlug(name string) string {
@@ -54,42 +138,172 @@ func (s *RoomService) JoinRoom(ctx context.Context, telegramID int64, slug strin
 		return nil, fmt.Errorf("circle tidak ditemukan")
 	}
 
-	if err := s.db.RemoveMemberFromAllRooms(ctx, telegramID); err != nil {
-		logger.Warn("Failed to remove user from existing rooms before join",
-			zap.Int64("user_id", telegramID),
-			zap.Error(err),
-		)
+	if room.Visibility == models.RoomInviteOnly {
+		member, err := s.db.IsRoomMember(ctx, room.ID, telegramID)
+		if err != nil {
+			return nil, err
+		}
+		role, err := s.db.GetRoomRole(ctx, room.ID, telegramID)
+		if err != nil {
+			return nil, err
+		}
+		if !member && role == models.RoomRoleMember {
+			return nil, fmt.Errorf("circle ini hanya bisa dimasuki lewat undangan")
+		}
 	}
 
-	err = s.db.AddRoomMember(ctx, room.ID, telegramID)
+	return s.join(ctx, room, telegramID)
+}
+
+// JoinRoomByInvite joins the circle a signed invite code points to. Codes
+// stop working once the owner rotates or revokes them.
+func (s *RoomService) JoinRoomByInvite(ctx context.Context, telegramID int64, code string) (*models.Room, error) {
+	room, err := s.resolveInvite(ctx, code)
 	if err != nil {
 		return nil, err
 	}
+	return s.join(ctx, room, telegramID)
+}
+
+func (s *RoomService) join(ctx context.Context, room *models.Room, telegramID int64) (*models.Room, error) {
+	if !room.IsActive {
+		return nil, fmt.Errorf("circle ini sudah diarsipkan")
+	}
+
+	if room.Visibility == models.RoomDepartmentOnly {
+		user, err := s.db.GetUser(ctx, telegramID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil || string(user.Department) != room.Department {
+			return nil, fmt.Errorf("circle ini khusus mahasiswa jurusan %s", room.Department)
+		}
+	}
 
-	err = s.db.SetUserState(ctx, telegramID, models.StateInCircle, slug)
+	kicked, err := s.db.IsKickedFromRoom(ctx, room.ID, telegramID)
 	if err != nil {
 		return nil, err
 	}
+	if kicked {
+		return nil, fmt.Errorf("kamu telah dikeluarkan dari circle ini")
+	}
+
+	_, member, err := s.db.GetRoomMemberAlias(ctx, room.ID, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		count, err := s.db.CountUserRooms(ctx, telegramID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= maxCirclesPerUser {
+			return nil, fmt.Errorf("kamu sudah bergabung di %d circle, keluar dari salah satu dulu", maxCirclesPerUser)
+		}
+
+		alias, err := s.pickAlias(ctx, room.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.db.AddRoomMember(ctx, room.ID, telegramID, alias); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.speakIn(ctx, telegramID, room); err != nil {
+		return nil, err
+	}
 
 	logger.Debug("User joined circle",
 		zap.Int64("user_id", telegramID),
-		zap.String("slug", slug),
+		zap.String("slug", room.Slug),
 	)
 	return room, nil
 }
 
-func (s *RoomService) LeaveRoom(ctx context.Context, telegramID int64) error {
-	err := s.db.RemoveMemberFromAllRooms(ctx, telegramID)
+func (s *RoomService) speakIn(ctx context.Context, telegramID int64, room *models.Room) error {
+	if err := s.db.SetSpeakingRoom(ctx, telegramID, room.ID); err != nil {
+		return err
+	}
+	return s.db.SetUserState(ctx, telegramID, models.StateInCircle, room.Slug)
+}
+
+// SwitchRoom makes roomID the circle the member's messages are sent to.
+func (s *RoomService) SwitchRoom(ctx context.Context, telegramID, roomID int64) (*models.Room, error) {
+	_, member, err := s.db.GetRoomMemberAlias(ctx, roomID, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("kamu bukan anggota circle ini")
+	}
+
+	room, err := s.db.GetRoomByID(ctx, roomID)
 	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, fmt.Errorf("circle tidak ditemukan")
+	}
+	if !room.IsActive {
+		return nil, fmt.Errorf("circle ini sudah diarsipkan")
+	}
+
+	if err := s.speakIn(ctx, telegramID, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// GetUserRooms lists every circle the user belongs to.
+func (s *RoomService) GetUserRooms(ctx context.Context, telegramID int64) ([]*models.Room, error) {
+	return s.db.GetUserRooms(ctx, telegramID)
+}
+
+// SetRoomMuted stops (or resumes) delivering the circle's messages to the
+// member without leaving it.
+func (s *RoomService) SetRoomMuted(ctx context.Context, telegramID, roomID int64, muted bool) error {
+	return s.db.SetRoomMemberMuted(ctx, roomID, telegramID, muted)
+}
+
+// LeaveRoom removes the user from one circle. If they were speaking in it,
+// their messages stop going to any circle until they switch to another one.
+func (s *RoomService) LeaveRoom(ctx context.Context, telegramID, roomID int64) error {
+	if err := s.db.RemoveRoomMember(ctx, roomID, telegramID); err != nil {
 		return err
 	}
 
-	err = s.db.SetUserState(ctx, telegramID, models.StateNone, "")
+	room, err := s.db.GetRoomByID(ctx, roomID)
 	if err != nil {
 		return err
 	}
+	state, stateData, err := s.db.GetUserState(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+	if room != nil && state == models.StateInCircle && stateData == room.Slug {
+		if err := s.db.SetUserState(ctx, telegramID, models.StateNone, ""); err != nil {
+			return err
+		}
+	}
 
-	logger.Debug("User left circle", zap.Int64("user_id", telegramID))
+	logger.Debug("User left circle", zap.Int64("user_id", telegramID), zap.Int64("room_id", roomID))
+	return nil
+}
+
+// LeaveAllRooms removes the user from every circle they belong to.
+func (s *RoomService) LeaveAllRooms(ctx context.Context, telegramID int64) error {
+	if err := s.db.RemoveMemberFromAllRooms(ctx, telegramID); err != nil {
+		return err
+	}
+
+	state, _, err := s.db.GetUserState(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+	if state == models.StateInCircle {
+		return s.db.SetUserState(ctx, telegramID, models.StateNone, "")
+	}
 	return nil
 }
 
@@ -113,3 +327,389 @@ func (s *RoomService) GetRoomMembers(ctx context.Context, telegramID int64) ([]i
 func (s *RoomService) GetUserRoom(ctx context.Context, telegramID int64) (*models.Room, error) {
 	return s.db.GetUserRoom(ctx, telegramID)
 }
+
+func generateCircleAlias() string {
+	color := aliasColors[getSecureRandomInt(len(aliasColors))]
+	animal := animals[getSecureRandomInt(len(animals))]
+	return fmt.Sprintf("%s%s#%d", color, animal, getSecureRandomInt(99)+1)
+}
+
+// pickAlias draws a fresh random alias that no current member of the room
+// uses. Aliases are never derived from the user, so they cannot be linked
+// across rooms or across rejoins.
+func (s *RoomService) pickAlias(ctx context.Context, roomID int64) (string, error) {
+	for i := 0; i < circleAliasAttempts; i++ {
+		alias := generateCircleAlias()
+		taken, err := s.db.IsRoomAliasTaken(ctx, roomID, alias)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return alias, nil
+		}
+	}
+	return "", fmt.Errorf("gagal membuat alias circle, silakan coba lagi")
+}
+
+// MemberAlias returns the member's alias in the room, assigning one to
+// members who joined before aliases were introduced.
+func (s *RoomService) MemberAlias(ctx context.Context, roomID, telegramID int64) (string, error) {
+	alias, member, err := s.db.GetRoomMemberAlias(ctx, roomID, telegramID)
+	if err != nil || !member || alias != "" {
+		return alias, err
+	}
+
+	alias, err = s.pickAlias(ctx, roomID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.db.SetRoomMemberAlias(ctx, roomID, telegramID, alias); err != nil {
+		return "", err
+	}
+	return alias, nil
+}
+
+func (s *RoomService) GetRole(ctx context.Context, roomID, telegramID int64) (models.RoomRole, error) {
+	return s.db.GetRoomRole(ctx, roomID, telegramID)
+}
+
+func circleDeliveryKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("circle_msg:%d:%d", chatID, messageID)
+}
+
+// RecordDelivery remembers who wrote a circle message delivered to chatID, so
+// moderators can later act on it by replying.
+func (s *RoomService) RecordDelivery(ctx context.Context, chatID int64, messageID int, roomID, senderID, historyID int64) error {
+	value := fmt.Sprintf("%d:%d:%d", roomID, senderID, historyID)
+	return s.redis.GetClient().Set(ctx, circleDeliveryKey(chatID, messageID), value, circleDeliveryTTL).Err()
+}
+
+// ResolveDelivery returns the circle, author and history entry of a delivered
+// message, or zeros when the message is unknown or too old.
+func (s *RoomService) ResolveDelivery(ctx context.Context, chatID int64, messageID int) (roomID, senderID, historyID int64) {
+	raw, err := s.redis.GetClient().Get(ctx, circleDeliveryKey(chatID, messageID)).Result()
+	if err != nil {
+		return 0, 0, 0
+	}
+	parts := strings.Split(raw, ":")
+	if len(parts) < 2 {
+		return 0, 0, 0
+	}
+	roomID, _ = strconv.ParseInt(parts[0], 10, 64)
+	senderID, _ = strconv.ParseInt(parts[1], 10, 64)
+	if len(parts) > 2 {
+		historyID, _ = strconv.ParseInt(parts[2], 10, 64)
+	}
+	return roomID, senderID, historyID
+}
+
+// KickMember removes a member from the circle and prevents them from joining
+// it again.
+func (s *RoomService) KickMember(ctx context.Context, roomID, telegramID, kickedBy int64) error {
+	if err := s.db.AddRoomKick(ctx, roomID, telegramID, kickedBy); err != nil {
+		return err
+	}
+	if err := s.db.RemoveRoomRole(ctx, roomID, telegramID); err != nil {
+		return err
+	}
+
+	return s.LeaveRoom(ctx, telegramID, roomID)
+}
+
+func (s *RoomService) MuteMember(ctx context.Context, roomID, telegramID int64, duration time.Duration, mutedBy int64) (time.Time, error) {
+	until := time.Now().Add(duration)
+	if err := s.db.MuteRoomMember(ctx, roomID, telegramID, until, mutedBy); err != nil {
+		return time.Time{}, err
+	}
+	return until, nil
+}
+
+// MutedUntil returns when the member's mute in the circle ends, or nil.
+func (s *RoomService) MutedUntil(ctx context.Context, roomID, telegramID int64) (*time.Time, error) {
+	return s.db.GetRoomMuteUntil(ctx, roomID, telegramID, time.Now())
+}
+
+func (s *RoomService) PinMessage(ctx context.Context, roomID int64, content string, pinnedBy int64) error {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("hanya pesan teks atau media dengan caption yang bisa di-pin")
+	}
+	return s.db.AddRoomPin(ctx, roomID, content, pinnedBy)
+}
+
+func (s *RoomService) GetPin(ctx context.Context, roomID int64) (*models.RoomPin, error) {
+	return s.db.GetLatestRoomPin(ctx, roomID)
+}
+
+// ToggleModerator promotes a member to moderator or demotes a moderator back
+// to member, returning the new role. Owners cannot be changed.
+func (s *RoomService) ToggleModerator(ctx context.Context, roomID, telegramID int64) (models.RoomRole, error) {
+	role, err := s.db.GetRoomRole(ctx, roomID, telegramID)
+	if err != nil {
+		return role, err
+	}
+
+	switch role {
+	case models.RoomRoleOwner:
+		return role, fmt.Errorf("pemilik circle tidak bisa diubah perannya")
+	case models.RoomRoleModerator:
+		return models.RoomRoleMember, s.db.RemoveRoomRole(ctx, roomID, telegramID)
+	default:
+		return models.RoomRoleModerator, s.db.SetRoomRole(ctx, roomID, telegramID, models.RoomRoleModerator)
+	}
+}
+
+// SetVisibility changes who can find and join the circle. Department-only
+// circles are restricted to the given department.
+func (s *RoomService) SetVisibility(ctx context.Context, roomID int64, visibility models.RoomVisibility, department string) error {
+	switch visibility {
+	case models.RoomPublic, models.RoomInviteOnly:
+		department = ""
+	case models.RoomDepartmentOnly:
+		if department == "" {
+			return fmt.Errorf("lengkapi jurusan kamu di profil terlebih dahulu")
+		}
+	default:
+		return fmt.Errorf("mode visibilitas tidak dikenal")
+	}
+	return s.db.UpdateRoomVisibility(ctx, roomID, visibility, department)
+}
+
+func (s *RoomService) inviteKey() []byte {
+	if s.cfg.CircleInviteSecret != "" {
+		return []byte(s.cfg.CircleInviteSecret)
+	}
+	return []byte(s.cfg.BotToken)
+}
+
+func (s *RoomService) signInvite(roomID int64, nonce string) string {
+	mac := hmac.New(sha256.New, s.inviteKey())
+	fmt.Fprintf(mac, "%d:%s", roomID, nonce)
+	return hex.EncodeToString(mac.Sum(nil))[:inviteSigLength]
+}
+
+// inviteCode only uses [0-9a-z-] so it fits in a t.me start parameter.
+func (s *RoomService) inviteCode(roomID int64, nonce string) string {
+	return strconv.FormatInt(roomID, 36) + "-" + nonce + "-" + s.signInvite(roomID, nonce)
+}
+
+func (s *RoomService) resolveInvite(ctx context.Context, code string) (*models.Room, error) {
+	invalid := fmt.Errorf("kode undangan tidak valid atau sudah dicabut")
+
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(code)), "-")
+	if len(parts) != 3 {
+		return nil, invalid
+	}
+	roomID, err := strconv.ParseInt(parts[0], 36, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signInvite(roomID, parts[1]))) {
+		return nil, invalid
+	}
+
+	room, err := s.db.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil || !room.IsActive || room.InviteNonce == "" || room.InviteNonce != parts[1] {
+		return nil, invalid
+	}
+	return room, nil
+}
+
+// InviteCode returns the circle's current invite code, creating one if the
+// circle has none.
+func (s *RoomService) InviteCode(ctx context.Context, roomID int64) (string, error) {
+	room, err := s.db.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return "", err
+	}
+	if room == nil {
+		return "", fmt.Errorf("circle tidak ditemukan")
+	}
+	if room.InviteNonce != "" {
+		return s.inviteCode(room.ID, room.InviteNonce), nil
+	}
+	return s.RotateInvite(ctx, roomID)
+}
+
+// RotateInvite replaces the circle's invite code, invalidating the old one.
+func (s *RoomService) RotateInvite(ctx context.Context, roomID int64) (string, error) {
+	buf := make([]byte, inviteNonceBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	nonce := hex.EncodeToString(buf)
+
+	if err := s.db.SetRoomInviteNonce(ctx, roomID, nonce); err != nil {
+		return "", err
+	}
+	return s.inviteCode(roomID, nonce), nil
+}
+
+func (s *RoomService) RevokeInvite(ctx context.Context, roomID int64) error {
+	return s.db.SetRoomInviteNonce(ctx, roomID, "")
+}
+
+func (s *RoomService) historyCutoff() time.Time {
+	if s.cfg.CircleHistoryRetentionDays <= 0 {
+		return time.Time{}
+	}
+	return time.Now().AddDate(0, 0, -s.cfg.CircleHistoryRetentionDays)
+}
+
+// LogMessage records activity in the circle and stores the message so it can
+// be reported. The history limit only bounds how many are kept for replay;
+// without one, messages are kept for the retention window.
+func (s *RoomService) LogMessage(ctx context.Context, m *models.RoomMessage) (int64, error) {
+	if err := s.db.TouchRoom(ctx, m.RoomID, time.Now()); err != nil {
+		logger.Warn("Failed to record circle activity", zap.Int64("room_id", m.RoomID), zap.Error(err))
+	}
+
+	id, err := s.db.AddRoomMessage(ctx, m)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.db.PruneRoomMessages(ctx, m.RoomID, s.cfg.CircleHistoryLimit, s.historyCutoff()); err != nil {
+		logger.Warn("Failed to prune circle history", zap.Int64("room_id", m.RoomID), zap.Error(err))
+	}
+	return id, nil
+}
+
+// History returns up to limit messages older than beforeID (the newest when
+// beforeID is 0) within the retention window, oldest first.
+func (s *RoomService) History(ctx context.Context, roomID, beforeID int64, limit int) ([]*models.RoomMessage, error) {
+	if s.cfg.CircleHistoryLimit <= 0 {
+		return nil, nil
+	}
+	messages, err := s.db.GetRoomHistory(ctx, roomID, beforeID, limit, s.historyCutoff())
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(messages)
+	return messages, nil
+}
+
+func (s *RoomService) RemoveMessage(ctx context.Context, roomID, historyID int64) error {
+	return s.db.RemoveRoomMessage(ctx, roomID, historyID)
+}
+
+// ArchiveInactiveRooms archives circles nobody has written in for
+// CircleInactiveDays. Members keep their membership so a restore brings the
+// circle back as it was.
+func (s *RoomService) ArchiveInactiveRooms(ctx context.Context) ([]*models.Room, error) {
+	if s.cfg.CircleInactiveDays <= 0 {
+		return nil, nil
+	}
+	return s.db.ArchiveInactiveRooms(ctx, time.Now().AddDate(0, 0, -s.cfg.CircleInactiveDays))
+}
+
+func (s *RoomService) GetArchivedRooms(ctx context.Context) ([]*models.Room, error) {
+	return s.db.GetArchivedRooms(ctx)
+}
+
+func (s *RoomService) RestoreRoom(ctx context.Context, slug string) (*models.Room, error) {
+	room, err := s.db.GetRoomBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, fmt.Errorf("circle tidak ditemukan")
+	}
+	if room.IsActive {
+		return nil, fmt.Errorf("circle ini tidak sedang diarsipkan")
+	}
+
+	if err := s.db.RestoreRoom(ctx, room.ID); err != nil {
+		return nil, err
+	}
+	room.IsActive = true
+	return room, nil
+}
+
+// DeleteRoom permanently removes a circle. It returns the deleted circle and
+// its former members so they can be told.
+func (s *RoomService) DeleteRoom(ctx context.Context, slug string) (*models.Room, []int64, error) {
+	room, err := s.db.GetRoomBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, err
+	}
+	if room == nil {
+		return nil, nil, fmt.Errorf("circle tidak ditemukan")
+	}
+
+	members, err := s.db.GetRoomMembers(ctx, room.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, memberID := range members {
+		if err := s.LeaveRoom(ctx, memberID, room.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := s.db.DeleteRoom(ctx, room.ID); err != nil {
+		return nil, nil, err
+	}
+	return room, members, nil
+}
+
+// SetSlowMode limits each member of the circle to one message per seconds;
+// zero turns slow mode off.
+func (s *RoomService) SetSlowMode(ctx context.Context, roomID int64, seconds int) error {
+	if seconds < 0 || seconds > maxSlowModeSeconds {
+		return fmt.Errorf("slow mode harus antara 0 dan %d detik", maxSlowModeSeconds)
+	}
+	return s.db.SetRoomSlowMode(ctx, roomID, seconds)
+}
+
+// CheckFlood decides whether a member may send a message with the given
+// fingerprint to the circle, returning a user-facing error when it is
+// dropped. Members who keep bursting past CircleBurstPerMinute are muted
+// automatically (never, if CircleFloodStrikes is zero). Moderators skip slow
+// mode but not flood control.
+func (s *RoomService) CheckFlood(ctx context.Context, room *models.Room, telegramID int64, fingerprint string, exempt bool) error {
+	subject := fmt.Sprintf("%d:%d", room.ID, telegramID)
+
+	if room.SlowMode > 0 && !exempt {
+		allowed, retryAfter, _ := s.redis.AllowOncePer(ctx, "circle_slow_mode", subject, time.Duration(room.SlowMode)*time.Second)
+		if !allowed {
+			return fmt.Errorf("slow mode aktif, tunggu %d detik sebelum mengirim pesan lagi", retryAfter)
+		}
+	}
+
+	if fingerprint != "" {
+		sum := sha256.Sum256([]byte(fingerprint))
+		window := time.Duration(s.cfg.CircleDuplicateWindowSeconds) * time.Second
+		allowed, _, _ := s.redis.AllowOncePer(ctx, "circle_duplicate", subject+":"+hex.EncodeToString(sum[:8]), window)
+		if !allowed {
+			return fmt.Errorf("pesan yang sama baru saja kamu kirim")
+		}
+	}
+
+	allowed, _, _ := s.redis.AllowPerWindow(ctx, "circle_burst", subject, s.cfg.CircleBurstPerMinute, time.Minute)
+	if allowed {
+		return nil
+	}
+
+	// Only the first message over the limit counts as a strike; the rest of
+	// the burst is dropped without piling on more.
+	if first, _, _ := s.redis.AllowOncePer(ctx, "circle_burst_strike", subject, time.Minute); !first {
+		return fmt.Errorf("kamu mengirim pesan terlalu cepat")
+	}
+	if s.cfg.CircleFloodStrikes <= 0 {
+		return fmt.Errorf("kamu mengirim pesan terlalu cepat")
+	}
+	if s.cfg.CircleFloodStrikes > 1 {
+		if ok, _, _ := s.redis.AllowPerWindow(ctx, "circle_flood_strikes", subject, s.cfg.CircleFloodStrikes-1, time.Hour); ok {
+			return fmt.Errorf("kamu mengirim pesan terlalu cepat, pelan-pelan ya, atau kamu akan di-mute otomatis")
+		}
+	}
+
+	until, err := s.MuteMember(ctx, room.ID, telegramID, time.Duration(s.cfg.CircleFloodMuteMinutes)*time.Minute, 0)
+	if err != nil {
+		return err
+	}
+	logger.Info("Auto-muted circle member for flooding", zap.Int64("room_id", room.ID), zap.Int64("user_id", telegramID))
+	return fmt.Errorf("kamu di-mute otomatis sampai %s karena membanjiri circle", until.Format("02 Jan 15:04"))
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
+	"os"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/pnj-anonymous-bot/internal/config"
 	"github.com/pnj-anonymous-bot/internal/models"
@@ -11,10 +14,11 @@ import (
 
 func TestRoomServiceCreateRoom(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
 
-	room, err := roomSvc.CreateRoom(ctx, "Gaming Lounge", "Room untuk gamers PNJ agar bisa ngobrol")
+	room, err := roomSvc.CreateRoom(ctx, 12000, "Gaming Lounge", "Room untuk gamers PNJ agar bisa ngobrol")
 	if err != nil {
 		t.Fatalf("CreateRoom failed: %v", err)
 	}
@@ -28,11 +32,12 @@ func TestRoomServiceCreateRoom(t *testing.T) {
 
 func TestRoomServiceCreateDuplicateRoom(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
 
-	_, _ = roomSvc.CreateRoom(ctx, "Gaming Lounge", "First room created for testing purposes")
-	_, err := roomSvc.CreateRoom(ctx, "Gaming Lounge", "Second with same name should fail")
+	_, _ = roomSvc.CreateRoom(ctx, 12000, "Gaming Lounge", "First room created for testing purposes")
+	_, err := roomSvc.CreateRoom(ctx, 12000, "Gaming Lounge", "Second with same name should fail")
 	if err == nil {
 		t.Fatal("Expected error for duplicate room name")
 	}
@@ -40,10 +45,11 @@ func TestRoomServiceCreateDuplicateRoom(t *testing.T) {
 
 func TestRoomServiceCreateRoomInvalidName(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
 
-	_, err := roomSvc.CreateRoom(ctx, "!!!", "Testing invalid room name with special chars")
+	_, err := roomSvc.CreateRoom(ctx, 12000, "!!!", "Testing invalid room name with special chars")
 	if err == nil {
 		t.Fatal("Expected error for invalid room name")
 	}
@@ -51,12 +57,13 @@ func TestRoomServiceCreateRoomInvalidName(t *testing.T) {
 
 func TestRoomServiceJoinAndLeave(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
 	userID := int64(12001)
 	createUserForTest(t, db, userID, "Laki-laki", "Teknik Informatika & Komputer", 2022)
 
-	room, _ := roomSvc.CreateRoom(ctx, "Coding Club", "Room untuk coding enthusiasts di PNJ")
+	room, _ := roomSvc.CreateRoom(ctx, 12000, "Coding Club", "Room untuk coding enthusiasts di PNJ")
 
 	joinedRoom, err := roomSvc.JoinRoom(ctx, userID, room.Slug)
 	if err != nil {
@@ -74,7 +81,7 @@ func TestRoomServiceJoinAndLeave(t *testing.T) {
 		t.Errorf("Expected state data '%s', got '%s'", room.Slug, stateData)
 	}
 
-	err = roomSvc.LeaveRoom(ctx, userID)
+	err = roomSvc.LeaveRoom(ctx, userID, room.ID)
 	if err != nil {
 		t.Fatalf("LeaveRoom failed: %v", err)
 	}
@@ -87,7 +94,7 @@ func TestRoomServiceJoinAndLeave(t *testing.T) {
 
 func TestRoomServiceJoinNonExistentRoom(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
 	userID := int64(12002)
 	createUserForTest(t, db, userID, "", "", 0)
@@ -100,15 +107,16 @@ func TestRoomServiceJoinNonExistentRoom(t *testing.T) {
 
 func TestRoomServiceGetRoomMembers(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
 
 	user1 := int64(12003)
 	user2 := int64(12004)
 	createUserForTest(t, db, user1, "Laki-laki", "Teknik Sipil", 2022)
 	createUserForTest(t, db, user2, "Perempuan", "Teknik Sipil", 2022)
 
-	room, _ := roomSvc.CreateRoom(ctx, "Study Group", "Room belajar bareng sebelum UAS")
+	room, _ := roomSvc.CreateRoom(ctx, 12000, "Study Group", "Room belajar bareng sebelum UAS")
 	_, _ = roomSvc.JoinRoom(ctx, user1, room.Slug)
 	_, _ = roomSvc.JoinRoom(ctx, user2, room.Slug)
 
@@ -126,7 +134,7 @@ func TestRoomServiceGetRoomMembers(t *testing.T) {
 
 func TestRoomServiceGetMembersNotInRoom(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
 	userID := int64(12005)
 	createUserForTest(t, db, userID, "", "", 0)
@@ -137,17 +145,18 @@ func TestRoomServiceGetMembersNotInRoom(t *testing.T) {
 	}
 }
 
-func TestRoomServiceGetActiveRooms(t *testing.T) {
+func TestRoomServiceGetVisibleRooms(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
 
-	_, _ = roomSvc.CreateRoom(ctx, "Room Alpha", "Room pertama untuk testing list rooms")
-	_, _ = roomSvc.CreateRoom(ctx, "Room Beta", "Room kedua untuk testing list rooms")
+	_, _ = roomSvc.CreateRoom(ctx, 12000, "Room Alpha", "Room pertama untuk testing list rooms")
+	_, _ = roomSvc.CreateRoom(ctx, 12000, "Room Beta", "Room kedua untuk testing list rooms")
 
-	rooms, err := roomSvc.GetActiveRooms(ctx)
+	rooms, err := roomSvc.GetVisibleRooms(ctx, 12000)
 	if err != nil {
-		t.Fatalf("GetActiveRooms failed: %v", err)
+		t.Fatalf("GetVisibleRooms failed: %v", err)
 	}
 	if len(rooms) != 2 {
 		t.Errorf("Expected 2 rooms, got %d", len(rooms))
@@ -156,12 +165,13 @@ func TestRoomServiceGetActiveRooms(t *testing.T) {
 
 func TestRoomServiceGetUserRoom(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
 	userID := int64(12006)
 	createUserForTest(t, db, userID, "Perempuan", "Akuntansi", 2023)
 
-	room, _ := roomSvc.CreateRoom(ctx, "Chill Zone", "Room santai untuk curhat ringan")
+	room, _ := roomSvc.CreateRoom(ctx, 12000, "Chill Zone", "Room santai untuk curhat ringan")
 	_, _ = roomSvc.JoinRoom(ctx, userID, room.Slug)
 
 	userRoom, err := roomSvc.GetUserRoom(ctx, userID)
@@ -175,13 +185,14 @@ func TestRoomServiceGetUserRoom(t *testing.T) {
 
 func TestRoomServiceJoinSwitchesRoom(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
 	userID := int64(12007)
 	createUserForTest(t, db, userID, "Laki-laki", "Teknik Elektro", 2022)
 
-	room1, _ := roomSvc.CreateRoom(ctx, "Room One", "Room pertama untuk test switch room")
-	room2, _ := roomSvc.CreateRoom(ctx, "Room Two", "Room kedua untuk test switch room")
+	room1, _ := roomSvc.CreateRoom(ctx, 12000, "Room One", "Room pertama untuk test switch room")
+	room2, _ := roomSvc.CreateRoom(ctx, 12000, "Room Two", "Room kedua untuk test switch room")
 
 	_, _ = roomSvc.JoinRoom(ctx, userID, room1.Slug)
 	_, _ = roomSvc.JoinRoom(ctx, userID, room2.Slug)
@@ -192,9 +203,205 @@ func TestRoomServiceJoinSwitchesRoom(t *testing.T) {
 	}
 }
 
+func TestRoomServiceModeration(t *testing.T) {
+	db := setupTestDB(t)
+	setupTestRedis(t)
+	roomSvc := NewRoomService(db, NewRedisService(os.Getenv("REDIS_URL")), &config.Config{})
+	ctx := context.Background()
+
+	owner, member := int64(12010), int64(12011)
+	createUserForTest(t, db, owner, "Laki-laki", "Teknik Sipil", 2022)
+	createUserForTest(t, db, member, "Perempuan", "Teknik Sipil", 2022)
+
+	room, err := roomSvc.CreateRoom(ctx, owner, "Mod Club", "Room untuk menguji moderasi circle")
+	if err != nil {
+		t.Fatalf("CreateRoom failed: %v", err)
+	}
+	if role, _ := roomSvc.GetRole(ctx, room.ID, owner); role != models.RoomRoleOwner {
+		t.Errorf("creator should be owner, got %q", role)
+	}
+
+	_, _ = roomSvc.JoinRoom(ctx, member, room.Slug)
+
+	if err := roomSvc.RecordDelivery(ctx, owner, 55, room.ID, member, 9); err != nil {
+		t.Fatalf("RecordDelivery failed: %v", err)
+	}
+	if roomID, senderID, historyID := roomSvc.ResolveDelivery(ctx, owner, 55); roomID != room.ID || senderID != member || historyID != 9 {
+		t.Errorf("ResolveDelivery = %d, %d, %d; want %d, %d, 9", roomID, senderID, historyID, room.ID, member)
+	}
+	if _, senderID, _ := roomSvc.ResolveDelivery(ctx, member, 55); senderID != 0 {
+		t.Error("deliveries should be scoped to the recipient chat")
+	}
+
+	role, err := roomSvc.ToggleModerator(ctx, room.ID, member)
+	if err != nil || role != models.RoomRoleModerator {
+		t.Fatalf("expected promotion to moderator, got %q (%v)", role, err)
+	}
+	if role, _ := roomSvc.ToggleModerator(ctx, room.ID, member); role != models.RoomRoleMember {
+		t.Errorf("expected demotion to member, got %q", role)
+	}
+	if _, err := roomSvc.ToggleModerator(ctx, room.ID, owner); err == nil {
+		t.Error("owner role should not be toggled")
+	}
+
+	if _, err := roomSvc.MuteMember(ctx, room.ID, member, time.Hour, owner); err != nil {
+		t.Fatalf("MuteMember failed: %v", err)
+	}
+	if until, _ := roomSvc.MutedUntil(ctx, room.ID, member); until == nil {
+		t.Error("member should be muted")
+	}
+
+	if err := roomSvc.PinMessage(ctx, room.ID, "  ", owner); err == nil {
+		t.Error("empty pin should be rejected")
+	}
+	_ = roomSvc.PinMessage(ctx, room.ID, "Selamat datang!", owner)
+	if pin, _ := roomSvc.GetPin(ctx, room.ID); pin == nil || pin.Content != "Selamat datang!" {
+		t.Error("expected pinned message")
+	}
+
+	if err := roomSvc.KickMember(ctx, room.ID, member, owner); err != nil {
+		t.Fatalf("KickMember failed: %v", err)
+	}
+	if userRoom, _ := roomSvc.GetUserRoom(ctx, member); userRoom != nil {
+		t.Error("kicked member should no longer be in the circle")
+	}
+	if state, _, _ := db.GetUserState(ctx, member); state != models.StateNone {
+		t.Errorf("kicked member state should be none, got %s", state)
+	}
+	if _, err := roomSvc.JoinRoom(ctx, member, room.Slug); err == nil {
+		t.Error("kicked member should not be able to rejoin")
+	}
+}
+
+func TestRoomServiceMemberAliases(t *testing.T) {
+	db := setupTestDB(t)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
+	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
+
+	user1, user2 := int64(12020), int64(12021)
+	createUserForTest(t, db, user1, "", "", 0)
+	createUserForTest(t, db, user2, "", "", 0)
+
+	room, _ := roomSvc.CreateRoom(ctx, 12000, "Alias Club", "Room untuk menguji alias anggota")
+	_, _ = roomSvc.JoinRoom(ctx, user1, room.Slug)
+	_, _ = roomSvc.JoinRoom(ctx, user2, room.Slug)
+
+	alias1, err := roomSvc.MemberAlias(ctx, room.ID, user1)
+	if err != nil {
+		t.Fatalf("MemberAlias failed: %v", err)
+	}
+	if !regexp.MustCompile(`^[A-Z][a-z]+[A-Z][a-z]+#\d{1,2}$`).MatchString(alias1) {
+		t.Errorf("unexpected alias format %q", alias1)
+	}
+	if again, _ := roomSvc.MemberAlias(ctx, room.ID, user1); again != alias1 {
+		t.Errorf("alias should be stable while in the circle, got %q then %q", alias1, again)
+	}
+	if alias2, _ := roomSvc.MemberAlias(ctx, room.ID, user2); alias2 == alias1 {
+		t.Error("members of the same circle should not share an alias")
+	}
+
+	rotated := false
+	for i := 0; i < 5 && !rotated; i++ {
+		_ = roomSvc.LeaveRoom(ctx, user1, room.ID)
+		_, _ = roomSvc.JoinRoom(ctx, user1, room.Slug)
+		alias, _ := roomSvc.MemberAlias(ctx, room.ID, user1)
+		rotated = alias != alias1
+	}
+	if !rotated {
+		t.Error("alias should rotate after leaving and rejoining")
+	}
+
+	_ = roomSvc.LeaveRoom(ctx, user1, room.ID)
+	if alias, _ := roomSvc.MemberAlias(ctx, room.ID, user1); alias != "" {
+		t.Errorf("non-members should have no alias, got %q", alias)
+	}
+}
+
+func TestRoomServiceVisibilityAndInvites(t *testing.T) {
+	db := setupTestDB(t)
+	roomSvc := NewRoomService(db, nil, &config.Config{CircleInviteSecret: "test-secret"})
+	ctx := context.Background()
+
+	owner, sipil, mesin := int64(12030), int64(12031), int64(12032)
+	createUserForTest(t, db, owner, "", "Teknik Sipil", 2022)
+	createUserForTest(t, db, sipil, "", "Teknik Sipil", 2022)
+	createUserForTest(t, db, mesin, "", "Teknik Mesin", 2022)
+
+	room, _ := roomSvc.CreateRoom(ctx, owner, "Sipil Only", "Circle khusus anak teknik sipil")
+	if err := roomSvc.SetVisibility(ctx, room.ID, models.RoomDepartmentOnly, "Teknik Sipil"); err != nil {
+		t.Fatalf("SetVisibility failed: %v", err)
+	}
+
+	if rooms, _ := roomSvc.GetVisibleRooms(ctx, mesin); len(rooms) != 0 {
+		t.Errorf("department circle should be hidden from other departments, got %d rooms", len(rooms))
+	}
+	if rooms, _ := roomSvc.GetVisibleRooms(ctx, sipil); len(rooms) != 1 {
+		t.Errorf("department circle should be visible to its department, got %d rooms", len(rooms))
+	}
+	if _, err := roomSvc.JoinRoom(ctx, mesin, room.Slug); err == nil {
+		t.Error("other departments should not be able to join")
+	}
+	if _, err := roomSvc.JoinRoom(ctx, sipil, room.Slug); err != nil {
+		t.Errorf("same department should be able to join: %v", err)
+	}
+
+	_ = roomSvc.SetVisibility(ctx, room.ID, models.RoomInviteOnly, "")
+	if rooms, _ := roomSvc.GetVisibleRooms(ctx, sipil); len(rooms) != 0 {
+		t.Error("invite-only circle should be hidden from members without a role")
+	}
+	if rooms, _ := roomSvc.GetVisibleRooms(ctx, owner); len(rooms) != 1 {
+		t.Error("invite-only circle should stay visible to its owner")
+	}
+	if _, err := roomSvc.JoinRoom(ctx, mesin, room.Slug); err == nil {
+		t.Error("invite-only circle should reject joins without an invite")
+	}
+	if _, err := roomSvc.JoinRoom(ctx, sipil, room.Slug); err != nil {
+		t.Errorf("existing members should re-open an invite-only circle: %v", err)
+	}
+	if _, err := roomSvc.JoinRoom(ctx, owner, room.Slug); err != nil {
+		t.Errorf("owner should join their invite-only circle: %v", err)
+	}
+
+	code, err := roomSvc.InviteCode(ctx, room.ID)
+	if err != nil {
+		t.Fatalf("InviteCode failed: %v", err)
+	}
+	if again, _ := roomSvc.InviteCode(ctx, room.ID); again != code {
+		t.Error("InviteCode should be stable until rotated")
+	}
+	if len("circle_"+code) > 64 || !regexp.MustCompile(`^[0-9a-z-]+$`).MatchString(code) {
+		t.Errorf("invite code %q is not a valid start parameter", code)
+	}
+
+	tampered := code[:len(code)-1] + "0"
+	if tampered == code {
+		tampered = code[:len(code)-1] + "1"
+	}
+	if _, err := roomSvc.JoinRoomByInvite(ctx, mesin, tampered); err == nil {
+		t.Error("tampered invite should be rejected")
+	}
+	if _, err := roomSvc.JoinRoomByInvite(ctx, mesin, code); err != nil {
+		t.Errorf("valid invite should be accepted: %v", err)
+	}
+
+	rotated, _ := roomSvc.RotateInvite(ctx, room.ID)
+	if _, err := roomSvc.JoinRoomByInvite(ctx, sipil, code); err == nil {
+		t.Error("old invite should stop working after rotation")
+	}
+	if _, err := roomSvc.JoinRoomByInvite(ctx, sipil, rotated); err != nil {
+		t.Errorf("rotated invite should work: %v", err)
+	}
+
+	_ = roomSvc.RevokeInvite(ctx, room.ID)
+	if _, err := roomSvc.JoinRoomByInvite(ctx, sipil, rotated); err == nil {
+		t.Error("revoked invite should be rejected")
+	}
+}
+
 func TestRoomSlugGeneration(t *testing.T) {
 	db := setupTestDB(t)
-	roomSvc := NewRoomService(db)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
 
 	tests := []struct {
 		name         string
@@ -306,3 +513,233 @@ func TestAuthServiceEmailSenderError(t *testing.T) {
 }
 
 var errMockSend = fmt.Errorf("email send failed")
+
+func TestRoomServiceHistory(t *testing.T) {
+	db := setupTestDB(t)
+	roomSvc := NewRoomService(db, nil, &config.Config{CircleHistoryLimit: 3, CircleHistoryRetentionDays: 7})
+	ctx := context.Background()
+
+	owner := int64(12040)
+	createUserForTest(t, db, owner, "", "Teknik Sipil", 2022)
+	room, _ := roomSvc.CreateRoom(ctx, owner, "History Club", "Circle untuk menguji riwayat pesan")
+
+	var ids []int64
+	for _, text := range []string{"satu", "dua", "tiga", "empat", "lima"} {
+		id, err := roomSvc.LogMessage(ctx, &models.RoomMessage{RoomID: room.ID, SenderID: owner, Alias: "BlueOtter#1", Content: text})
+		if err != nil {
+			t.Fatalf("LogMessage failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	messages, _ := roomSvc.History(ctx, room.ID, 0, 10)
+	if len(messages) != 3 {
+		t.Fatalf("history should be pruned to 3 messages, got %d", len(messages))
+	}
+	if messages[0].Content != "tiga" || messages[2].Content != "lima" {
+		t.Errorf("history should be oldest first, got %q..%q", messages[0].Content, messages[2].Content)
+	}
+
+	page, _ := roomSvc.History(ctx, room.ID, ids[4], 1)
+	if len(page) != 1 || page[0].Content != "empat" {
+		t.Errorf("expected paging before the newest message to return 'empat', got %v", page)
+	}
+
+	_ = roomSvc.RemoveMessage(ctx, room.ID, ids[3])
+	if messages, _ := roomSvc.History(ctx, room.ID, 0, 10); len(messages) != 2 {
+		t.Errorf("removed messages should be hidden from history, got %d", len(messages))
+	}
+
+	disabled := NewRoomService(db, nil, &config.Config{})
+	id, err := disabled.LogMessage(ctx, &models.RoomMessage{RoomID: room.ID, SenderID: owner, Content: "x"})
+	if err != nil || id == 0 {
+		t.Fatalf("messages should still be stored for reports without history, got %d (%v)", id, err)
+	}
+	if messages, _ := disabled.History(ctx, room.ID, 0, 10); len(messages) != 0 {
+		t.Errorf("history disabled should not replay messages, got %d", len(messages))
+	}
+	if messages, _ := roomSvc.History(ctx, room.ID, 0, 10); len(messages) != 3 {
+		t.Errorf("disabled history should not prune other messages, got %d", len(messages))
+	}
+}
+
+func TestRoomServiceMultipleCircles(t *testing.T) {
+	db := setupTestDB(t)
+	roomSvc := NewRoomService(db, nil, &config.Config{})
+	ctx := context.Background()
+	createUserForTest(t, db, 12000, "", "", 0)
+
+	userID := int64(12050)
+	createUserForTest(t, db, userID, "", "", 0)
+
+	first, _ := roomSvc.CreateRoom(ctx, 12000, "First Circle", "Circle pertama untuk menguji multi circle")
+	second, _ := roomSvc.CreateRoom(ctx, 12000, "Second Circle", "Circle kedua untuk menguji multi circle")
+	_, _ = roomSvc.JoinRoom(ctx, userID, first.Slug)
+	_, _ = roomSvc.JoinRoom(ctx, userID, second.Slug)
+
+	rooms, err := roomSvc.GetUserRooms(ctx, userID)
+	if err != nil || len(rooms) != 2 {
+		t.Fatalf("expected membership in 2 circles, got %d (%v)", len(rooms), err)
+	}
+	if rooms[0].Speaking || !rooms[1].Speaking {
+		t.Error("the most recently joined circle should be the speaking circle")
+	}
+
+	if _, err := roomSvc.SwitchRoom(ctx, userID, first.ID); err != nil {
+		t.Fatalf("SwitchRoom failed: %v", err)
+	}
+	if room, _ := roomSvc.GetUserRoom(ctx, userID); room == nil || room.ID != first.ID {
+		t.Error("GetUserRoom should return the circle switched to")
+	}
+	if _, stateData, _ := db.GetUserState(ctx, userID); stateData != first.Slug {
+		t.Errorf("state data should follow the speaking circle, got %q", stateData)
+	}
+
+	_ = roomSvc.SetRoomMuted(ctx, userID, second.ID, true)
+	if recipients, _ := db.GetRoomRecipients(ctx, second.ID); len(recipients) != 0 {
+		t.Errorf("muted members should not receive circle messages, got %v", recipients)
+	}
+
+	_ = roomSvc.LeaveRoom(ctx, userID, second.ID)
+	if state, _, _ := db.GetUserState(ctx, userID); state != models.StateInCircle {
+		t.Errorf("leaving another circle should keep the user speaking, got %s", state)
+	}
+	_ = roomSvc.LeaveRoom(ctx, userID, first.ID)
+	if state, _, _ := db.GetUserState(ctx, userID); state != models.StateNone {
+		t.Errorf("leaving the speaking circle should reset state, got %s", state)
+	}
+
+	if _, err := roomSvc.SwitchRoom(ctx, userID, first.ID); err == nil {
+		t.Error("switching to a circle you left should fail")
+	}
+}
+
+func TestRoomServiceLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	roomSvc := NewRoomService(db, nil, &config.Config{CircleInactiveDays: 30})
+	ctx := context.Background()
+
+	owner, member := int64(12060), int64(12061)
+	createUserForTest(t, db, owner, "", "", 0)
+	createUserForTest(t, db, member, "", "", 0)
+
+	if err := roomSvc.CanCreateRoom(ctx, owner); err != nil {
+		t.Fatalf("new users should be able to create a circle: %v", err)
+	}
+	room, _ := roomSvc.CreateRoom(ctx, owner, "Sleepy Circle", "Circle yang akan diarsipkan")
+	if err := roomSvc.CanCreateRoom(ctx, owner); err == nil {
+		t.Error("a level 1 user should be limited to one active circle")
+	}
+	if circleCreationLimit(10) != 3 || circleCreationLimit(100) != maxOwnedCircles {
+		t.Error("unexpected circle creation limits")
+	}
+
+	_, _ = roomSvc.JoinRoom(ctx, member, room.Slug)
+	if archived, _ := roomSvc.ArchiveInactiveRooms(ctx); len(archived) != 0 {
+		t.Fatalf("fresh circles should not be archived, got %d", len(archived))
+	}
+
+	_, _ = db.Exec("UPDATE rooms SET created_at = ? WHERE id = ?", time.Now().AddDate(0, 0, -31), room.ID)
+	archived, err := roomSvc.ArchiveInactiveRooms(ctx)
+	if err != nil || len(archived) != 1 || archived[0].ID != room.ID {
+		t.Fatalf("expected the idle circle to be archived, got %v (%v)", archived, err)
+	}
+	if _, err := roomSvc.SwitchRoom(ctx, member, room.ID); err == nil {
+		t.Error("switching to an archived circle should fail")
+	}
+	if rooms, _ := roomSvc.GetArchivedRooms(ctx); len(rooms) != 1 {
+		t.Errorf("expected 1 archived circle, got %d", len(rooms))
+	}
+
+	if _, err := roomSvc.RestoreRoom(ctx, room.Slug); err != nil {
+		t.Fatalf("RestoreRoom failed: %v", err)
+	}
+	if archived, _ := roomSvc.ArchiveInactiveRooms(ctx); len(archived) != 0 {
+		t.Error("a restored circle should not be archived again right away")
+	}
+	if _, err := roomSvc.SwitchRoom(ctx, member, room.ID); err != nil {
+		t.Errorf("members should keep their membership across archive and restore: %v", err)
+	}
+
+	deleted, members, err := roomSvc.DeleteRoom(ctx, room.Slug)
+	if err != nil || deleted.ID != room.ID || len(members) != 1 {
+		t.Fatalf("DeleteRoom = %v, %v, %v", deleted, members, err)
+	}
+	if state, _, _ := db.GetUserState(ctx, member); state != models.StateNone {
+		t.Errorf("members of a deleted circle should leave circle state, got %s", state)
+	}
+	if r, _ := db.GetRoomBySlug(ctx, room.Slug); r != nil {
+		t.Error("deleted circle should be gone")
+	}
+
+	empty, _ := db.CreateRoom(ctx, "empty-circle", "Empty Circle", "Circle tanpa anggota", owner)
+	_, _ = db.Exec("UPDATE rooms SET created_at = ?, last_message_at = ? WHERE id = ?", time.Now().AddDate(0, 0, -31), time.Now(), empty.ID)
+	archived, _ = roomSvc.ArchiveInactiveRooms(ctx)
+	if len(archived) != 1 || archived[0].ID != empty.ID {
+		t.Errorf("expected the memberless circle to be archived, got %v", archived)
+	}
+}
+
+func TestRoomServiceFloodControl(t *testing.T) {
+	db := setupTestDB(t)
+	mr := setupTestRedis(t)
+	redisSvc := NewRedisService(os.Getenv("REDIS_URL"))
+	roomSvc := NewRoomService(db, redisSvc, &config.Config{
+		CircleBurstPerMinute:         3,
+		CircleFloodStrikes:           2,
+		CircleFloodMuteMinutes:       30,
+		CircleDuplicateWindowSeconds: 60,
+	})
+	ctx := context.Background()
+
+	owner, member := int64(12070), int64(12071)
+	createUserForTest(t, db, owner, "", "", 0)
+	createUserForTest(t, db, member, "", "", 0)
+	room, _ := roomSvc.CreateRoom(ctx, owner, "Flood Club", "Circle untuk menguji flood control")
+	_, _ = roomSvc.JoinRoom(ctx, member, room.Slug)
+
+	if err := roomSvc.CheckFlood(ctx, room, member, "halo", false); err != nil {
+		t.Fatalf("first message should pass: %v", err)
+	}
+	if err := roomSvc.CheckFlood(ctx, room, member, "halo", false); err == nil {
+		t.Error("duplicate message should be suppressed")
+	}
+
+	if err := roomSvc.SetSlowMode(ctx, room.ID, 7200); err == nil {
+		t.Error("slow mode above the maximum should be rejected")
+	}
+	_ = roomSvc.SetSlowMode(ctx, room.ID, 30)
+	room, _ = db.GetRoomByID(ctx, room.ID)
+	if err := roomSvc.CheckFlood(ctx, room, member, "pesan 1", false); err != nil {
+		t.Fatalf("first message under slow mode should pass: %v", err)
+	}
+	if err := roomSvc.CheckFlood(ctx, room, member, "pesan 2", false); err == nil {
+		t.Error("second message within the slow mode interval should be rejected")
+	}
+	if err := roomSvc.CheckFlood(ctx, room, owner, "pesan owner", true); err != nil {
+		t.Errorf("moderators should skip slow mode: %v", err)
+	}
+
+	_ = roomSvc.SetSlowMode(ctx, room.ID, 0)
+	room, _ = db.GetRoomByID(ctx, room.ID)
+	for i := 0; i < 2; i++ {
+		_ = roomSvc.CheckFlood(ctx, room, owner, fmt.Sprintf("spam %d", i), true)
+	}
+	if err := roomSvc.CheckFlood(ctx, room, owner, "spam over", true); err == nil {
+		t.Fatal("burst over the per-minute limit should be rejected")
+	}
+	if until, _ := roomSvc.MutedUntil(ctx, room.ID, owner); until != nil {
+		t.Fatal("the first burst should only warn")
+	}
+
+	mr.FastForward(61 * time.Second)
+	for i := 0; i < 3; i++ {
+		_ = roomSvc.CheckFlood(ctx, room, owner, fmt.Sprintf("spam lagi %d", i), true)
+	}
+	if err := roomSvc.CheckFlood(ctx, room, owner, "spam over again", true); err == nil {
+		t.Fatal("second burst should be rejected")
+	}
+	if until, _ := roomSvc.MutedUntil(ctx, room.ID, owner); until == nil {
+		t.Error("repeat offenders should be muted automatically")
+	}
+}
@@ -2,7 +2,9 @@ package validation
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
@@ -22,8 +24,79 @@ var (
 	PollQuestionLimits = TextLimits{MinLen: 5, MaxLen: 300, Label: "Pertanyaan Polling"}
 	PollOptionLimits   = TextLimits{MinLen: 1, MaxLen: 100, Label: "Opsi Polling"}
 	BroadcastLimits    = TextLimits{MinLen: 1, MaxLen: 4000, Label: "Broadcast"}
+	SearchQueryLimits  = TextLimits{MinLen: 2, MaxLen: 100, Label: "Kata kunci"}
 )
 
+const (
+	MinPollDuration = 5 * time.Minute
+	MaxPollDuration = 30 * 24 * time.Hour
+	MaxPollOptions  = 10
+)
+
+// ParsePollDuration parses durations like "30m", "24h" or "7d".
+func ParsePollDuration(token string) (time.Duration, bool) {
+	token = strings.ToLower(strings.TrimSpace(token))
+	if len(token) < 2 {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(token[:len(token)-1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+
+	var d time.Duration
+	switch token[len(token)-1] {
+	case 'm':
+		d = time.Duration(n) * time.Minute
+	case 'h':
+		d = time.Duration(n) * time.Hour
+	case 'd':
+		d = time.Duration(n) * 24 * time.Hour
+	default:
+		return 0, false
+	}
+
+	return d, true
+}
+
+func ValidatePollDuration(d time.Duration) string {
+	if d < MinPollDuration {
+		return fmt.Sprintf("⚠️ Durasi polling terlalu singkat. Minimal %d menit.", int(MinPollDuration.Minutes()))
+	}
+	if d > MaxPollDuration {
+		return fmt.Sprintf("⚠️ Durasi polling terlalu lama. Maksimal %d hari.", int(MaxPollDuration.Hours()/24))
+	}
+	return ""
+}
+
+// ValidatePoll checks the question and options of a new poll, including that
+// no option is repeated.
+func ValidatePoll(question string, options []string) string {
+	if errMsg := ValidateText(question, PollQuestionLimits); errMsg != "" {
+		return errMsg
+	}
+	if len(options) < 2 {
+		return "⚠️ Minimal harus ada 2 opsi jawaban."
+	}
+	if len(options) > MaxPollOptions {
+		return fmt.Sprintf("⚠️ Maksimal %d opsi jawaban.", MaxPollOptions)
+	}
+
+	seen := make(map[string]bool, len(options))
+	for _, opt := range options {
+		if errMsg := ValidateText(opt, PollOptionLimits); errMsg != "" {
+			return errMsg
+		}
+		key := strings.ToLower(strings.TrimSpace(opt))
+		if seen[key] {
+			return fmt.Sprintf("⚠️ Opsi \"%s\" ditulis lebih dari sekali.", strings.TrimSpace(opt))
+		}
+		seen[key] = true
+	}
+	return ""
+}
+
 func ValidateText(text string, limits TextLimits) string {
 	length := utf8.RuneCountInString(strings.TrimSpace(text))
 
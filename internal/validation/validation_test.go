@@ -3,6 +3,7 @@ package validation
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidateTextTooShort(t *testing.T) {
@@ -90,3 +91,56 @@ func TestPredefinedLimits(t *testing.T) {
 		}
 	}
 }
+
+func TestParsePollDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30m": 30 * time.Minute,
+		"24h": 24 * time.Hour,
+		"7D":  7 * 24 * time.Hour,
+	}
+	for token, want := range cases {
+		got, ok := ParsePollDuration(token)
+		if !ok || got != want {
+			t.Errorf("ParsePollDuration(%q) = %v, %v; want %v", token, got, ok, want)
+		}
+	}
+
+	for _, token := range []string{"", "h", "0h", "-5m", "10x", "Apakah"} {
+		if _, ok := ParsePollDuration(token); ok {
+			t.Errorf("ParsePollDuration(%q) should not parse", token)
+		}
+	}
+
+	if ValidatePollDuration(time.Minute) == "" {
+		t.Error("Expected error for too short duration")
+	}
+	if ValidatePollDuration(31*24*time.Hour) == "" {
+		t.Error("Expected error for too long duration")
+	}
+	if msg := ValidatePollDuration(24 * time.Hour); msg != "" {
+		t.Errorf("Expected valid duration, got %s", msg)
+	}
+}
+
+func TestValidatePoll(t *testing.T) {
+	if msg := ValidatePoll("Setuju gak harga parkir naik?", []string{"Setuju", "Tidak Setuju"}); msg != "" {
+		t.Errorf("Expected valid poll, got %s", msg)
+	}
+
+	tooMany := make([]string, MaxPollOptions+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("x", i+1)
+	}
+	invalid := map[string][]string{
+		"Hm?":                  {"A", "B"},
+		"Pertanyaan valid?":    {"A"},
+		"Pertanyaan panjang?":  {"A", strings.Repeat("b", 101)},
+		"Opsi kembar?":         {"Ya", " ya "},
+		"Terlalu banyak opsi?": tooMany,
+	}
+	for question, options := range invalid {
+		if ValidatePoll(question, options) == "" {
+			t.Errorf("Expected error for %q %v", question, options)
+		}
+	}
+}